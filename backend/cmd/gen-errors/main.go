@@ -0,0 +1,29 @@
+// Command gen-errors writes the API error catalogue as TypeScript for the
+// frontend. Run it through go generate in internal/apierr.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+)
+
+func main() {
+	out := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	var buf bytes.Buffer
+	if err := apierr.WriteTypeScript(&buf); err != nil {
+		log.Fatal(err)
+	}
+	if *out == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
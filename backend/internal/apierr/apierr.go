@@ -0,0 +1,177 @@
+// Package apierr defines the error codes the API returns and the catalogue
+// of statuses and messages behind them. The catalogue is the single source
+// for the frontend's error types, generated by WriteTypeScript.
+package apierr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Code is a stable, machine-readable error identifier. Codes are part of
+// the API contract: add new ones freely, but never rename or reuse one.
+type Code string
+
+const (
+	Internal           Code = "internal"
+	InvalidJSON        Code = "invalid_json"
+	InvalidID          Code = "invalid_id"
+	InvalidQuery       Code = "invalid_query"
+	ValidationFailed   Code = "validation_failed"
+	RouteNotFound      Code = "route_not_found"
+	MethodNotAllowed   Code = "method_not_allowed"
+	MissingToken       Code = "missing_token"
+	InvalidToken       Code = "invalid_token"
+	TokenRevoked       Code = "token_revoked"
+	InvalidCredentials Code = "invalid_credentials"
+	InvalidRefresh     Code = "invalid_refresh_token"
+	RefreshExpired     Code = "refresh_token_expired"
+	WrongPassword      Code = "wrong_password"
+	Forbidden          Code = "forbidden"
+	MenuItemNotFound   Code = "menu_item_not_found"
+	NewsNotFound       Code = "news_not_found"
+	RevisionNotFound   Code = "revision_not_found"
+	CategoryNotFound   Code = "category_not_found"
+	CategoryExists     Code = "category_exists"
+	CategoryInUse      Code = "category_in_use"
+	UserNotFound       Code = "user_not_found"
+	UsernameTaken      Code = "username_taken"
+	CannotDeleteSelf   Code = "cannot_delete_self"
+	LastOwner          Code = "last_owner"
+	InvalidMultipart   Code = "invalid_multipart"
+	MissingFile        Code = "missing_file"
+	FileTooLarge       Code = "file_too_large"
+	UnsupportedType    Code = "unsupported_media_type"
+	InvalidImage       Code = "invalid_image"
+	UnsupportedBody    Code = "unsupported_content_type"
+	PreconditionNeeded Code = "precondition_required"
+	EditConflict       Code = "edit_conflict"
+)
+
+// DefaultLanguage is used when the client asks for a language the
+// catalogue has no message in. The cafe's audience reads Russian.
+const DefaultLanguage = "ru"
+
+// Languages lists every language the catalogue has messages for.
+var Languages = []string{"ru", "en"}
+
+// Entry describes one code: the HTTP status it is sent with and its
+// message in each language.
+type Entry struct {
+	Code     Code
+	Status   int
+	Messages map[string]string
+}
+
+// Catalogue lists every code the API can return, in a fixed order so the
+// generated TypeScript is stable.
+var Catalogue = []Entry{
+	{Internal, 500, map[string]string{"ru": "Внутренняя ошибка сервера", "en": "Internal server error"}},
+	{InvalidJSON, 400, map[string]string{"ru": "Некорректный JSON в теле запроса", "en": "Invalid JSON"}},
+	{InvalidID, 400, map[string]string{"ru": "Некорректный идентификатор", "en": "Invalid ID"}},
+	{InvalidQuery, 400, map[string]string{"ru": "Некорректные параметры запроса", "en": "Invalid query parameters"}},
+	{ValidationFailed, 422, map[string]string{"ru": "Проверьте правильность заполнения полей", "en": "Validation failed"}},
+	{RouteNotFound, 404, map[string]string{"ru": "Адрес не найден", "en": "Not found"}},
+	{MethodNotAllowed, 405, map[string]string{"ru": "Метод не поддерживается", "en": "Method not allowed"}},
+	{MissingToken, 401, map[string]string{"ru": "Требуется вход в систему", "en": "Missing token"}},
+	{InvalidToken, 401, map[string]string{"ru": "Сессия недействительна, войдите снова", "en": "Invalid token"}},
+	{TokenRevoked, 401, map[string]string{"ru": "Сессия завершена, войдите снова", "en": "Token revoked"}},
+	{InvalidCredentials, 401, map[string]string{"ru": "Неверное имя пользователя или пароль", "en": "Invalid username or password"}},
+	{InvalidRefresh, 401, map[string]string{"ru": "Сессия недействительна, войдите снова", "en": "Invalid refresh token"}},
+	{RefreshExpired, 401, map[string]string{"ru": "Сессия истекла, войдите снова", "en": "Refresh token expired"}},
+	{WrongPassword, 401, map[string]string{"ru": "Неверный текущий пароль", "en": "Invalid password"}},
+	{Forbidden, 403, map[string]string{"ru": "Недостаточно прав", "en": "Forbidden"}},
+	{MenuItemNotFound, 404, map[string]string{"ru": "Позиция меню не найдена", "en": "Menu item not found"}},
+	{NewsNotFound, 404, map[string]string{"ru": "Новость не найдена", "en": "News item not found"}},
+	{RevisionNotFound, 404, map[string]string{"ru": "Версия не найдена", "en": "Revision not found"}},
+	{CategoryNotFound, 404, map[string]string{"ru": "Категория не найдена", "en": "Category not found"}},
+	{CategoryExists, 409, map[string]string{"ru": "Категория с таким кодом уже существует", "en": "Category already exists"}},
+	{CategoryInUse, 409, map[string]string{"ru": "В категории есть позиции меню", "en": "Category still has menu items"}},
+	{UserNotFound, 404, map[string]string{"ru": "Пользователь не найден", "en": "User not found"}},
+	{UsernameTaken, 409, map[string]string{"ru": "Имя пользователя уже занято", "en": "Username already taken"}},
+	{CannotDeleteSelf, 400, map[string]string{"ru": "Нельзя удалить собственную учётную запись", "en": "You cannot delete your own account"}},
+	{LastOwner, 409, map[string]string{"ru": "Должен остаться хотя бы один владелец", "en": "At least one owner must remain"}},
+	{InvalidMultipart, 400, map[string]string{"ru": "Ожидается корректное тело multipart/form-data", "en": "Expected a well-formed multipart/form-data body"}},
+	{MissingFile, 400, map[string]string{"ru": "Файл не передан", "en": "Missing file field"}},
+	{FileTooLarge, 413, map[string]string{"ru": "Файл слишком большой", "en": "File too large"}},
+	{UnsupportedType, 415, map[string]string{"ru": "Неподдерживаемый тип файла", "en": "Unsupported file type"}},
+	{InvalidImage, 422, map[string]string{"ru": "Не удалось прочитать изображение", "en": "Invalid image"}},
+	{UnsupportedBody, 415, map[string]string{"ru": "Неподдерживаемый формат тела запроса", "en": "Unsupported request content type"}},
+	{PreconditionNeeded, 428, map[string]string{"ru": "Укажите версию, которую вы изменяете (If-Match или updatedAt)", "en": "Send If-Match or updatedAt with the version you are editing"}},
+	{EditConflict, 412, map[string]string{"ru": "Запись уже изменил кто-то другой. Обновите страницу и повторите", "en": "Someone else has changed this record; reload and try again"}},
+}
+
+var byCode = func() map[Code]Entry {
+	m := make(map[Code]Entry, len(Catalogue))
+	for _, e := range Catalogue {
+		m[e.Code] = e
+	}
+	return m
+}()
+
+// Lookup returns the catalogue entry for code, falling back to Internal
+// for codes that are not catalogued.
+func Lookup(code Code) Entry {
+	if e, ok := byCode[code]; ok {
+		return e
+	}
+	return byCode[Internal]
+}
+
+// Message returns the message for code in lang, or in DefaultLanguage.
+func (e Entry) Message(lang string) string {
+	if msg, ok := e.Messages[lang]; ok {
+		return msg
+	}
+	return e.Messages[DefaultLanguage]
+}
+
+// Error is an error carrying an API code. Details, when set, are sent to
+// the client as they are; Err is the underlying cause and is only logged.
+type Error struct {
+	Code    Code
+	Details any
+	Err     error
+}
+
+// New returns an Error for code.
+func New(code Code) *Error {
+	return &Error{Code: code}
+}
+
+// Wrap returns an Error for code caused by err.
+func Wrap(code Code, err error) *Error {
+	return &Error{Code: code, Err: err}
+}
+
+// WithDetails attaches client-visible details such as the failing fields.
+func (e *Error) WithDetails(details any) *Error {
+	e.Details = details
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Code, e.Err)
+	}
+	return string(e.Code)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Language picks the catalogue language for an Accept-Language header,
+// taking the first listed language the catalogue knows.
+func Language(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		for _, lang := range Languages {
+			if base == lang {
+				return lang
+			}
+		}
+	}
+	return DefaultLanguage
+}
@@ -0,0 +1,63 @@
+package apierr
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+//go:generate go run ../../cmd/gen-errors -o ../../../frontend/src/errorCodes.ts
+
+// WriteTypeScript renders the catalogue as a TypeScript module: an
+// ErrorCode union plus the status and messages for each code.
+func WriteTypeScript(w io.Writer) error {
+	p := &tsWriter{w: w}
+	p.line("// Code generated by backend/cmd/gen-errors from the apierr catalogue. DO NOT EDIT.")
+	p.line("")
+	p.line("export type ErrorCode =")
+	for i, e := range Catalogue {
+		end := ""
+		if i == len(Catalogue)-1 {
+			end = ";"
+		}
+		p.line("  | " + strconv.Quote(string(e.Code)) + end)
+	}
+	p.line("")
+	p.line("export type ErrorLanguage = " + quotedUnion(Languages) + ";")
+	p.line("")
+	p.line("export const errorCatalogue: Record<ErrorCode, { status: number; messages: Record<ErrorLanguage, string> }> = {")
+	for _, e := range Catalogue {
+		p.line(fmt.Sprintf("  %s: {", strconv.Quote(string(e.Code))))
+		p.line(fmt.Sprintf("    status: %d,", e.Status))
+		p.line("    messages: {")
+		for _, lang := range Languages {
+			p.line(fmt.Sprintf("      %s: %s,", lang, strconv.Quote(e.Messages[lang])))
+		}
+		p.line("    },")
+		p.line("  },")
+	}
+	p.line("};")
+	return p.err
+}
+
+func quotedUnion(values []string) string {
+	out := ""
+	for i, v := range values {
+		if i > 0 {
+			out += " | "
+		}
+		out += strconv.Quote(v)
+	}
+	return out
+}
+
+type tsWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *tsWriter) line(s string) {
+	if p.err == nil {
+		_, p.err = io.WriteString(p.w, s+"\n")
+	}
+}
@@ -0,0 +1,61 @@
+// Package audit records who changed what. Repository decorators write an
+// entry after every successful create, update, delete and restore made
+// through them, naming the Actor found in the request's context.
+package audit
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/andrey-918/cafe-between/models"
+)
+
+// Actor is who a change is attributed to. The zero Actor stands for the
+// server itself, such as a background job.
+type Actor struct {
+	UserID   int
+	Username string
+	IP       string
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx that attributes changes to a.
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+// ActorFromContext returns the actor WithActor stored in ctx.
+func ActorFromContext(ctx context.Context) Actor {
+	a, _ := ctx.Value(actorKey{}).(Actor)
+	return a
+}
+
+// Entry builds an entry for an action by the actor in ctx.
+func Entry(ctx context.Context, action models.AuditAction, entity models.AuditEntity, id int) models.AuditEntry {
+	a := ActorFromContext(ctx)
+	e := models.AuditEntry{Username: a.Username, Action: action, Entity: entity, IP: a.IP}
+	if a.UserID != 0 {
+		e.UserID = &a.UserID
+	}
+	if id != 0 {
+		e.EntityID = &id
+	}
+	return e
+}
+
+// record writes an entry with the difference between before and after.
+// The change it describes has already been made, so a failure is logged
+// rather than returned, and the entry is written even if the request that
+// made the change has been cancelled since.
+func record(ctx context.Context, log models.AuditRepository, action models.AuditAction, entity models.AuditEntity, id int, before, after any) {
+	e := Entry(ctx, action, entity, id)
+	changes, err := models.AuditDiff(before, after)
+	if err == nil {
+		e.Changes = changes
+		err = log.Record(context.WithoutCancel(ctx), e)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to write audit log entry", "action", action, "entity", entity, "entity_id", id, "err", err)
+	}
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/andrey-918/cafe-between/models"
+)
+
+// MenuRepository records the changes made through it to another
+// MenuRepository. It reads the item before and after each write, so it
+// belongs below any cache.
+type MenuRepository struct {
+	models.MenuRepository
+	log models.AuditRepository
+}
+
+var _ models.MenuRepository = (*MenuRepository)(nil)
+
+func NewMenuRepository(inner models.MenuRepository, log models.AuditRepository) *MenuRepository {
+	return &MenuRepository{MenuRepository: inner, log: log}
+}
+
+func (r *MenuRepository) Create(ctx context.Context, item models.MenuItem) (int, error) {
+	id, err := r.MenuRepository.Create(ctx, item)
+	if err == nil {
+		record(ctx, r.log, models.AuditCreate, models.AuditMenuItem, id, nil, r.current(ctx, id))
+	}
+	return id, err
+}
+
+func (r *MenuRepository) Update(ctx context.Context, id int, item models.MenuItem) error {
+	return r.update(ctx, id, func() error { return r.MenuRepository.Update(ctx, id, item) })
+}
+
+func (r *MenuRepository) UpdateIfUnmodified(ctx context.Context, id int, item models.MenuItem, version time.Time) error {
+	return r.update(ctx, id, func() error { return r.MenuRepository.UpdateIfUnmodified(ctx, id, item, version) })
+}
+
+func (r *MenuRepository) SetAvailability(ctx context.Context, id int, change models.AvailabilityChange) error {
+	return r.update(ctx, id, func() error { return r.MenuRepository.SetAvailability(ctx, id, change) })
+}
+
+func (r *MenuRepository) Delete(ctx context.Context, id int) error {
+	before := r.current(ctx, id)
+	err := r.MenuRepository.Delete(ctx, id)
+	if err == nil {
+		record(ctx, r.log, models.AuditDelete, models.AuditMenuItem, id, before, nil)
+	}
+	return err
+}
+
+func (r *MenuRepository) Restore(ctx context.Context, id int) error {
+	err := r.MenuRepository.Restore(ctx, id)
+	if err == nil {
+		record(ctx, r.log, models.AuditRestore, models.AuditMenuItem, id, nil, r.current(ctx, id))
+	}
+	return err
+}
+
+func (r *MenuRepository) update(ctx context.Context, id int, write func() error) error {
+	before := r.current(ctx, id)
+	err := write()
+	if err == nil {
+		record(ctx, r.log, models.AuditUpdate, models.AuditMenuItem, id, before, r.current(ctx, id))
+	}
+	return err
+}
+
+// current returns the stored item, or nil if it cannot be read.
+func (r *MenuRepository) current(ctx context.Context, id int) *models.MenuItem {
+	item, err := r.MenuRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil
+	}
+	return &item
+}
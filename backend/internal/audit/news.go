@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/andrey-918/cafe-between/models"
+)
+
+// NewsRepository records the changes made through it to another
+// NewsRepository, like MenuRepository.
+type NewsRepository struct {
+	models.NewsRepository
+	log models.AuditRepository
+}
+
+var _ models.NewsRepository = (*NewsRepository)(nil)
+
+func NewNewsRepository(inner models.NewsRepository, log models.AuditRepository) *NewsRepository {
+	return &NewsRepository{NewsRepository: inner, log: log}
+}
+
+func (r *NewsRepository) Create(ctx context.Context, item models.News) (int, error) {
+	id, err := r.NewsRepository.Create(ctx, item)
+	if err == nil {
+		record(ctx, r.log, models.AuditCreate, models.AuditNews, id, nil, r.current(ctx, id))
+	}
+	return id, err
+}
+
+func (r *NewsRepository) Update(ctx context.Context, id int, item models.News) error {
+	return r.update(ctx, id, func() error { return r.NewsRepository.Update(ctx, id, item) })
+}
+
+func (r *NewsRepository) UpdateIfUnmodified(ctx context.Context, id int, item models.News, version time.Time) error {
+	return r.update(ctx, id, func() error { return r.NewsRepository.UpdateIfUnmodified(ctx, id, item, version) })
+}
+
+func (r *NewsRepository) Delete(ctx context.Context, id int) error {
+	before := r.current(ctx, id)
+	err := r.NewsRepository.Delete(ctx, id)
+	if err == nil {
+		record(ctx, r.log, models.AuditDelete, models.AuditNews, id, before, nil)
+	}
+	return err
+}
+
+func (r *NewsRepository) Restore(ctx context.Context, id int) error {
+	err := r.NewsRepository.Restore(ctx, id)
+	if err == nil {
+		record(ctx, r.log, models.AuditRestore, models.AuditNews, id, nil, r.current(ctx, id))
+	}
+	return err
+}
+
+func (r *NewsRepository) update(ctx context.Context, id int, write func() error) error {
+	before := r.current(ctx, id)
+	err := write()
+	if err == nil {
+		record(ctx, r.log, models.AuditUpdate, models.AuditNews, id, before, r.current(ctx, id))
+	}
+	return err
+}
+
+// current returns the stored post, or nil if it cannot be read.
+func (r *NewsRepository) current(ctx context.Context, id int) *models.News {
+	item, err := r.NewsRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil
+	}
+	return &item
+}
@@ -0,0 +1,434 @@
+// Package config loads the server configuration once, at startup, and
+// checks it before anything else runs.
+//
+// Every setting has one name, used as the environment variable and as the
+// key in the configuration file, and a matching command-line flag: JWT_SECRET
+// is also -jwt-secret. Sources are layered, later ones winning:
+//
+//  1. the defaults of the selected profile;
+//  2. the configuration file, .env by default;
+//  3. the profile's file next to it, e.g. .env.production;
+//  4. the environment;
+//  5. command-line flags.
+//
+// The profile comes from -profile or CAFE_ENV and defaults to development.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // the cafe timezone must load on hosts without zoneinfo
+
+	"github.com/andrey-918/cafe-between/internal/logging"
+	"github.com/andrey-918/cafe-between/internal/storage"
+	"github.com/joho/godotenv"
+)
+
+// Profiles.
+const (
+	Development = "development"
+	Production  = "production"
+)
+
+var Profiles = []string{Development, Production}
+
+// minProductionSecret is the shortest JWT secret production accepts: 256
+// bits, the size of the HS256 key.
+const minProductionSecret = 32
+
+type Config struct {
+	Profile string
+	// Files lists the configuration files that were read, in order.
+	Files []string
+	// Args holds what is left of the command line after the flags: the
+	// subcommand, if any, and its arguments.
+	Args []string
+
+	Server    Server
+	Database  Database
+	Auth      Auth
+	Cache     Cache
+	Cafe      Cafe
+	Trash     Trash
+	Metrics   Metrics
+	Storage   Storage
+	Bootstrap Bootstrap
+	Log       Log
+}
+
+type Server struct {
+	Port string
+	// CORSOrigins are the browser origins allowed to call the API.
+	CORSOrigins []string
+
+	// ReadTimeout covers the whole request, body included, so it must
+	// leave room for an image upload on a slow connection.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// ShutdownTimeout is how long in-flight requests may take to finish
+	// after SIGTERM before they are cut off.
+	ShutdownTimeout time.Duration
+	// DrainDelay keeps serving after /readyz starts failing, giving the
+	// load balancer time to notice before the listener closes.
+	DrainDelay time.Duration
+}
+
+type Database struct {
+	DSN string
+	// AutoMigrate applies pending migrations when the server starts.
+	AutoMigrate bool
+	// SlowQuery is the duration from which a query is logged as slow;
+	// zero turns the warning off.
+	SlowQuery time.Duration
+}
+
+type Log struct {
+	Level slog.Level
+}
+
+type Auth struct {
+	JWTSecret       []byte
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+type Cache struct {
+	// Control is the Cache-Control header of public menu and news responses.
+	Control string
+	// ReadTTL bounds how long cached reads may lag another instance's
+	// writes; zero turns the read cache off.
+	ReadTTL time.Duration
+}
+
+type Cafe struct {
+	Timezone *time.Location
+}
+
+type Trash struct {
+	// Retention is how long deleted menu items and news posts can be
+	// restored before they, and their orphaned images, are purged.
+	Retention time.Duration
+}
+
+type Metrics struct {
+	// Token is the bearer token a scraper must send to read /metrics. The
+	// counters describe the server's internals, so the endpoint is off
+	// while no token is set.
+	Token string
+}
+
+type Storage struct {
+	// Backend is "local" or "s3".
+	Backend   string
+	UploadDir string
+	// PublicURL is where the local backend's files are served from.
+	PublicURL string
+	S3        storage.S3Config
+}
+
+// Bootstrap creates the first owner account on an empty database.
+type Bootstrap struct {
+	AdminUsername string
+	AdminPassword string
+}
+
+// defaults returns the configuration a profile starts from.
+func defaults(profile string) Config {
+	c := Config{
+		Profile: profile,
+		Server: Server{
+			Port:            "8080",
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		Database: Database{AutoMigrate: true, SlowQuery: 200 * time.Millisecond},
+		Auth: Auth{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+		Cache:     Cache{Control: "public, max-age=60", ReadTTL: 5 * time.Minute},
+		Trash:     Trash{Retention: 30 * 24 * time.Hour},
+		Storage:   Storage{Backend: "local", UploadDir: "uploads"},
+		Bootstrap: Bootstrap{AdminUsername: "admin"},
+	}
+	c.Cafe.Timezone, _ = time.LoadLocation("Europe/Moscow")
+	if profile == Development {
+		c.Server.CORSOrigins = []string{"http://localhost:5173"}
+	} else {
+		c.Server.DrainDelay = 5 * time.Second
+	}
+	return c
+}
+
+// setting is one configuration key and how it is applied.
+type setting struct {
+	key   string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+// flagName is the command-line spelling of key: PORT is -port.
+func (s setting) flagName() string {
+	return strings.ToLower(strings.ReplaceAll(s.key, "_", "-"))
+}
+
+var settings = []setting{
+	{"PORT", "HTTP listen port", func(c *Config, v string) error { c.Server.Port = v; return nil }},
+	{"CORS_ORIGINS", "comma-separated browser origins allowed to call the API", func(c *Config, v string) error {
+		c.Server.CORSOrigins = splitList(v)
+		return nil
+	}},
+	{"HTTP_READ_TIMEOUT", "longest time to read a request, body included", func(c *Config, v string) error { return parseDuration(v, &c.Server.ReadTimeout) }},
+	{"HTTP_WRITE_TIMEOUT", "longest time to write a response", func(c *Config, v string) error { return parseDuration(v, &c.Server.WriteTimeout) }},
+	{"HTTP_IDLE_TIMEOUT", "how long idle keep-alive connections stay open", func(c *Config, v string) error { return parseDuration(v, &c.Server.IdleTimeout) }},
+	{"SHUTDOWN_TIMEOUT", "how long in-flight requests may finish after SIGTERM", func(c *Config, v string) error { return parseDuration(v, &c.Server.ShutdownTimeout) }},
+	{"DRAIN_DELAY", "how long to keep serving after /readyz starts failing", func(c *Config, v string) error { return parseDuration(v, &c.Server.DrainDelay) }},
+	{"POSTGRES_DSN", "PostgreSQL connection string (required)", func(c *Config, v string) error { c.Database.DSN = v; return nil }},
+	{"AUTO_MIGRATE", "apply pending migrations at startup", func(c *Config, v string) error { return parseBool(v, &c.Database.AutoMigrate) }},
+	{"SLOW_QUERY_THRESHOLD", "log queries taking at least this long; 0 disables", func(c *Config, v string) error { return parseDuration(v, &c.Database.SlowQuery) }},
+	{"LOG_LEVEL", "debug, info, warn or error", func(c *Config, v string) error {
+		level, err := logging.ParseLevel(v)
+		c.Log.Level = level
+		return err
+	}},
+	{"JWT_SECRET", "key that signs access tokens (required)", func(c *Config, v string) error { c.Auth.JWTSecret = []byte(v); return nil }},
+	{"ACCESS_TOKEN_TTL", "lifetime of access tokens", func(c *Config, v string) error { return parseDuration(v, &c.Auth.AccessTokenTTL) }},
+	{"REFRESH_TOKEN_TTL", "lifetime of refresh tokens", func(c *Config, v string) error { return parseDuration(v, &c.Auth.RefreshTokenTTL) }},
+	{"CACHE_CONTROL", "Cache-Control of public menu and news responses", func(c *Config, v string) error { c.Cache.Control = v; return nil }},
+	{"READ_CACHE_TTL", "how long menu and news reads are cached; 0 disables the cache", func(c *Config, v string) error { return parseDuration(v, &c.Cache.ReadTTL) }},
+	{"CAFE_TIMEZONE", "IANA timezone of the cafe", func(c *Config, v string) error {
+		loc, err := time.LoadLocation(v)
+		if err != nil {
+			return err
+		}
+		c.Cafe.Timezone = loc
+		return nil
+	}},
+	{"TRASH_RETENTION", "how long deleted menu items and news stay restorable", func(c *Config, v string) error { return parseDuration(v, &c.Trash.Retention) }},
+	{"METRICS_TOKEN", "bearer token required to scrape /metrics; unset turns the endpoint off", func(c *Config, v string) error { c.Metrics.Token = v; return nil }},
+	{"STORAGE_BACKEND", `upload storage, "local" or "s3"`, func(c *Config, v string) error { c.Storage.Backend = v; return nil }},
+	{"UPLOAD_DIR", "directory of the local upload storage", func(c *Config, v string) error { c.Storage.UploadDir = v; return nil }},
+	{"PUBLIC_URL", "public base URL of this server, used for local upload links", func(c *Config, v string) error { c.Storage.PublicURL = v; return nil }},
+	{"S3_ENDPOINT", "S3 endpoint", func(c *Config, v string) error { c.Storage.S3.Endpoint = v; return nil }},
+	{"S3_REGION", "S3 region", func(c *Config, v string) error { c.Storage.S3.Region = v; return nil }},
+	{"S3_BUCKET", "S3 bucket", func(c *Config, v string) error { c.Storage.S3.Bucket = v; return nil }},
+	{"S3_ACCESS_KEY_ID", "S3 access key ID", func(c *Config, v string) error { c.Storage.S3.AccessKeyID = v; return nil }},
+	{"S3_SECRET_ACCESS_KEY", "S3 secret access key", func(c *Config, v string) error { c.Storage.S3.SecretAccessKey = v; return nil }},
+	{"S3_PUBLIC_URL", "public base URL of the S3 bucket", func(c *Config, v string) error { c.Storage.S3.PublicURL = v; return nil }},
+	{"ADMIN_USERNAME", "username of the owner created on an empty database", func(c *Config, v string) error { c.Bootstrap.AdminUsername = v; return nil }},
+	{"ADMIN_PASSWORD", "password of the owner created on an empty database", func(c *Config, v string) error { c.Bootstrap.AdminPassword = v; return nil }},
+}
+
+// defaultFiles are tried in order when no file is named; the second one is
+// the repository root when the server runs from backend/.
+var defaultFiles = []string{".env", "../.env"}
+
+// Load builds the configuration from args (without the program name), the
+// environment as seen through getenv, and the configuration files. Every
+// problem found is reported, not just the first. flag.ErrHelp is returned
+// after -help has printed the usage.
+func Load(args []string, getenv func(string) string) (*Config, error) {
+	fs := flag.NewFlagSet("cafe-between", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	profile := fs.String("profile", "", "configuration profile, one of "+strings.Join(Profiles, ", ")+" (CAFE_ENV)")
+	file := fs.String("config", "", "configuration file (CONFIG_FILE); .env by default")
+	flagValues := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		flagValues[s.key] = fs.String(s.flagName(), "", s.usage+" ("+s.key+")")
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(os.Stderr)
+			fs.Usage()
+		}
+		return nil, err
+	}
+
+	if *profile == "" {
+		*profile = getenv("CAFE_ENV")
+	}
+	if *profile == "" {
+		*profile = Development
+	}
+	if !slices.Contains(Profiles, *profile) {
+		return nil, fmt.Errorf("unknown profile %q, expected one of %v", *profile, Profiles)
+	}
+	c := defaults(*profile)
+	c.Args = fs.Args()
+
+	values := map[string]string{}
+	if *file == "" {
+		*file = getenv("CONFIG_FILE")
+	}
+	files, err := readFiles(*file, *profile)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		c.Files = append(c.Files, f.path)
+		for _, s := range settings {
+			if v, ok := f.values[s.key]; ok {
+				values[s.key] = v
+			}
+		}
+	}
+	for _, s := range settings {
+		if v := getenv(s.key); v != "" {
+			values[s.key] = v
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flagName() == f.Name {
+				values[s.key] = *flagValues[s.key]
+			}
+		}
+	})
+
+	var errs []error
+	for _, s := range settings {
+		v, ok := values[s.key]
+		if !ok {
+			continue
+		}
+		if err := s.set(&c, strings.TrimSpace(v)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.key, err))
+		}
+	}
+	if c.Storage.PublicURL == "" && c.Profile == Development {
+		c.Storage.PublicURL = "http://localhost:" + c.Server.Port
+	}
+	errs = append(errs, c.validate()...)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &c, nil
+}
+
+// validate reports the settings that are missing or unusable.
+func (c *Config) validate() []error {
+	var errs []error
+	if c.Database.DSN == "" {
+		errs = append(errs, errors.New("POSTGRES_DSN: required"))
+	}
+	switch {
+	case strings.TrimSpace(string(c.Auth.JWTSecret)) == "":
+		errs = append(errs, errors.New("JWT_SECRET: required"))
+	case c.Profile == Production && len(c.Auth.JWTSecret) < minProductionSecret:
+		errs = append(errs, fmt.Errorf("JWT_SECRET: must be at least %d bytes in production", minProductionSecret))
+	}
+	if c.Auth.AccessTokenTTL <= 0 {
+		errs = append(errs, errors.New("ACCESS_TOKEN_TTL: must be positive"))
+	}
+	if c.Auth.RefreshTokenTTL <= 0 {
+		errs = append(errs, errors.New("REFRESH_TOKEN_TTL: must be positive"))
+	}
+	for key, d := range map[string]time.Duration{
+		"HTTP_READ_TIMEOUT":  c.Server.ReadTimeout,
+		"HTTP_WRITE_TIMEOUT": c.Server.WriteTimeout,
+		"HTTP_IDLE_TIMEOUT":  c.Server.IdleTimeout,
+		"SHUTDOWN_TIMEOUT":   c.Server.ShutdownTimeout,
+		"TRASH_RETENTION":    c.Trash.Retention,
+	} {
+		if d <= 0 {
+			errs = append(errs, fmt.Errorf("%s: must be positive", key))
+		}
+	}
+	if c.Server.DrainDelay < 0 {
+		errs = append(errs, errors.New("DRAIN_DELAY: must not be negative"))
+	}
+	if c.Cache.ReadTTL < 0 {
+		errs = append(errs, errors.New("READ_CACHE_TTL: must not be negative"))
+	}
+	if _, err := strconv.Atoi(c.Server.Port); err != nil {
+		errs = append(errs, fmt.Errorf("PORT: %q is not a port number", c.Server.Port))
+	}
+	switch c.Storage.Backend {
+	case "local":
+		if c.Storage.PublicURL == "" {
+			errs = append(errs, errors.New("PUBLIC_URL: required in production with local storage"))
+		}
+	case "s3":
+	default:
+		errs = append(errs, fmt.Errorf("STORAGE_BACKEND: unknown backend %q", c.Storage.Backend))
+	}
+	return errs
+}
+
+type configFile struct {
+	path   string
+	values map[string]string
+}
+
+// readFiles reads the base configuration file and the profile's file next
+// to it. A file named explicitly must exist; the defaults are optional.
+func readFiles(name, profile string) ([]configFile, error) {
+	var base string
+	if name != "" {
+		if _, err := os.Stat(name); err != nil {
+			return nil, fmt.Errorf("config file: %w", err)
+		}
+		base = name
+	} else {
+		for _, candidate := range defaultFiles {
+			if _, err := os.Stat(candidate); err == nil {
+				base = candidate
+				break
+			}
+		}
+		if base == "" {
+			base = defaultFiles[0]
+		}
+	}
+	var files []configFile
+	for _, path := range []string{base, base + "." + profile} {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		values, err := godotenv.Read(path)
+		if err != nil {
+			return nil, fmt.Errorf("config file %s: %w", path, err)
+		}
+		files = append(files, configFile{path: path, values: values})
+	}
+	return files, nil
+}
+
+func splitList(v string) []string {
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func parseBool(v string, dst *bool) error {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%q is not a boolean", v)
+	}
+	*dst = b
+	return nil
+}
+
+func parseDuration(v string, dst *time.Duration) error {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return err
+	}
+	*dst = d
+	return nil
+}
@@ -2,29 +2,54 @@ package database
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"os"
+	"time"
 
+	"github.com/andrey-918/cafe-between/internal/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/joho/godotenv"
 )
 
 var Pool *pgxpool.Pool
 
-func Init() {
-	_ = godotenv.Load("../.env")
-	dsn := os.Getenv("POSTGRES_DSN")
-	if dsn == "" {
-		log.Fatal("POSTGRES_DSN not set in .env")
+// Init connects the shared pool to cfg.DSN, which config.Load has already
+// checked is set.
+func Init(cfg config.Database) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.DSN)
+	if err != nil {
+		slog.Error("invalid POSTGRES_DSN", "err", err)
+		os.Exit(1)
 	}
-
-	pool, err := pgxpool.New(context.Background(), dsn)
+	configureUTC(poolConfig)
+	poolConfig.ConnConfig.Tracer = queryTracer{slow: cfg.SlowQuery}
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
-		log.Fatalf("Unable to connect to database: %v", err)
+		slog.Error("unable to connect to database", "err", err)
+		os.Exit(1)
 	}
 	if err := pool.Ping(context.Background()); err != nil {
-		log.Fatalf("Unable to ping database: %v", err)
+		slog.Error("unable to ping database", "err", err)
+		os.Exit(1)
 	}
 	Pool = pool
-	log.Println("Connected to PostgreSQL")
-}
\ No newline at end of file
+	slog.Info("connected to PostgreSQL")
+}
+
+// configureUTC pins every session to UTC, whatever the server or role
+// default is, and scans timestamptz values as UTC times so they encode as
+// RFC 3339 with a Z suffix. The cafe's own timezone is a presentation
+// concern and never reaches the database.
+func configureUTC(config *pgxpool.Config) {
+	config.ConnConfig.RuntimeParams["timezone"] = "UTC"
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		conn.TypeMap().RegisterType(&pgtype.Type{
+			Name:  "timestamptz",
+			OID:   pgtype.TimestamptzOID,
+			Codec: &pgtype.TimestamptzCodec{ScanLocation: time.UTC},
+		})
+		return nil
+	}
+}
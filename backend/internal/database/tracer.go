@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// queryTracer logs slow queries, and failed ones at debug level, with the
+// context of the call, so each line carries the ID of the request that ran
+// the query.
+type queryTracer struct {
+	slow time.Duration
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	sql string
+	at  time.Time
+}
+
+func (t queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{sql: data.SQL, at: time.Now()})
+}
+
+func (t queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start.at)
+	attrs := []slog.Attr{
+		slog.String("sql", compactSQL(start.sql)),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	switch {
+	// Failures are mostly expected ones, such as unique violations the
+	// repositories turn into conflicts; the caller decides what is an error.
+	case data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows):
+		slog.LogAttrs(ctx, slog.LevelDebug, "query failed", append(attrs, slog.String("err", data.Err.Error()))...)
+	case t.slow > 0 && elapsed >= t.slow:
+		slog.LogAttrs(ctx, slog.LevelWarn, "slow query", attrs...)
+	}
+}
+
+// compactSQL folds the repositories' multi-line queries onto one line.
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// quietRoutes are polled by infrastructure; their requests are logged at
+// debug level so they do not drown the rest.
+var quietRoutes = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+// AccessLog wraps the router and writes one record per request once it has
+// been served: method, route template, path, status, duration, bytes
+// written and, for signed-in staff, the user, which JWTMiddleware adds to
+// the request's log fields. It must run inside RequestID so the record
+// carries the request ID.
+//
+// It wraps the router, rather than being installed with Use, so requests
+// that match no route are logged too.
+func AccessLog(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := ""
+		var match mux.RouteMatch
+		if router.Match(r, &match) && match.Route != nil {
+			route, _ = match.Route.GetPathTemplate()
+		}
+		rec := &accessRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		router.ServeHTTP(rec, r)
+
+		level := slog.LevelInfo
+		switch {
+		case rec.status >= 500:
+			level = slog.LevelError
+		case quietRoutes[route] && rec.status < 400:
+			level = slog.LevelDebug
+		}
+		slog.LogAttrs(r.Context(), level, "request",
+			slog.String("method", r.Method),
+			slog.String("route", route),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int64("bytes", rec.bytes),
+		)
+	})
+}
+
+// accessRecorder counts what a handler writes.
+type accessRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *accessRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *accessRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *accessRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/validate"
+	"github.com/andrey-918/cafe-between/models"
+)
+
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 500
+)
+
+// GetAuditLogHandler lists audit log entries, newest first. To page
+// through, pass the ID of the last entry received as before.
+func (h *Handler) GetAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := parseAuditQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	entries, err := h.Audit.List(r.Context(), q)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", staffCacheControl)
+	json.NewEncoder(w).Encode(entries)
+}
+
+// parseAuditQuery reads the audit log filters, reporting every malformed
+// one like parseMenuQuery.
+func parseAuditQuery(values url.Values) (models.AuditQuery, error) {
+	q := models.AuditQuery{
+		Entity: models.AuditEntity(values.Get("entity")),
+		Action: models.AuditAction(values.Get("action")),
+		Limit:  defaultAuditPageSize,
+	}
+	var v validate.Validator
+	v.Check(q.Entity == "" || q.Entity.Valid(), "entity", validate.CodeInvalid, fmt.Sprintf("must be one of %v", models.AuditEntities))
+	v.Check(q.Action == "" || q.Action.Valid(), "action", validate.CodeInvalid, fmt.Sprintf("must be one of %v", models.AuditActions))
+	for _, name := range []string{"entityId", "userId", "before"} {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 {
+			v.Add(name, validate.CodeInvalid, "must be a positive integer")
+			continue
+		}
+		switch name {
+		case "entityId":
+			q.EntityID = int(n)
+		case "userId":
+			q.UserID = int(n)
+		case "before":
+			q.Before = n
+		}
+	}
+	for _, name := range []string{"from", "to"} {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			v.Add(name, validate.CodeInvalid, "must be an RFC 3339 timestamp")
+			continue
+		}
+		if name == "from" {
+			q.From = t
+		} else {
+			q.To = t
+		}
+	}
+	if !q.From.IsZero() && !q.To.IsZero() {
+		v.Check(q.From.Before(q.To), "from", validate.CodeTooLarge, "must be before to")
+	}
+	if raw := values.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxAuditPageSize {
+			v.Add("limit", validate.CodeInvalid, fmt.Sprintf("must be between 1 and %d", maxAuditPageSize))
+		} else {
+			q.Limit = n
+		}
+	}
+	if err := v.Err(); err != nil {
+		return q, apierr.Wrap(apierr.InvalidQuery, err).WithDetails(err)
+	}
+	return q, nil
+}
@@ -3,78 +3,217 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
-	"os"
 	"time"
 
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/audit"
+	"github.com/andrey-918/cafe-between/internal/logging"
+	"github.com/andrey-918/cafe-between/models"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret []byte
+// dummyHash is compared against when the username does not exist so that
+// unknown and known usernames take the same time to reject.
+var dummyHash, _ = models.HashPassword("cafe-between-dummy-password")
 
-func init() {
-	secret := os.Getenv("JWT_SECRET")
-	jwtSecret = []byte(secret)
-}
+type contextKey string
+
+const claimsKey contextKey = "claims"
 
 type Credentials struct {
+	Username string `json:"username"`
 	Password string `json:"password"`
 }
 
+type refreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
 type Claims struct {
-	UserID int    `json:"user_id"`
-	Role   string `json:"role"`
+	UserID         int         `json:"user_id"`
+	Username       string      `json:"username"`
+	Role           models.Role `json:"role"`
+	SessionVersion int         `json:"sv"`
 	jwt.RegisteredClaims
 }
 
-func generateToken() (string, error) {
-	claims := Claims{
-		UserID: 1,
-		Role:   "admin",
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+// ClaimsFromContext returns the claims JWTMiddleware stored for the request.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*Claims)
+	return claims, ok
 }
 
-func LoginHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	var creds Credentials
 	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, r, apierr.Wrap(apierr.InvalidJSON, err))
+		return
+	}
+
+	user, err := h.Users.GetByUsername(r.Context(), creds.Username)
+	if err != nil {
+		if !errors.Is(err, models.ErrUserNotFound) {
+			writeError(w, r, err)
+			return
+		}
+		user = models.User{PasswordHash: dummyHash}
+	}
+	if !user.CheckPassword(creds.Password) || user.ID == 0 {
+		h.recordLogin(r, models.AuditLoginFailed, user.ID, creds.Username)
+		writeError(w, r, apierr.New(apierr.InvalidCredentials))
+		return
+	}
+
+	tokens, _, err := h.issueTokens(r.Context(), user)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	h.recordLogin(r, models.AuditLogin, user.ID, user.Username)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// recordLogin adds a sign-in attempt to the audit log. userID is zero when
+// the username is unknown. It is recorded even if the client has gone
+// away; a failure to record is logged and does not affect the response.
+func (h *Handler) recordLogin(r *http.Request, action models.AuditAction, userID int, username string) {
+	ctx := audit.WithActor(r.Context(), audit.Actor{UserID: userID, Username: username, IP: clientIP(r)})
+	entry := audit.Entry(ctx, action, models.AuditSession, userID)
+	if err := h.Audit.Record(context.WithoutCancel(ctx), entry); err != nil {
+		slog.ErrorContext(ctx, "failed to write audit log entry", "action", action, "err", err)
+	}
+}
+
+// clientIP is the address the request came from. The server is expected
+// to be reached directly, so forwarding headers are not trusted.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RefreshHandler exchanges a refresh token for a new access token and a new
+// refresh token. Each refresh token works once; presenting one that was
+// already rotated is treated as theft and signs the user out everywhere.
+func (h *Handler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		writeError(w, r, apierr.Wrap(apierr.InvalidJSON, err))
 		return
 	}
+	ctx := r.Context()
 
-	adminPassword := os.Getenv("ADMIN_PASSWORD")
+	stored, err := h.Tokens.GetRefreshToken(ctx, models.HashToken(req.RefreshToken))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if stored.RevokedAt != nil {
+		// A token that was rotated, rather than revoked by logout or a
+		// password change, should never be presented again.
+		if stored.ReplacedBy != nil {
+			h.revokeReusedToken(ctx, stored.UserID)
+		}
+		writeError(w, r, apierr.New(apierr.InvalidRefresh))
+		return
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		writeError(w, r, apierr.New(apierr.RefreshExpired))
+		return
+	}
 
-	if creds.Password != adminPassword {
-		http.Error(w, "Invalid password", http.StatusUnauthorized)
+	user, err := h.Users.GetByID(ctx, stored.UserID)
+	if errors.Is(err, models.ErrUserNotFound) {
+		err = apierr.Wrap(apierr.InvalidRefresh, err)
+	}
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	token, err := generateToken()
+	tokens, newID, err := h.issueTokens(ctx, user)
 	if err != nil {
-		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+	if err := h.Tokens.RevokeRefreshToken(ctx, stored.ID, &newID); err != nil {
+		// Another request rotated the same token first.
+		if errors.Is(err, models.ErrRefreshTokenRevoked) {
+			h.revokeReusedToken(ctx, stored.UserID)
+		}
+		writeError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"token": token})
+	json.NewEncoder(w).Encode(tokens)
+}
+
+func (h *Handler) revokeReusedToken(ctx context.Context, userID int) {
+	slog.WarnContext(ctx, "refresh token reuse detected, revoking all sessions", "target_user_id", userID)
+	if err := h.Tokens.RevokeUserSessions(ctx, userID); err != nil {
+		slog.ErrorContext(ctx, "failed to revoke sessions", "target_user_id", userID, "err", err)
+	}
 }
 
-func LogoutHandler(w http.ResponseWriter, r *http.Request) {
-	// For JWT, logout is handled on client side by removing token
-	w.WriteHeader(http.StatusOK)
+// LogoutHandler revokes the access token used for the request and, if the
+// body carries one, the refresh token of the same session.
+func (h *Handler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, r, apierr.New(apierr.MissingToken))
+		return
+	}
+	var req refreshRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	ctx := r.Context()
+	if err := h.Tokens.RevokeAccessToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if req.RefreshToken != "" {
+		stored, err := h.Tokens.GetRefreshToken(ctx, models.HashToken(req.RefreshToken))
+		if err == nil && stored.UserID == claims.UserID {
+			err = h.Tokens.RevokeRefreshToken(ctx, stored.ID, nil)
+		}
+		if err != nil && !errors.Is(err, models.ErrRefreshTokenNotFound) && !errors.Is(err, models.ErrRefreshTokenRevoked) {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"message": "Logout successful"})
 }
 
-func JWTMiddleware(next http.Handler) http.Handler {
+// RevokeOwnSessionsHandler signs the current user out on every device.
+func (h *Handler) RevokeOwnSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, r, apierr.New(apierr.MissingToken))
+		return
+	}
+	if err := h.Tokens.RevokeUserSessions(r.Context(), claims.UserID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) JWTMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "Missing token", http.StatusUnauthorized)
+			writeError(w, r, apierr.New(apierr.MissingToken))
 			return
 		}
 
@@ -85,17 +224,59 @@ func JWTMiddleware(next http.Handler) http.Handler {
 
 		claims := &Claims{}
 		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
+			return h.JWTSecret, nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+		if err != nil || !token.Valid || !claims.Role.Valid() || claims.ID == "" {
+			writeError(w, r, apierr.Wrap(apierr.InvalidToken, err))
+			return
+		}
+
+		revoked, err := h.Tokens.IsAccessTokenRevoked(r.Context(), claims.ID, claims.UserID, claims.SessionVersion)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if revoked {
+			writeError(w, r, apierr.New(apierr.TokenRevoked))
 			return
 		}
 
 		// Store claims in context for use in handlers
-		ctx := r.Context()
-		ctx = context.WithValue(ctx, "claims", claims)
+		ctx := context.WithValue(r.Context(), claimsKey, claims)
+		ctx = logging.With(ctx, slog.Int("user_id", claims.UserID), slog.String("user", claims.Username))
+		ctx = audit.WithActor(ctx, audit.Actor{UserID: claims.UserID, Username: claims.Username, IP: clientIP(r)})
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// RequireRole lets the request through only if the JWT role is one of
+// roles. Owners are always allowed. It must run after JWTMiddleware.
+func RequireRole(roles ...models.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				writeError(w, r, apierr.New(apierr.MissingToken))
+				return
+			}
+			if !hasRole(claims.Role, roles) {
+				writeError(w, r, apierr.New(apierr.Forbidden))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func hasRole(role models.Role, allowed []models.Role) bool {
+	if role == models.RoleOwner {
+		return true
+	}
+	for _, r := range allowed {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/validate"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+)
+
+// DayStart returns the most recent midnight, in the cafe's timezone, at or
+// before now. Items sold out before it are back on sale.
+func (h *Handler) DayStart(now time.Time) time.Time {
+	local := now.In(h.Location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, h.Location)
+}
+
+// today is the cafe's current date, as the YYYY-MM-DD season bounds use.
+func (h *Handler) today() string {
+	return time.Now().In(h.Location).Format(time.DateOnly)
+}
+
+// GetStopListHandler lists the items sold out today, in menu order, for the
+// barista screen.
+func (h *Handler) GetStopListHandler(w http.ResponseWriter, r *http.Request) {
+	q := models.MenuQuery{Availability: models.SoldOut}
+	categories, err := h.Categories.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	for _, c := range categories {
+		q.CategoryOrder = append(q.CategoryOrder, c.Slug)
+	}
+	page, err := h.Menu.List(r.Context(), q)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	h.withMenuImages(page.Items)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", staffCacheControl)
+	json.NewEncoder(w).Encode(page.Items)
+}
+
+// SetAvailabilityHandler changes only the availability of the item at {id}
+// and answers with the saved item. Baristas may put items on the stop-list
+// and take them off it; hiding items and setting seasons is left to menu
+// managers, and so is bringing back an item that is hidden or seasonal.
+func (h *Handler) SetAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.InvalidID))
+		return
+	}
+	var change models.AvailabilityChange
+	if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+		writeError(w, r, apierr.Wrap(apierr.InvalidJSON, err))
+		return
+	}
+	if change.Availability == "" {
+		// Normalize would default it; here a missing state is a mistake.
+		writeError(w, r, validate.Errors{{Field: "availability", Code: validate.CodeRequired, Message: "is required"}})
+		return
+	}
+	change.Normalize()
+	var v validate.Validator
+	change.Validate(&v)
+	if err := v.Err(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	item, err := h.Menu.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if claims, _ := ClaimsFromContext(r.Context()); claims != nil && !hasRole(claims.Role, []models.Role{models.RoleMenuManager}) &&
+		!(onStopList(item.Availability) && onStopList(change.Availability)) {
+		writeError(w, r, apierr.New(apierr.Forbidden))
+		return
+	}
+	err = h.Menu.SetAvailability(r.Context(), id, change)
+	if err == nil {
+		item, err = h.Menu.GetByID(r.Context(), id)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	item.Images = h.imageSets(item.ImageURLs)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(item.UpdatedAt))
+	json.NewEncoder(w).Encode(item)
+}
+
+// onStopList reports whether a is one of the two states the stop-list
+// toggles between.
+func onStopList(a models.Availability) bool {
+	return a == models.Available || a == models.SoldOut
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/validate"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+)
+
+// GetCategoriesHandler lists categories in display order. Staff see every
+// category; the public route leaves hidden ones out.
+func (h *Handler) GetCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.Categories.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if _, staff := ClaimsFromContext(r.Context()); !staff {
+		categories = visibleCategories(categories)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(categories)
+}
+
+func (h *Handler) CreateCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	category := models.Category{Visible: true}
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		writeError(w, r, apierr.Wrap(apierr.InvalidJSON, err))
+		return
+	}
+	category.Normalize()
+	var v validate.Validator
+	v.Check(models.ValidSlug(category.Slug), "slug", validate.CodeInvalid, "must be lowercase latin letters, digits, '-' or '_'")
+	category.Validate(&v)
+	if err := v.Err(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := h.Categories.Create(r.Context(), category); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	created, err := h.Categories.GetBySlug(r.Context(), category.Slug)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// UpdateCategoryHandler updates the category at {slug}. Fields the body
+// leaves out keep their stored values, so a rename does not hide the
+// category. The slug itself is what menu items refer to and cannot be
+// changed.
+func (h *Handler) UpdateCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+	category, err := h.Categories.GetBySlug(r.Context(), slug)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		writeError(w, r, apierr.Wrap(apierr.InvalidJSON, err))
+		return
+	}
+	category.Normalize()
+	var v validate.Validator
+	v.Check(category.Slug == "" || category.Slug == slug, "slug", validate.CodeInvalid, "cannot be changed")
+	category.Slug = slug
+	category.Validate(&v)
+	if err := v.Err(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := h.Categories.Update(r.Context(), slug, category); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DelCategoryHandler removes an empty category. Categories that still hold
+// menu items are refused with 409 so nothing is orphaned.
+func (h *Handler) DelCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+	category, err := h.Categories.GetBySlug(r.Context(), slug)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	used, err := h.Menu.List(r.Context(), models.MenuQuery{Category: slug, Limit: 1})
+	if err == nil && len(used.Items) > 0 {
+		err = models.ErrCategoryInUse
+	}
+	// Trashed items keep their category so they can be restored.
+	var trashed []models.MenuItem
+	if err == nil {
+		trashed, err = h.Menu.ListDeleted(r.Context())
+	}
+	for _, item := range trashed {
+		if err == nil && item.Category == slug {
+			err = models.ErrCategoryInUse
+		}
+	}
+	if err == nil {
+		err = h.Categories.Delete(r.Context(), slug)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if category.ImageURL != "" {
+		h.cleanupImages(r.Context(), []string{category.ImageURL})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func visibleCategories(categories []models.Category) []models.Category {
+	visible := []models.Category{}
+	for _, c := range categories {
+		if c.Visible {
+			visible = append(visible, c)
+		}
+	}
+	return visible
+}
+
+// categoriesStats summarises a category list for cache validators.
+func categoriesStats(categories []models.Category) models.ListStats {
+	stats := models.ListStats{Count: len(categories)}
+	for _, c := range categories {
+		if c.UpdatedAt.After(stats.LastModified) {
+			stats.LastModified = c.UpdatedAt
+		}
+	}
+	return stats
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andrey-918/cafe-between/models"
+)
+
+// defaultCacheControl lets browsers and CDNs reuse a public response for a
+// minute, then revalidate it, which usually costs a 304.
+const defaultCacheControl = "public, max-age=60"
+
+// staffCacheControl keeps staff responses out of shared caches and makes
+// browsers revalidate them every time, so edits show up at once.
+const staffCacheControl = "private, no-cache"
+
+// listETag derives a strong entity tag from everything a listing response
+// depends on: the request's query and the stats of the tables it reads.
+func listETag(r *http.Request, stats ...models.ListStats) string {
+	sum := sha256.New()
+	fmt.Fprintf(sum, "%s\n", r.URL.Query().Encode())
+	for _, s := range stats {
+		fmt.Fprintf(sum, "%d %d\n", s.Count, s.LastModified.UnixNano())
+	}
+	return `"` + hex.EncodeToString(sum.Sum(nil)[:16]) + `"`
+}
+
+// latest returns the newest LastModified among stats.
+func latest(stats ...models.ListStats) time.Time {
+	var t time.Time
+	for _, s := range stats {
+		if s.LastModified.After(t) {
+			t = s.LastModified
+		}
+	}
+	return t
+}
+
+// notModified sets the caching headers for a response identified by tag
+// and lastModified. If the request's If-None-Match or, failing that,
+// If-Modified-Since shows the client already has it, notModified answers
+// 304 and returns true.
+func (h *Handler) notModified(w http.ResponseWriter, r *http.Request, tag string, lastModified time.Time) bool {
+	cacheControl := h.CacheControl
+	if _, staff := ClaimsFromContext(r.Context()); staff {
+		cacheControl = staffCacheControl
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("ETag", tag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	fresh := false
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, candidate := range strings.Split(header, ",") {
+			// If-None-Match uses the weak comparison.
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			fresh = fresh || candidate == tag || candidate == "*"
+		}
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastModified.IsZero() {
+		fresh = !lastModified.Truncate(time.Second).After(since)
+	}
+	if fresh {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return fresh
+}
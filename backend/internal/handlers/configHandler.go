@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// clientConfig is the deployment configuration the frontend needs.
+type clientConfig struct {
+	// Timezone is the IANA name of the cafe's timezone, e.g.
+	// "Europe/Moscow".
+	Timezone string `json:"timezone"`
+}
+
+// GetConfigHandler publishes the settings clients use for presentation.
+func (h *Handler) GetConfigHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", h.CacheControl)
+	json.NewEncoder(w).Encode(clientConfig{Timezone: h.Location.String()})
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/logging"
+	"github.com/andrey-918/cafe-between/internal/mergepatch"
+	"github.com/andrey-918/cafe-between/internal/validate"
+	"github.com/andrey-918/cafe-between/models"
+)
+
+// errorResponse is the body of every error the API returns. Clients branch
+// on Code; Message is for people and follows the Accept-Language header.
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code      apierr.Code `json:"code"`
+	Message   string      `json:"message"`
+	RequestID string      `json:"requestId"`
+	Details   any         `json:"details,omitempty"`
+}
+
+// sentinelCodes maps the repositories' sentinel errors to API codes, so
+// handlers can pass them to writeError untouched.
+var sentinelCodes = []struct {
+	err  error
+	code apierr.Code
+}{
+	{models.ErrMenuItemNotFound, apierr.MenuItemNotFound},
+	{models.ErrNewsNotFound, apierr.NewsNotFound},
+	{models.ErrRevisionNotFound, apierr.RevisionNotFound},
+	{models.ErrCategoryNotFound, apierr.CategoryNotFound},
+	{models.ErrCategoryExists, apierr.CategoryExists},
+	{models.ErrCategoryInUse, apierr.CategoryInUse},
+	{models.ErrUserNotFound, apierr.UserNotFound},
+	{models.ErrUsernameTaken, apierr.UsernameTaken},
+	{models.ErrRefreshTokenNotFound, apierr.InvalidRefresh},
+	{models.ErrRefreshTokenRevoked, apierr.InvalidRefresh},
+	{models.ErrInvalidCursor, apierr.InvalidQuery},
+	{models.ErrEditConflict, apierr.EditConflict},
+	{mergepatch.ErrInvalidPatch, apierr.InvalidJSON},
+}
+
+// toAPIError resolves err to the code and details sent to the client.
+// Errors that are not recognised become apierr.Internal.
+func toAPIError(err error) *apierr.Error {
+	var apiErr *apierr.Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	var fields validate.Errors
+	if errors.As(err, &fields) {
+		return apierr.Wrap(apierr.ValidationFailed, err).WithDetails(fields)
+	}
+	for _, s := range sentinelCodes {
+		if errors.Is(err, s.err) {
+			return apierr.Wrap(s.code, err)
+		}
+	}
+	return apierr.Wrap(apierr.Internal, err)
+}
+
+// writeError sends err as an errorResponse. Internal errors are logged with
+// the request ID; their cause is never shown to the client.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := toAPIError(err)
+	entry := apierr.Lookup(apiErr.Code)
+	id := requestID(w, r)
+	if entry.Code == apierr.Internal {
+		ctx := r.Context()
+		if _, ok := RequestIDFromContext(ctx); !ok {
+			ctx = logging.With(ctx, slog.String("request_id", id))
+		}
+		slog.ErrorContext(ctx, "request failed", "method", r.Method, "path", r.URL.Path, "err", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(entry.Status)
+	json.NewEncoder(w).Encode(errorResponse{Error: errorBody{
+		Code:      entry.Code,
+		Message:   entry.Message(apierr.Language(r.Header.Get("Accept-Language"))),
+		RequestID: id,
+		Details:   apiErr.Details,
+	}})
+}
+
+// NotFoundHandler and MethodNotAllowedHandler answer requests the router
+// has no route for, in the same format as every other error.
+func NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, r, apierr.New(apierr.RouteNotFound))
+	})
+}
+
+func MethodNotAllowedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, r, apierr.New(apierr.MethodNotAllowed))
+	})
+}
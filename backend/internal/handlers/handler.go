@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/storage"
+	"github.com/andrey-918/cafe-between/models"
+)
+
+// Handler carries the repositories the HTTP handlers read from and write to.
+// main wires it with the Postgres implementations; tests use the in-memory
+// ones.
+type Handler struct {
+	Menu       models.MenuRepository
+	Categories models.CategoryRepository
+	News       models.NewsRepository
+	Users      models.UserRepository
+	Tokens     models.TokenRepository
+	// Audit is the log of admin changes and sign-ins. Menu and News are
+	// expected to record their changes to it; the handlers record logins.
+	Audit models.AuditRepository
+	// Storage holds uploaded images.
+	Storage storage.Storage
+
+	// JWTSecret signs and verifies access tokens.
+	JWTSecret       []byte
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	MaxUploadSize   int64
+	// CacheControl is sent with public menu and news responses. Staff
+	// responses are never cached by shared caches.
+	CacheControl string
+	// Location is the cafe's timezone. Timestamps are stored and served in
+	// UTC; the zone is published to clients, which use it for display and
+	// for interpreting local dates typed into the admin forms.
+	Location *time.Location
+	// TrashRetention is how long deleted menu items and news posts can be
+	// restored before PurgeTrash removes them.
+	TrashRetention time.Duration
+	// ReadinessChecks are run by /readyz.
+	ReadinessChecks []ReadinessCheck
+	// Metrics serves /metrics to requests bearing MetricsToken; without
+	// either the route answers 404.
+	Metrics      http.Handler
+	MetricsToken string
+
+	draining atomic.Bool
+}
+
+func New(menu models.MenuRepository, categories models.CategoryRepository, news models.NewsRepository, users models.UserRepository, tokens models.TokenRepository, auditLog models.AuditRepository, store storage.Storage) *Handler {
+	return &Handler{
+		Menu:            menu,
+		Categories:      categories,
+		News:            news,
+		Users:           users,
+		Tokens:          tokens,
+		Audit:           auditLog,
+		Storage:         store,
+		AccessTokenTTL:  defaultAccessTokenTTL,
+		RefreshTokenTTL: defaultRefreshTokenTTL,
+		MaxUploadSize:   defaultMaxUploadSize,
+		CacheControl:    defaultCacheControl,
+		Location:        time.UTC,
+		TrashRetention:  defaultTrashRetention,
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+)
+
+// readinessTimeout bounds each readiness check, so a hung database fails
+// the probe instead of stalling it.
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck is one dependency /readyz verifies, such as the database.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// HealthzHandler is the liveness probe: it answers as long as the process
+// serves requests at all.
+func (h *Handler) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, healthResponse{Status: "ok"})
+}
+
+// ReadyzHandler is the readiness probe. It fails once shutdown has begun,
+// so the orchestrator stops routing new requests here while in-flight ones
+// drain, and whenever one of the readiness checks fails. Failure details go
+// to the log rather than to the caller.
+func (h *Handler) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	if h.draining.Load() {
+		writeHealth(w, http.StatusServiceUnavailable, healthResponse{Status: "shutting_down"})
+		return
+	}
+	resp := healthResponse{Status: "ok", Checks: map[string]string{}}
+	status := http.StatusOK
+	for _, c := range h.ReadinessChecks {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		err := c.Check(ctx)
+		cancel()
+		if err != nil {
+			slog.WarnContext(r.Context(), "readiness check failed", "check", c.Name, "err", err)
+			resp.Checks[c.Name] = "failing"
+			resp.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		resp.Checks[c.Name] = "ok"
+	}
+	writeHealth(w, status, resp)
+}
+
+// MetricsHandler serves the Prometheus metrics, when they are enabled, to
+// a scraper sending the metrics token. Any other request gets the same 404
+// as an unknown path, so the endpoint is not advertised.
+func (h *Handler) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if h.Metrics == nil || h.MetricsToken == "" || !ok ||
+		subtle.ConstantTimeCompare([]byte(token), []byte(h.MetricsToken)) != 1 {
+		writeError(w, r, apierr.New(apierr.RouteNotFound))
+		return
+	}
+	h.Metrics.ServeHTTP(w, r)
+}
+
+// Drain makes /readyz fail from now on. The server calls it when shutdown
+// starts.
+func (h *Handler) Drain() {
+	h.draining.Store(true)
+}
+
+func writeHealth(w http.ResponseWriter, status int, resp healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
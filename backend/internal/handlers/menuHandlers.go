@@ -1,111 +1,313 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/validate"
 	"github.com/andrey-918/cafe-between/models"
 	"github.com/gorilla/mux"
 )
 
-
-func CreateMenuItemHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateMenuItemHandler(w http.ResponseWriter, r *http.Request) {
 	var item models.MenuItem
 	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, r, apierr.Wrap(apierr.InvalidJSON, err))
+		return
+	}
+	item.Normalize()
+	if err := h.validateMenuItem(r.Context(), item); err != nil {
+		writeError(w, r, err)
 		return
 	}
-	id, err := models.CreateMenuItem(item)
+	id, err := h.Menu.Create(r.Context(), item)
 	if err != nil {
-		http.Error(w, "Failed to create menu item", http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
-	createdMenuItem, err := models.GetMenuItemByID(id)
-	if err != nil { 
-		http.Error(w, "Failed to fetch created menu item", http.StatusInternalServerError)
+	createdMenuItem, err := h.Menu.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
+	createdMenuItem.Images = h.imageSets(createdMenuItem.ImageURLs)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(createdMenuItem)
 }
 
-func GetMenuHandler(w http.ResponseWriter, r *http.Request) {
-	menu, err := models.GetMenu()
+// menuResponse is a page of menu items together with the categories they
+// are grouped under, in display order.
+type menuResponse struct {
+	models.MenuPage
+	Categories []models.Category `json:"categories"`
+}
+
+// GetMenuHandler lists menu items one page at a time. Supported query
+// parameters are category, minPrice, maxPrice, maxCalories, availability, q
+// (matched against title and description), sort, limit and cursor, the last
+// being the nextCursor value of the previous page.
+//
+// Without a sort parameter items come grouped by category in the configured
+// order. The public route leaves out hidden categories and their items,
+// hidden items and seasonal ones out of season; sold-out items are listed
+// with their availability so the page can mark them.
+func (h *Handler) GetMenuHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := parseMenuQuery(r.URL.Query())
 	if err != nil {
-		http.Error(w, "Failed to fetch menu", http.StatusInternalServerError)
-		return 
+		writeError(w, r, err)
+		return
+	}
+	categories, err := h.Categories.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	_, staff := ClaimsFromContext(r.Context())
+	for _, c := range categories {
+		q.CategoryOrder = append(q.CategoryOrder, c.Slug)
+		if !c.Visible && !staff {
+			q.HiddenCategories = append(q.HiddenCategories, c.Slug)
+		}
+	}
+	if !staff {
+		categories = visibleCategories(categories)
+		q.AvailableOn = h.today()
+	}
+	menuStats, err := h.Menu.Stats(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	stats := []models.ListStats{menuStats, categoriesStats(categories)}
+	if !staff {
+		// Seasonal items come and go at midnight without any write, so the
+		// public listing is a different one every day.
+		stats = append(stats, models.ListStats{LastModified: h.DayStart(time.Now())})
+	}
+	if h.notModified(w, r, listETag(r, stats...), latest(stats...)) {
+		return
 	}
+	page, err := h.Menu.List(r.Context(), q)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	h.withMenuImages(page.Items)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(menu)
+	json.NewEncoder(w).Encode(menuResponse{MenuPage: page, Categories: categories})
+}
+
+const (
+	defaultMenuPageSize = 50
+	maxMenuPageSize     = 100
+)
+
+// parseMenuQuery reads the listing parameters. Every malformed parameter is
+// reported, as an invalid_query error with one detail per parameter.
+func parseMenuQuery(values url.Values) (models.MenuQuery, error) {
+	q := models.MenuQuery{
+		Category:     values.Get("category"),
+		Search:       strings.TrimSpace(values.Get("q")),
+		Sort:         models.MenuSort(values.Get("sort")),
+		Availability: models.Availability(values.Get("availability")),
+		Limit:        defaultMenuPageSize,
+	}
+	var v validate.Validator
+	v.Check(q.Sort.Valid(), "sort", validate.CodeInvalid, fmt.Sprintf("must be one of %v", models.MenuSorts))
+	v.Check(q.Availability == "" || q.Availability.Valid(), "availability", validate.CodeInvalid, fmt.Sprintf("must be one of %v", models.Availabilities))
+	for _, name := range []string{"minPrice", "maxPrice", "maxCalories"} {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			v.Add(name, validate.CodeInvalid, "must be a non-negative integer")
+			continue
+		}
+		switch name {
+		case "minPrice":
+			q.MinPrice = &n
+		case "maxPrice":
+			q.MaxPrice = &n
+		case "maxCalories":
+			q.MaxCalories = &n
+		}
+	}
+	if q.MinPrice != nil && q.MaxPrice != nil {
+		v.Check(*q.MinPrice <= *q.MaxPrice, "minPrice", validate.CodeTooLarge, "must not exceed maxPrice")
+	}
+	if raw := values.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxMenuPageSize {
+			v.Add("limit", validate.CodeInvalid, fmt.Sprintf("must be between 1 and %d", maxMenuPageSize))
+		} else {
+			q.Limit = n
+		}
+	}
+	if raw := values.Get("cursor"); raw != "" && q.Sort.Valid() {
+		cursor, err := models.DecodeMenuCursor(raw, q.Sort)
+		v.Check(err == nil, "cursor", validate.CodeInvalid, "does not belong to this listing")
+		q.Cursor = cursor
+	}
+	if err := v.Err(); err != nil {
+		return q, apierr.Wrap(apierr.InvalidQuery, err).WithDetails(err)
+	}
+	return q, nil
 }
 
-func GetMenuItemHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetMenuItemHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.InvalidID))
 		return
 	}
 
-	item, err := models.GetMenuItemByID(id)
+	item, err := h.Menu.GetByID(r.Context(), id)
+	if err == nil && !item.ListedOn(h.today()) {
+		err = models.ErrMenuItemNotFound
+	}
+	if err == nil && item.Category != "" {
+		err = h.checkCategoryListed(r.Context(), item.Category)
+	}
 	if err != nil {
-		if errors.Is(err, models.ErrMenuItemNotFound) {
-			http.Error(w, "Menu item not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Failed to fetch menu item", http.StatusInternalServerError)
-		}
+		writeError(w, r, err)
+		return
+	}
+	if h.notModified(w, r, etag(item.UpdatedAt), item.UpdatedAt) {
 		return
 	}
+	item.Images = h.imageSets(item.ImageURLs)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(item)
 }
 
-func DelMenuItemHandler(w http.ResponseWriter, r *http.Request) {
+// checkCategoryListed fails with ErrMenuItemNotFound when the category is
+// hidden, so its items are not served publicly one by one either. An item
+// whose category no longer exists is listed, as on the public menu.
+func (h *Handler) checkCategoryListed(ctx context.Context, slug string) error {
+	category, err := h.Categories.GetBySlug(ctx, slug)
+	switch {
+	case errors.Is(err, models.ErrCategoryNotFound):
+		return nil
+	case err != nil:
+		return err
+	case !category.Visible:
+		return models.ErrMenuItemNotFound
+	}
+	return nil
+}
+
+// DelMenuItemHandler moves the item at {id} to the trash. Its images stay
+// until the item is purged.
+func (h *Handler) DelMenuItemHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.InvalidID))
 		return
 	}
-	err = models.DelMenuItem(id)
+	err = h.Menu.Delete(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, models.ErrMenuItemNotFound) {
-			http.Error(w, "Menu item not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Failed to delete menu item", http.StatusInternalServerError)
-		}
+		writeError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func UpdateMenuHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateMenuHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.InvalidID))
 		return
 	}
 
 	var item models.MenuItem
 	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, r, apierr.Wrap(apierr.InvalidJSON, err))
 		return
 	}
-	err = models.UpdateMenuItem(id, item)
-	if err != nil {
-		if errors.Is(err, models.ErrMenuItemNotFound) {
-			http.Error(w, "Menu item not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Failed to update menu item", http.StatusInternalServerError)
+	item.Normalize()
+	if err := h.validateMenuItem(r.Context(), item); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// PUT replaces the item outright; a client that sends If-Match still
+	// gets the conflict check PATCH always makes.
+	if r.Header.Get("If-Match") != "" {
+		var current models.MenuItem
+		current, err = h.Menu.GetByID(r.Context(), id)
+		if err == nil {
+			err = checkVersion(r, nil, current.UpdatedAt, false)
 		}
+		if err == nil {
+			err = h.Menu.UpdateIfUnmodified(r.Context(), id, item, current.UpdatedAt)
+		}
+	} else {
+		err = h.Menu.Update(r.Context(), id, item)
+	}
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+}
+
+// PatchMenuItemHandler applies a JSON Merge Patch to the item at {id}: only
+// the members present in the body change, and null clears one. The client
+// must name the version it edited, with If-Match or an updatedAt member, and
+// an edit of an outdated version fails with 412. The response is the saved
+// item.
+func (h *Handler) PatchMenuItemHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.InvalidID))
+		return
+	}
+	patch, members, err := readMergePatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	current, err := h.Menu.GetByID(r.Context(), id)
+	if err == nil {
+		err = checkVersion(r, members, current.UpdatedAt, true)
+	}
+	var item models.MenuItem
+	if err == nil {
+		err = applyMergePatch(current, patch, &item)
+	}
+	if err == nil {
+		item.Normalize()
+		err = h.validateMenuItem(r.Context(), item)
+	}
+	if err == nil {
+		err = h.Menu.UpdateIfUnmodified(r.Context(), id, item, current.UpdatedAt)
+	}
+	var updated models.MenuItem
+	if err == nil {
+		updated, err = h.Menu.GetByID(r.Context(), id)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	updated.Images = h.imageSets(updated.ImageURLs)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(updated.UpdatedAt))
+	json.NewEncoder(w).Encode(updated)
+}
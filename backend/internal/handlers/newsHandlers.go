@@ -2,110 +2,214 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
+	"slices"
 	"strconv"
+	"time"
 
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/validate"
 	"github.com/andrey-918/cafe-between/models"
 	"github.com/gorilla/mux"
 )
 
-
-func CreateNewsHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateNewsHandler(w http.ResponseWriter, r *http.Request) {
 	var item models.News
 	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, r, apierr.Wrap(apierr.InvalidJSON, err))
+		return
+	}
+	item.Normalize()
+	if err := validateNews(item); err != nil {
+		writeError(w, r, err)
 		return
 	}
-	id, err := models.CreateNews(item)
+	item.Schedule(time.Now().UTC(), nil)
+	id, err := h.News.Create(r.Context(), item)
 	if err != nil {
-		http.Error(w, "Failed to create News item", http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
-	createdNews, err := models.GetNewsByID(id)
-	if err != nil { 
-		http.Error(w, "Failed to fetch created News item", http.StatusInternalServerError)
+	createdNews, err := h.News.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
+	createdNews.Images = h.imageSets(createdNews.ImageURLs)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(createdNews)
 }
 
-func GetNewsHandler(w http.ResponseWriter, r *http.Request) {
-	news, err := models.GetNews()
+// GetNewsHandler lists news newest first. The public only sees posts that
+// are live; staff see every post and may narrow the list with ?status=.
+func (h *Handler) GetNewsHandler(w http.ResponseWriter, r *http.Request) {
+	var news []models.News
+	var err error
+	if _, staff := ClaimsFromContext(r.Context()); staff {
+		news, err = h.News.List(r.Context())
+		if status := models.NewsStatus(r.URL.Query().Get("status")); status != "" && err == nil {
+			if !status.Valid() {
+				writeError(w, r, apierr.New(apierr.InvalidQuery).WithDetails(validate.Errors{{Field: "status", Code: validate.CodeInvalid, Message: "unknown status"}}))
+				return
+			}
+			news = slices.DeleteFunc(news, func(item models.News) bool { return item.Status != status })
+		}
+		if stats := newsStats(news); err == nil && h.notModified(w, r, listETag(r, stats), stats.LastModified) {
+			return
+		}
+	} else {
+		now := time.Now().UTC()
+		var stats models.ListStats
+		stats, err = h.News.VisibleStats(r.Context(), now)
+		if err == nil && h.notModified(w, r, listETag(r, stats), stats.LastModified) {
+			return
+		}
+		if err == nil {
+			news, err = h.News.ListVisible(r.Context(), now)
+		}
+	}
 	if err != nil {
-		http.Error(w, "Failed to fetch news", http.StatusInternalServerError)
-		return 
+		writeError(w, r, err)
+		return
 	}
+	h.withNewsImages(news)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(news)
 }
 
-func GetNewsByIdHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetNewsByIdHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.InvalidID))
 		return
 	}
 
-	item, err := models.GetNewsByID(id)
+	item, err := h.News.GetByID(r.Context(), id)
+	if _, staff := ClaimsFromContext(r.Context()); err == nil && !staff && !item.VisibleAt(time.Now().UTC()) {
+		err = models.ErrNewsNotFound
+	}
 	if err != nil {
-		if errors.Is(err, models.ErrNewsNotFound) {
-			http.Error(w, "News item not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Failed to fetch News item", http.StatusInternalServerError)
-		}
+		writeError(w, r, err)
+		return
+	}
+	if h.notModified(w, r, etag(item.UpdatedAt), item.UpdatedAt) {
 		return
 	}
+	item.Images = h.imageSets(item.ImageURLs)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(item)
 }
 
-func DelNewsHandler(w http.ResponseWriter, r *http.Request) {
+// DelNewsHandler moves the post at {id} to the trash. Its images stay until
+// the post is purged.
+func (h *Handler) DelNewsHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.InvalidID))
 		return
 	}
-	err = models.DelNews(id)
+	err = h.News.Delete(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, models.ErrNewsNotFound) {
-			http.Error(w, "News not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Failed to delete news", http.StatusInternalServerError)
-		}
+		writeError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func UpdateNewsHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateNewsHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.InvalidID))
 		return
 	}
 
 	var item models.News
 	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, r, apierr.Wrap(apierr.InvalidJSON, err))
 		return
 	}
-	err = models.UpdateNews(id, item)
-	if err != nil {
-		if errors.Is(err, models.ErrNewsNotFound) {
-			http.Error(w, "News not found", http.StatusNotFound)
+	item.Normalize()
+	if err := validateNews(item); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	previous, err := h.News.GetByID(r.Context(), id)
+	if err == nil {
+		err = checkVersion(r, nil, previous.UpdatedAt, false)
+	}
+	if err == nil {
+		item.Schedule(time.Now().UTC(), &previous)
+		if r.Header.Get("If-Match") != "" {
+			err = h.News.UpdateIfUnmodified(r.Context(), id, item, previous.UpdatedAt)
 		} else {
-			http.Error(w, "Failed to update news", http.StatusInternalServerError)
+			err = h.News.Update(r.Context(), id, item)
 		}
+	}
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+}
+
+// PatchNewsHandler applies a JSON Merge Patch to the post at {id}, with the
+// same version check as PatchMenuItemHandler. Status and publishedAt are
+// settled by the usual scheduling rules afterwards.
+func (h *Handler) PatchNewsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.InvalidID))
+		return
+	}
+	patch, members, err := readMergePatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	previous, err := h.News.GetByID(r.Context(), id)
+	if err == nil {
+		err = checkVersion(r, members, previous.UpdatedAt, true)
+	}
+	var item models.News
+	if err == nil {
+		err = applyMergePatch(previous, patch, &item)
+	}
+	if err == nil {
+		item.Normalize()
+		err = validateNews(item)
+	}
+	if err == nil {
+		item.Schedule(time.Now().UTC(), &previous)
+		err = h.News.UpdateIfUnmodified(r.Context(), id, item, previous.UpdatedAt)
+	}
+	var updated models.News
+	if err == nil {
+		updated, err = h.News.GetByID(r.Context(), id)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	updated.Images = h.imageSets(updated.ImageURLs)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(updated.UpdatedAt))
+	json.NewEncoder(w).Encode(updated)
+}
+
+// newsStats summarises a staff news listing, which is loaded anyway.
+func newsStats(news []models.News) models.ListStats {
+	stats := models.ListStats{Count: len(news)}
+	for _, item := range news {
+		if item.UpdatedAt.After(stats.LastModified) {
+			stats.LastModified = item.UpdatedAt
+		}
+	}
+	return stats
+}
@@ -0,0 +1,506 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/mergepatch"
+	"github.com/andrey-918/cafe-between/internal/openapi"
+	"github.com/andrey-918/cafe-between/models"
+)
+
+// operation documents one entry of the route table. Authentication, roles
+// and path parameters are read from the Route itself; the rest is declared
+// here, next to the other operations, so a new route cannot be added
+// without describing it: OpenAPI fails on any route missing from the table.
+type operation struct {
+	summary string
+	tag     string
+	query   []openapi.Parameter
+	// request is a value of the body type. Its content type is
+	// requestType, application/json when empty; optionalBody marks a body
+	// the handler does without.
+	request      any
+	requestType  string
+	optionalBody bool
+	// responses maps each success status to a value of the body type; a
+	// nil value means the status has no body. Bodies are contentType,
+	// application/json when empty.
+	responses   map[int]any
+	contentType string
+	// errors lists the API error codes the handler itself sends. The codes
+	// every route of its kind can send, such as the authentication
+	// failures, are added by OpenAPI.
+	errors []apierr.Code
+	// cacheable marks a GET answering 304 to a matching If-None-Match.
+	cacheable bool
+	// ifMatch marks an update that checks If-Match; when ifMatchRequired
+	// is set the version must be sent.
+	ifMatch, ifMatchRequired bool
+}
+
+const (
+	jsonType      = "application/json"
+	multipartType = "multipart/form-data"
+)
+
+func nonNegative() *openapi.Schema {
+	zero := 0.0
+	return &openapi.Schema{Type: "integer", Minimum: &zero}
+}
+
+func queryParam(name, description string, schema *openapi.Schema) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func menuQueryParams() []openapi.Parameter {
+	one, most := 1.0, float64(maxMenuPageSize)
+	sorts := make([]string, len(models.MenuSorts))
+	for i, s := range models.MenuSorts {
+		sorts[i] = string(s)
+	}
+	availabilities := make([]string, len(models.Availabilities))
+	for i, a := range models.Availabilities {
+		availabilities[i] = string(a)
+	}
+	return []openapi.Parameter{
+		queryParam("category", "Only items of the category with this slug.", &openapi.Schema{Type: "string"}),
+		queryParam("minPrice", "Lowest price, inclusive.", nonNegative()),
+		queryParam("maxPrice", "Highest price, inclusive.", nonNegative()),
+		queryParam("maxCalories", "Highest calorie count, inclusive.", nonNegative()),
+		queryParam("availability", "Only items in this state.", &openapi.Schema{Type: "string", Enum: availabilities}),
+		queryParam("q", "Text matched against title and description.", &openapi.Schema{Type: "string"}),
+		queryParam("sort", "Sort order; without it items are grouped by category.", &openapi.Schema{Type: "string", Enum: sorts}),
+		queryParam("limit", fmt.Sprintf("Page size, %d by default.", defaultMenuPageSize), &openapi.Schema{Type: "integer", Minimum: &one, Maximum: &most}),
+		queryParam("cursor", "nextCursor of the previous page.", &openapi.Schema{Type: "string"}),
+	}
+}
+
+func revisionDiffParams() []openapi.Parameter {
+	one := 1.0
+	from := queryParam("from", "The earlier revision.", &openapi.Schema{Type: "integer", Minimum: &one})
+	from.Required = true
+	return []openapi.Parameter{
+		from,
+		queryParam("to", "The later revision; the current version when left out.", &openapi.Schema{Type: "integer", Minimum: &one}),
+	}
+}
+
+func auditQueryParams() []openapi.Parameter {
+	one, most := 1.0, float64(maxAuditPageSize)
+	entities := make([]string, len(models.AuditEntities))
+	for i, e := range models.AuditEntities {
+		entities[i] = string(e)
+	}
+	actions := make([]string, len(models.AuditActions))
+	for i, a := range models.AuditActions {
+		actions[i] = string(a)
+	}
+	id := &openapi.Schema{Type: "integer", Minimum: &one}
+	timestamp := &openapi.Schema{Type: "string", Format: "date-time"}
+	return []openapi.Parameter{
+		queryParam("entity", "Only entries about this kind of entity.", &openapi.Schema{Type: "string", Enum: entities}),
+		queryParam("entityId", "Only entries about the entity with this ID.", id),
+		queryParam("userId", "Only entries by this user.", id),
+		queryParam("action", "Only entries for this action.", &openapi.Schema{Type: "string", Enum: actions}),
+		queryParam("from", "Earliest time, inclusive.", timestamp),
+		queryParam("to", "Latest time, exclusive.", timestamp),
+		queryParam("before", "ID of the last entry of the previous page.", id),
+		queryParam("limit", fmt.Sprintf("Page size, %d by default.", defaultAuditPageSize), &openapi.Schema{Type: "integer", Minimum: &one, Maximum: &most}),
+	}
+}
+
+// operations describes every route of Routes, keyed by "METHOD path".
+func operations() map[string]operation {
+	statuses := make([]string, len(models.NewsStatuses))
+	for i, s := range models.NewsStatuses {
+		statuses[i] = string(s)
+	}
+
+	return map[string]operation{
+		"GET /healthz": {summary: "Liveness probe", tag: "system",
+			responses: map[int]any{http.StatusOK: healthResponse{}}},
+		"GET /readyz": {summary: "Readiness probe: database and migrations", tag: "system",
+			responses: map[int]any{http.StatusOK: healthResponse{}, http.StatusServiceUnavailable: healthResponse{}}},
+		"GET /metrics": {summary: "Prometheus metrics, for a scraper sending the METRICS_TOKEN bearer token", tag: "system",
+			responses: map[int]any{http.StatusOK: ""}, contentType: "text/plain",
+			errors: []apierr.Code{apierr.RouteNotFound}},
+		"GET /api/openapi.json": {summary: "This document", tag: "system",
+			responses: map[int]any{http.StatusOK: map[string]any{}}},
+		"GET /api/docs": {summary: "API documentation page", tag: "system",
+			responses: map[int]any{http.StatusOK: ""}, contentType: "text/html"},
+		"GET /api/config": {summary: "Settings the frontend needs", tag: "system",
+			responses: map[int]any{http.StatusOK: clientConfig{}}},
+
+		"GET /api/menu": {summary: "List visible menu items, one page at a time", tag: "menu",
+			query: menuQueryParams(), responses: map[int]any{http.StatusOK: menuResponse{}},
+			errors: []apierr.Code{apierr.InvalidQuery}, cacheable: true},
+		"GET /api/menu/{id}": {summary: "Get a menu item", tag: "menu",
+			responses: map[int]any{http.StatusOK: models.MenuItem{}},
+			errors:    []apierr.Code{apierr.MenuItemNotFound}, cacheable: true},
+		"GET /api/categories": {summary: "List visible categories in display order", tag: "menu",
+			responses: map[int]any{http.StatusOK: []models.Category{}}, cacheable: true},
+		"GET /api/news": {summary: "List live news, newest first", tag: "news",
+			responses: map[int]any{http.StatusOK: []models.News{}}, cacheable: true},
+		"GET /api/news/{id}": {summary: "Get a live news post", tag: "news",
+			responses: map[int]any{http.StatusOK: models.News{}},
+			errors:    []apierr.Code{apierr.NewsNotFound}, cacheable: true},
+
+		"POST /api/login": {summary: "Sign in with username and password", tag: "auth",
+			request: Credentials{}, responses: map[int]any{http.StatusOK: tokenResponse{}},
+			errors: []apierr.Code{apierr.InvalidCredentials}},
+		"POST /api/auth/refresh": {summary: "Exchange a refresh token for new tokens", tag: "auth",
+			request: refreshRequest{}, responses: map[int]any{http.StatusOK: tokenResponse{}},
+			errors: []apierr.Code{apierr.InvalidRefresh, apierr.RefreshExpired}},
+		"POST /api/logout": {summary: "Revoke the current access token and, if sent, its refresh token", tag: "auth",
+			request: refreshRequest{}, optionalBody: true, responses: map[int]any{http.StatusOK: map[string]string{}}},
+		"PUT /api/admin/password": {summary: "Change the current user's password", tag: "auth",
+			request: passwordChangeRequest{}, responses: map[int]any{http.StatusOK: tokenResponse{}},
+			errors: []apierr.Code{apierr.WrongPassword, apierr.ValidationFailed}},
+		"POST /api/admin/sessions/revoke": {summary: "Sign the current user out everywhere", tag: "auth",
+			responses: map[int]any{http.StatusNoContent: nil}},
+
+		"GET /api/admin/menu": {summary: "List all menu items, hidden categories included", tag: "menu",
+			query: menuQueryParams(), responses: map[int]any{http.StatusOK: menuResponse{}},
+			errors: []apierr.Code{apierr.InvalidQuery}, cacheable: true},
+		"POST /api/admin/menu": {summary: "Create a menu item", tag: "menu",
+			request: models.MenuItem{}, responses: map[int]any{http.StatusCreated: models.MenuItem{}},
+			errors: []apierr.Code{apierr.ValidationFailed}},
+		"PUT /api/admin/menu/{id}": {summary: "Replace a menu item", tag: "menu",
+			request: models.MenuItem{}, responses: map[int]any{http.StatusNoContent: nil},
+			errors: []apierr.Code{apierr.ValidationFailed, apierr.MenuItemNotFound}, ifMatch: true},
+		"PATCH /api/admin/menu/{id}": {summary: "Change some fields of a menu item", tag: "menu",
+			request: models.MenuItem{}, requestType: mergepatch.ContentType,
+			responses: map[int]any{http.StatusOK: models.MenuItem{}},
+			errors:    []apierr.Code{apierr.ValidationFailed, apierr.MenuItemNotFound}, ifMatch: true, ifMatchRequired: true},
+		"DELETE /api/admin/menu/{id}": {summary: "Move a menu item to the trash", tag: "menu",
+			responses: map[int]any{http.StatusNoContent: nil}, errors: []apierr.Code{apierr.MenuItemNotFound}},
+		"PUT /api/admin/menu/{id}/availability": {summary: "Put an item on the stop-list, take it off, hide it or make it seasonal", tag: "menu",
+			request: models.AvailabilityChange{}, responses: map[int]any{http.StatusOK: models.MenuItem{}},
+			errors: []apierr.Code{apierr.ValidationFailed, apierr.MenuItemNotFound}},
+		"GET /api/admin/stop-list": {summary: "List the items sold out today", tag: "menu",
+			responses: map[int]any{http.StatusOK: []models.MenuItem{}}},
+		"GET /api/admin/trash/menu": {summary: "List deleted menu items that can still be restored", tag: "menu",
+			responses: map[int]any{http.StatusOK: []models.MenuItem{}}},
+		"POST /api/admin/trash/menu/{id}/restore": {summary: "Restore a deleted menu item", tag: "menu",
+			responses: map[int]any{http.StatusOK: models.MenuItem{}}, errors: []apierr.Code{apierr.MenuItemNotFound}},
+		"GET /api/admin/menu/{id}/revisions": {summary: "List the earlier versions of a menu item, newest first", tag: "menu",
+			responses: map[int]any{http.StatusOK: []models.MenuRevision{}}, errors: []apierr.Code{apierr.MenuItemNotFound}},
+		"GET /api/admin/menu/{id}/revisions/diff": {summary: "Compare two versions of a menu item field by field", tag: "menu",
+			query: revisionDiffParams(), responses: map[int]any{http.StatusOK: revisionDiff{}},
+			errors: []apierr.Code{apierr.InvalidQuery, apierr.MenuItemNotFound, apierr.RevisionNotFound}},
+		"POST /api/admin/menu/{id}/revisions/{revision}/restore": {summary: "Make an earlier version of a menu item current again", tag: "menu",
+			responses: map[int]any{http.StatusOK: models.MenuItem{}},
+			errors:    []apierr.Code{apierr.MenuItemNotFound, apierr.RevisionNotFound, apierr.ValidationFailed}, ifMatch: true},
+
+		"GET /api/admin/categories": {summary: "List all categories", tag: "menu",
+			responses: map[int]any{http.StatusOK: []models.Category{}}, cacheable: true},
+		"POST /api/admin/categories": {summary: "Create a category", tag: "menu",
+			request: models.Category{}, responses: map[int]any{http.StatusCreated: models.Category{}},
+			errors: []apierr.Code{apierr.ValidationFailed, apierr.CategoryExists}},
+		"PUT /api/admin/categories/{slug}": {summary: "Update a category; omitted fields are kept and the slug cannot change", tag: "menu",
+			request: models.Category{}, responses: map[int]any{http.StatusNoContent: nil},
+			errors: []apierr.Code{apierr.ValidationFailed, apierr.CategoryNotFound}},
+		"DELETE /api/admin/categories/{slug}": {summary: "Delete an empty category", tag: "menu",
+			responses: map[int]any{http.StatusNoContent: nil},
+			errors:    []apierr.Code{apierr.CategoryNotFound, apierr.CategoryInUse}},
+
+		"GET /api/admin/news": {summary: "List all news posts, newest first", tag: "news",
+			query:     []openapi.Parameter{queryParam("status", "Only posts in this status.", &openapi.Schema{Type: "string", Enum: statuses})},
+			responses: map[int]any{http.StatusOK: []models.News{}},
+			errors:    []apierr.Code{apierr.InvalidQuery}, cacheable: true},
+		"POST /api/admin/news": {summary: "Create a news post", tag: "news",
+			request: models.News{}, responses: map[int]any{http.StatusCreated: models.News{}},
+			errors: []apierr.Code{apierr.ValidationFailed}},
+		"PUT /api/admin/news/{id}": {summary: "Replace a news post", tag: "news",
+			request: models.News{}, responses: map[int]any{http.StatusNoContent: nil},
+			errors: []apierr.Code{apierr.ValidationFailed, apierr.NewsNotFound}, ifMatch: true},
+		"PATCH /api/admin/news/{id}": {summary: "Change some fields of a news post", tag: "news",
+			request: models.News{}, requestType: mergepatch.ContentType,
+			responses: map[int]any{http.StatusOK: models.News{}},
+			errors:    []apierr.Code{apierr.ValidationFailed, apierr.NewsNotFound}, ifMatch: true, ifMatchRequired: true},
+		"DELETE /api/admin/news/{id}": {summary: "Move a news post to the trash", tag: "news",
+			responses: map[int]any{http.StatusNoContent: nil}, errors: []apierr.Code{apierr.NewsNotFound}},
+		"GET /api/admin/trash/news": {summary: "List deleted news posts that can still be restored", tag: "news",
+			responses: map[int]any{http.StatusOK: []models.News{}}},
+		"POST /api/admin/trash/news/{id}/restore": {summary: "Restore a deleted news post", tag: "news",
+			responses: map[int]any{http.StatusOK: models.News{}}, errors: []apierr.Code{apierr.NewsNotFound}},
+		"GET /api/admin/news/{id}/revisions": {summary: "List the earlier versions of a news post, newest first", tag: "news",
+			responses: map[int]any{http.StatusOK: []models.NewsRevision{}}, errors: []apierr.Code{apierr.NewsNotFound}},
+		"GET /api/admin/news/{id}/revisions/diff": {summary: "Compare two versions of a news post field by field", tag: "news",
+			query: revisionDiffParams(), responses: map[int]any{http.StatusOK: revisionDiff{}},
+			errors: []apierr.Code{apierr.InvalidQuery, apierr.NewsNotFound, apierr.RevisionNotFound}},
+		"POST /api/admin/news/{id}/revisions/{revision}/restore": {summary: "Make an earlier version of a news post current again, keeping its status", tag: "news",
+			responses: map[int]any{http.StatusOK: models.News{}},
+			errors:    []apierr.Code{apierr.NewsNotFound, apierr.RevisionNotFound, apierr.ValidationFailed}, ifMatch: true},
+
+		"POST /api/admin/uploads": {summary: "Upload an image", tag: "uploads",
+			requestType: multipartType, responses: map[int]any{http.StatusCreated: uploadResponse{}},
+			errors: []apierr.Code{apierr.InvalidMultipart, apierr.MissingFile, apierr.FileTooLarge, apierr.UnsupportedType, apierr.InvalidImage}},
+
+		"GET /api/admin/users": {summary: "List staff accounts", tag: "users",
+			responses: map[int]any{http.StatusOK: []models.User{}}},
+		"POST /api/admin/users": {summary: "Create a staff account", tag: "users",
+			request: userRequest{}, responses: map[int]any{http.StatusCreated: models.User{}},
+			errors: []apierr.Code{apierr.ValidationFailed, apierr.UsernameTaken}},
+		"PUT /api/admin/users/{id}": {summary: "Change a staff account; an empty password keeps the current one", tag: "users",
+			request: userRequest{}, responses: map[int]any{http.StatusNoContent: nil},
+			errors: []apierr.Code{apierr.ValidationFailed, apierr.UserNotFound, apierr.UsernameTaken, apierr.LastOwner}},
+		"DELETE /api/admin/users/{id}": {summary: "Delete a staff account", tag: "users",
+			responses: map[int]any{http.StatusNoContent: nil},
+			errors:    []apierr.Code{apierr.UserNotFound, apierr.CannotDeleteSelf, apierr.LastOwner}},
+		"POST /api/admin/users/{id}/sessions/revoke": {summary: "Sign a staff account out everywhere", tag: "users",
+			responses: map[int]any{http.StatusNoContent: nil}, errors: []apierr.Code{apierr.UserNotFound}},
+		"GET /api/admin/audit": {summary: "List audit log entries, newest first", tag: "users",
+			query: auditQueryParams(), responses: map[int]any{http.StatusOK: []models.AuditEntry{}},
+			errors: []apierr.Code{apierr.InvalidQuery}},
+	}
+}
+
+var openAPITags = []openapi.Tag{
+	{Name: "menu", Description: "Menu items and the categories they are grouped under."},
+	{Name: "news", Description: "News posts. The public sees live posts only."},
+	{Name: "auth", Description: "Sign-in, token refresh and the current user's sessions."},
+	{Name: "uploads", Description: "Images for menu items, categories and news."},
+	{Name: "users", Description: "Staff accounts and the audit log; owners only."},
+	{Name: "system", Description: "Probes, metrics and client settings."},
+}
+
+// OpenAPI describes the routes of Routes as an OpenAPI document. Request
+// and response schemas are derived from the Go types the handlers decode
+// and encode, so the document follows the models as they change.
+func (h *Handler) OpenAPI() (*openapi.Document, error) {
+	g := openapi.NewGenerator()
+	openapi.Enum(g, models.NewsStatuses...)
+	openapi.Enum(g, models.Roles...)
+	openapi.Enum(g, models.MenuSorts...)
+	openapi.Enum(g, models.Availabilities...)
+	openapi.Enum(g, models.AuditActions...)
+	openapi.Enum(g, models.AuditEntities...)
+	codes := make([]apierr.Code, len(apierr.Catalogue))
+	for i, e := range apierr.Catalogue {
+		codes[i] = e.Code
+	}
+	openapi.Enum(g, codes...)
+	for _, name := range []string{"id", "createdAt", "updatedAt", "images", "soldOutAt", "deletedAt"} {
+		g.ReadOnly[name] = true
+	}
+
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Cafe Between API",
+			Version:     "1",
+			Description: "Errors share one body, ErrorResponse; the x-error-codes of each response list the codes it can carry.",
+		},
+		Tags:  openAPITags,
+		Paths: map[string]*openapi.PathItem{},
+		Components: openapi.Components{
+			SecuritySchemes: map[string]openapi.SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "The access token returned by /api/login."},
+			},
+		},
+	}
+	errorSchema := g.Schema(errorResponse{})
+
+	ops := operations()
+	var missing []string
+	for _, route := range h.Routes() {
+		key := route.Method + " " + route.Path
+		op, ok := ops[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		delete(ops, key)
+		item := doc.Paths[route.Path]
+		if item == nil {
+			item = &openapi.PathItem{}
+			doc.Paths[route.Path] = item
+		}
+		(*item)[strings.ToLower(route.Method)] = op.build(g, route, errorSchema)
+	}
+	for key := range ops {
+		missing = append(missing, key+" (documented but not routed)")
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("openapi: undocumented routes: %s", strings.Join(missing, "; "))
+	}
+	doc.Components.Schemas = g.Schemas
+	return doc, nil
+}
+
+func (op operation) build(g *openapi.Generator, route Route, errorSchema *openapi.Schema) *openapi.Operation {
+	out := &openapi.Operation{
+		OperationID: operationID(route.Method, route.Path),
+		Summary:     op.summary,
+		Tags:        []string{op.tag},
+		Parameters:  slices.Clone(op.query),
+		Responses:   map[string]*openapi.Response{},
+	}
+	errors := slices.Clone(op.errors)
+
+	for _, name := range pathParams(route.Path) {
+		schema := &openapi.Schema{Type: "string"}
+		if name == "id" || name == "revision" {
+			schema = &openapi.Schema{Type: "integer"}
+			if !slices.Contains(errors, apierr.InvalidID) {
+				errors = append(errors, apierr.InvalidID)
+			}
+		}
+		out.Parameters = append(out.Parameters, openapi.Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+
+	switch {
+	case op.requestType == multipartType:
+		out.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+			multipartType: {Schema: &openapi.Schema{
+				Type:       "object",
+				Properties: map[string]*openapi.Schema{"file": {Type: "string", Format: "binary"}},
+				Required:   []string{"file"},
+			}},
+		}}
+	case op.requestType == mergepatch.ContentType:
+		// A merge patch is any subset of the resource, with null clearing
+		// a member, so it is described rather than constrained.
+		target := g.Schema(op.request)
+		patch := &openapi.Schema{Type: "object", Description: "JSON Merge Patch (RFC 7396) of " + strings.TrimPrefix(target.Ref, "#/components/schemas/") + "."}
+		out.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+			mergepatch.ContentType: {Schema: patch},
+			jsonType:               {Schema: patch},
+		}}
+		errors = append(errors, apierr.InvalidJSON, apierr.UnsupportedBody)
+	case op.request != nil:
+		out.RequestBody = &openapi.RequestBody{Required: !op.optionalBody, Content: map[string]openapi.MediaType{
+			jsonType: {Schema: g.Schema(op.request)},
+		}}
+		errors = append(errors, apierr.InvalidJSON)
+	}
+
+	if op.ifMatch {
+		out.Parameters = append(out.Parameters, openapi.Parameter{
+			Name: "If-Match", In: "header", Required: false,
+			Description: "ETag of the version being edited. PATCH accepts an updatedAt member instead; one of the two is required.",
+			Schema:      &openapi.Schema{Type: "string"},
+		})
+		errors = append(errors, apierr.EditConflict)
+		if op.ifMatchRequired {
+			errors = append(errors, apierr.PreconditionNeeded)
+		} else {
+			out.Parameters[len(out.Parameters)-1].Description = "ETag of the version being edited; when sent, a stale version is refused."
+		}
+	}
+	if op.cacheable {
+		out.Parameters = append(out.Parameters, openapi.Parameter{
+			Name: "If-None-Match", In: "header", Description: "ETag of a copy the client holds.",
+			Schema: &openapi.Schema{Type: "string"},
+		})
+		out.Responses[strconv.Itoa(http.StatusNotModified)] = &openapi.Response{Description: "The client's copy is current."}
+	}
+
+	if route.Auth {
+		out.Security = []map[string][]string{{"bearerAuth": {}}}
+		errors = append(errors, apierr.MissingToken, apierr.InvalidToken, apierr.TokenRevoked)
+		if len(route.Roles) > 0 {
+			errors = append(errors, apierr.Forbidden)
+			for _, role := range route.Roles {
+				out.Roles = append(out.Roles, string(role))
+			}
+		}
+	}
+	errors = append(errors, apierr.Internal)
+
+	contentType := op.contentType
+	if contentType == "" {
+		contentType = jsonType
+	}
+	for status, body := range op.responses {
+		resp := &openapi.Response{Description: http.StatusText(status)}
+		if body != nil {
+			schema := g.Schema(body)
+			if contentType != jsonType {
+				schema = &openapi.Schema{Type: "string"}
+			}
+			resp.Content = map[string]openapi.MediaType{contentType: {Schema: schema}}
+		}
+		if op.cacheable && status == http.StatusOK {
+			resp.Headers = map[string]openapi.Header{
+				"ETag":          {Schema: &openapi.Schema{Type: "string"}},
+				"Cache-Control": {Schema: &openapi.Schema{Type: "string"}},
+			}
+		}
+		out.Responses[strconv.Itoa(status)] = resp
+	}
+
+	byStatus := map[int][]string{}
+	for _, code := range errors {
+		entry := apierr.Lookup(code)
+		if !slices.Contains(byStatus[entry.Status], string(code)) {
+			byStatus[entry.Status] = append(byStatus[entry.Status], string(code))
+		}
+	}
+	for status, codes := range byStatus {
+		out.Responses[strconv.Itoa(status)] = &openapi.Response{
+			Description: http.StatusText(status),
+			Content:     map[string]openapi.MediaType{jsonType: {Schema: errorSchema}},
+			ErrorCodes:  codes,
+		}
+	}
+	return out
+}
+
+// operationID names an operation after its method and path:
+// GET /api/admin/menu/{id} is getAdminMenuById.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/api"), "/") {
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			segment = "by-" + strings.TrimSuffix(name, "}")
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '.' || r == '_' }) {
+			r := []rune(word)
+			r[0] = unicode.ToUpper(r[0])
+			b.WriteString(string(r))
+		}
+	}
+	return b.String()
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			names = append(names, strings.TrimSuffix(name, "}"))
+		}
+	}
+	return names
+}
+
+// OpenAPIHandler serves the document built by OpenAPI.
+func (h *Handler) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	doc, err := h.OpenAPI()
+	var body []byte
+	if err == nil {
+		body, err = doc.JSON()
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// DocsHandler serves a page that renders the document for people.
+func (h *Handler) DocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(openapi.DocsPage)
+}
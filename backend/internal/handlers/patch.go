@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/mergepatch"
+)
+
+// maxPatchSize bounds PATCH bodies. An item with every field at its limit
+// is far smaller.
+const maxPatchSize = 1 << 20
+
+// etag is the entity tag of a menu item or post. It changes whenever the
+// record is saved, so it doubles as the version clients send in If-Match.
+func etag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixNano(), 36) + `"`
+}
+
+// readMergePatch reads a JSON Merge Patch body. The patch must be an
+// object; its members are returned so callers can look at them directly.
+func readMergePatch(r *http.Request) ([]byte, map[string]json.RawMessage, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != mergepatch.ContentType && mediaType != "application/json" {
+		return nil, nil, apierr.New(apierr.UnsupportedBody)
+	}
+	patch, err := io.ReadAll(io.LimitReader(r.Body, maxPatchSize+1))
+	if err != nil {
+		return nil, nil, apierr.Wrap(apierr.InvalidJSON, err)
+	}
+	if len(patch) > maxPatchSize {
+		return nil, nil, apierr.New(apierr.InvalidJSON)
+	}
+	members, err := mergepatch.Members(patch)
+	if err != nil || members == nil {
+		return nil, nil, apierr.Wrap(apierr.InvalidJSON, err)
+	}
+	return patch, members, nil
+}
+
+// applyMergePatch applies patch to the JSON form of current and decodes the
+// result into dst. Members the patch sets to null come back as zero values.
+func applyMergePatch(current any, patch []byte, dst any) error {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	merged, err := mergepatch.Apply(doc, patch)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(merged, dst); err != nil {
+		return apierr.Wrap(apierr.InvalidJSON, err)
+	}
+	return nil
+}
+
+// checkVersion enforces optimistic concurrency. The client names the
+// version its edit is based on with If-Match or, in a patch, an updatedAt
+// member; an edit based on anything but the stored version is refused.
+// When required is false, a request naming no version is let through.
+func checkVersion(r *http.Request, members map[string]json.RawMessage, updatedAt time.Time, required bool) error {
+	if header := r.Header.Get("If-Match"); header != "" {
+		current := etag(updatedAt)
+		for _, tag := range strings.Split(header, ",") {
+			if tag = strings.TrimSpace(tag); tag == "*" || tag == current {
+				return nil
+			}
+		}
+		return apierr.New(apierr.EditConflict)
+	}
+	if raw, ok := members["updatedAt"]; ok {
+		var version time.Time
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return apierr.Wrap(apierr.InvalidJSON, err)
+		}
+		if !version.Equal(updatedAt) {
+			return apierr.New(apierr.EditConflict)
+		}
+		return nil
+	}
+	if required {
+		return apierr.New(apierr.PreconditionNeeded)
+	}
+	return nil
+}
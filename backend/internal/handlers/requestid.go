@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+
+	"github.com/andrey-918/cafe-between/internal/logging"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// validRequestID bounds what is accepted from clients and proxies, since
+// the ID ends up in logs and response headers.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID tags every request with an ID, reusing a well-formed
+// X-Request-ID from the client or a proxy, and echoes it in the response.
+// Log records written with the request's context carry the ID.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		ctx = logging.With(ctx, slog.String("request_id", id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext returns the ID RequestID assigned to the request.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// requestID returns the request's ID, assigning one when the request did
+// not pass through RequestID, as in handler tests.
+func requestID(w http.ResponseWriter, r *http.Request) string {
+	if id, ok := RequestIDFromContext(r.Context()); ok {
+		return id
+	}
+	id := w.Header().Get(requestIDHeader)
+	if id == "" {
+		id = newRequestID()
+		w.Header().Set(requestIDHeader, id)
+	}
+	return id
+}
+
+func newRequestID() string {
+	var b [12]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/validate"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+)
+
+// revisionDiff compares two versions of an item field by field. To is
+// empty when the later version is the current one.
+type revisionDiff struct {
+	From    int64                         `json:"from"`
+	To      int64                         `json:"to,omitempty"`
+	Changes map[string]models.AuditChange `json:"changes"`
+}
+
+// GetMenuRevisionsHandler lists the earlier versions of the item at {id},
+// newest first.
+func (h *Handler) GetMenuRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.InvalidID))
+		return
+	}
+	_, err = h.Menu.GetByID(r.Context(), id)
+	var revisions []models.MenuRevision
+	if err == nil {
+		revisions, err = h.Menu.ListRevisions(r.Context(), id)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", staffCacheControl)
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// DiffMenuRevisionsHandler compares revision from of the item at {id} with
+// revision to or, without one, with the item as it is now.
+func (h *Handler) DiffMenuRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.InvalidID))
+		return
+	}
+	diff, err := parseRevisionDiff(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	from, err := h.Menu.GetRevision(r.Context(), id, diff.From)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var to models.MenuItem
+	if diff.To != 0 {
+		var rev models.MenuRevision
+		rev, err = h.Menu.GetRevision(r.Context(), id, diff.To)
+		to = rev.Item
+	} else {
+		to, err = h.Menu.GetByID(r.Context(), id)
+	}
+	if err == nil {
+		diff.Changes, err = models.AuditDiff(from.Item, to)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", staffCacheControl)
+	json.NewEncoder(w).Encode(diff)
+}
+
+// RestoreMenuRevisionHandler makes {revision} the current version of the
+// item at {id} and answers with the saved item. The version it replaces
+// becomes a revision in turn, so a restore can itself be undone. The
+// availability is not rolled back: it follows the stop-list, not edits.
+func (h *Handler) RestoreMenuRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, revision, err := revisionVars(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	ctx := r.Context()
+	current, err := h.Menu.GetByID(ctx, id)
+	if err == nil {
+		err = checkVersion(r, nil, current.UpdatedAt, false)
+	}
+	var rev models.MenuRevision
+	if err == nil {
+		rev, err = h.Menu.GetRevision(ctx, id, revision)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	item := rev.Item
+	item.Availability, item.SeasonStart, item.SeasonEnd = current.Availability, current.SeasonStart, current.SeasonEnd
+	// The category may have been deleted since.
+	err = h.validateMenuItem(ctx, item)
+	if err == nil {
+		err = h.Menu.UpdateIfUnmodified(ctx, id, item, current.UpdatedAt)
+	}
+	if err == nil {
+		item, err = h.Menu.GetByID(ctx, id)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	item.Images = h.imageSets(item.ImageURLs)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(item.UpdatedAt))
+	json.NewEncoder(w).Encode(item)
+}
+
+// GetNewsRevisionsHandler lists the earlier versions of the post at {id},
+// newest first.
+func (h *Handler) GetNewsRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.InvalidID))
+		return
+	}
+	_, err = h.News.GetByID(r.Context(), id)
+	var revisions []models.NewsRevision
+	if err == nil {
+		revisions, err = h.News.ListRevisions(r.Context(), id)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", staffCacheControl)
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// DiffNewsRevisionsHandler compares two versions of the post at {id}, like
+// DiffMenuRevisionsHandler.
+func (h *Handler) DiffNewsRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.InvalidID))
+		return
+	}
+	diff, err := parseRevisionDiff(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	from, err := h.News.GetRevision(r.Context(), id, diff.From)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var to models.News
+	if diff.To != 0 {
+		var rev models.NewsRevision
+		rev, err = h.News.GetRevision(r.Context(), id, diff.To)
+		to = rev.Item
+	} else {
+		to, err = h.News.GetByID(r.Context(), id)
+	}
+	if err == nil {
+		diff.Changes, err = models.AuditDiff(from.Item, to)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", staffCacheControl)
+	json.NewEncoder(w).Encode(diff)
+}
+
+// RestoreNewsRevisionHandler makes {revision} the current version of the
+// post at {id}, like RestoreMenuRevisionHandler. Only the content is
+// rolled back: the post keeps its status and posting time, so restoring
+// yesterday's wording does not take a live post down.
+func (h *Handler) RestoreNewsRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, revision, err := revisionVars(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	ctx := r.Context()
+	current, err := h.News.GetByID(ctx, id)
+	if err == nil {
+		err = checkVersion(r, nil, current.UpdatedAt, false)
+	}
+	var rev models.NewsRevision
+	if err == nil {
+		rev, err = h.News.GetRevision(ctx, id, revision)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	item := rev.Item
+	item.Status, item.PostedAt, item.PublishedAt = current.Status, current.PostedAt, current.PublishedAt
+	err = validateNews(item)
+	if err == nil {
+		item.Schedule(time.Now().UTC(), &current)
+		err = h.News.UpdateIfUnmodified(ctx, id, item, current.UpdatedAt)
+	}
+	if err == nil {
+		item, err = h.News.GetByID(ctx, id)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	item.Images = h.imageSets(item.ImageURLs)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(item.UpdatedAt))
+	json.NewEncoder(w).Encode(item)
+}
+
+// revisionVars reads the {id} and {revision} path variables.
+func revisionVars(r *http.Request) (int, int64, error) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return 0, 0, apierr.New(apierr.InvalidID)
+	}
+	revision, err := strconv.ParseInt(vars["revision"], 10, 64)
+	if err != nil {
+		return 0, 0, apierr.New(apierr.InvalidID)
+	}
+	return id, revision, nil
+}
+
+// parseRevisionDiff reads the from and to revision IDs of a diff.
+func parseRevisionDiff(values url.Values) (revisionDiff, error) {
+	var diff revisionDiff
+	var v validate.Validator
+	for _, name := range []string{"from", "to"} {
+		raw := values.Get(name)
+		if raw == "" {
+			v.Check(name != "from", name, validate.CodeRequired, "is required")
+			continue
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 {
+			v.Add(name, validate.CodeInvalid, "must be a revision ID")
+			continue
+		}
+		if name == "from" {
+			diff.From = n
+		} else {
+			diff.To = n
+		}
+	}
+	if err := v.Err(); err != nil {
+		return diff, apierr.Wrap(apierr.InvalidQuery, err).WithDetails(err)
+	}
+	return diff, nil
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+)
+
+// Route is one entry of the API route table. Every route the server exposes
+// is declared here so the authentication requirements can be read, and
+// checked, in one place.
+type Route struct {
+	Method  string
+	Path    string
+	Handler http.HandlerFunc
+	// Auth requires a valid access token. Roles narrows the route down to
+	// the listed roles; owners are always allowed. An empty Roles list
+	// admits any signed-in user.
+	Auth  bool
+	Roles []models.Role
+	// Anonymous marks a mutating route that is deliberately reachable
+	// without a token, such as login. VerifyRoutes rejects any other
+	// unauthenticated POST, PUT, PATCH or DELETE.
+	Anonymous bool
+}
+
+func (h *Handler) Routes() []Route {
+	menuStaff := []models.Role{models.RoleMenuManager, models.RoleBarista}
+	menuManagers := []models.Role{models.RoleMenuManager}
+	editors := []models.Role{models.RoleEditor}
+	owners := []models.Role{models.RoleOwner}
+	uploaders := []models.Role{models.RoleMenuManager, models.RoleEditor}
+
+	return []Route{
+		{Method: http.MethodGet, Path: "/healthz", Handler: h.HealthzHandler},
+		{Method: http.MethodGet, Path: "/readyz", Handler: h.ReadyzHandler},
+		{Method: http.MethodGet, Path: "/metrics", Handler: h.MetricsHandler},
+
+		{Method: http.MethodGet, Path: "/api/openapi.json", Handler: h.OpenAPIHandler},
+		{Method: http.MethodGet, Path: "/api/docs", Handler: h.DocsHandler},
+		{Method: http.MethodGet, Path: "/api/config", Handler: h.GetConfigHandler},
+		{Method: http.MethodGet, Path: "/api/menu", Handler: h.GetMenuHandler},
+		{Method: http.MethodGet, Path: "/api/menu/{id}", Handler: h.GetMenuItemHandler},
+		{Method: http.MethodGet, Path: "/api/categories", Handler: h.GetCategoriesHandler},
+		{Method: http.MethodGet, Path: "/api/news", Handler: h.GetNewsHandler},
+		{Method: http.MethodGet, Path: "/api/news/{id}", Handler: h.GetNewsByIdHandler},
+
+		{Method: http.MethodPost, Path: "/api/login", Handler: h.LoginHandler, Anonymous: true},
+		{Method: http.MethodPost, Path: "/api/auth/refresh", Handler: h.RefreshHandler, Anonymous: true},
+		{Method: http.MethodPost, Path: "/api/logout", Handler: h.LogoutHandler, Auth: true},
+
+		{Method: http.MethodGet, Path: "/api/admin/menu", Handler: h.GetMenuHandler, Auth: true, Roles: menuStaff},
+		{Method: http.MethodPost, Path: "/api/admin/menu", Handler: h.CreateMenuItemHandler, Auth: true, Roles: menuManagers},
+		{Method: http.MethodPut, Path: "/api/admin/menu/{id}", Handler: h.UpdateMenuHandler, Auth: true, Roles: menuManagers},
+		{Method: http.MethodPatch, Path: "/api/admin/menu/{id}", Handler: h.PatchMenuItemHandler, Auth: true, Roles: menuManagers},
+		{Method: http.MethodDelete, Path: "/api/admin/menu/{id}", Handler: h.DelMenuItemHandler, Auth: true, Roles: menuManagers},
+		{Method: http.MethodPut, Path: "/api/admin/menu/{id}/availability", Handler: h.SetAvailabilityHandler, Auth: true, Roles: menuStaff},
+		{Method: http.MethodGet, Path: "/api/admin/stop-list", Handler: h.GetStopListHandler, Auth: true, Roles: menuStaff},
+		{Method: http.MethodGet, Path: "/api/admin/trash/menu", Handler: h.GetMenuTrashHandler, Auth: true, Roles: menuManagers},
+		{Method: http.MethodPost, Path: "/api/admin/trash/menu/{id}/restore", Handler: h.RestoreMenuItemHandler, Auth: true, Roles: menuManagers},
+		{Method: http.MethodGet, Path: "/api/admin/menu/{id}/revisions", Handler: h.GetMenuRevisionsHandler, Auth: true, Roles: menuManagers},
+		{Method: http.MethodGet, Path: "/api/admin/menu/{id}/revisions/diff", Handler: h.DiffMenuRevisionsHandler, Auth: true, Roles: menuManagers},
+		{Method: http.MethodPost, Path: "/api/admin/menu/{id}/revisions/{revision}/restore", Handler: h.RestoreMenuRevisionHandler, Auth: true, Roles: menuManagers},
+
+		{Method: http.MethodGet, Path: "/api/admin/categories", Handler: h.GetCategoriesHandler, Auth: true, Roles: menuStaff},
+		{Method: http.MethodPost, Path: "/api/admin/categories", Handler: h.CreateCategoryHandler, Auth: true, Roles: menuManagers},
+		{Method: http.MethodPut, Path: "/api/admin/categories/{slug}", Handler: h.UpdateCategoryHandler, Auth: true, Roles: menuManagers},
+		{Method: http.MethodDelete, Path: "/api/admin/categories/{slug}", Handler: h.DelCategoryHandler, Auth: true, Roles: menuManagers},
+
+		{Method: http.MethodGet, Path: "/api/admin/news", Handler: h.GetNewsHandler, Auth: true, Roles: editors},
+		{Method: http.MethodPost, Path: "/api/admin/news", Handler: h.CreateNewsHandler, Auth: true, Roles: editors},
+		{Method: http.MethodPut, Path: "/api/admin/news/{id}", Handler: h.UpdateNewsHandler, Auth: true, Roles: editors},
+		{Method: http.MethodPatch, Path: "/api/admin/news/{id}", Handler: h.PatchNewsHandler, Auth: true, Roles: editors},
+		{Method: http.MethodDelete, Path: "/api/admin/news/{id}", Handler: h.DelNewsHandler, Auth: true, Roles: editors},
+		{Method: http.MethodGet, Path: "/api/admin/trash/news", Handler: h.GetNewsTrashHandler, Auth: true, Roles: editors},
+		{Method: http.MethodPost, Path: "/api/admin/trash/news/{id}/restore", Handler: h.RestoreNewsHandler, Auth: true, Roles: editors},
+		{Method: http.MethodGet, Path: "/api/admin/news/{id}/revisions", Handler: h.GetNewsRevisionsHandler, Auth: true, Roles: editors},
+		{Method: http.MethodGet, Path: "/api/admin/news/{id}/revisions/diff", Handler: h.DiffNewsRevisionsHandler, Auth: true, Roles: editors},
+		{Method: http.MethodPost, Path: "/api/admin/news/{id}/revisions/{revision}/restore", Handler: h.RestoreNewsRevisionHandler, Auth: true, Roles: editors},
+
+		{Method: http.MethodPost, Path: "/api/admin/uploads", Handler: h.UploadHandler, Auth: true, Roles: uploaders},
+
+		{Method: http.MethodGet, Path: "/api/admin/users", Handler: h.GetUsersHandler, Auth: true, Roles: owners},
+		{Method: http.MethodPost, Path: "/api/admin/users", Handler: h.CreateUserHandler, Auth: true, Roles: owners},
+		{Method: http.MethodPut, Path: "/api/admin/users/{id}", Handler: h.UpdateUserHandler, Auth: true, Roles: owners},
+		{Method: http.MethodDelete, Path: "/api/admin/users/{id}", Handler: h.DelUserHandler, Auth: true, Roles: owners},
+		{Method: http.MethodPost, Path: "/api/admin/users/{id}/sessions/revoke", Handler: h.RevokeUserSessionsHandler, Auth: true, Roles: owners},
+		{Method: http.MethodGet, Path: "/api/admin/audit", Handler: h.GetAuditLogHandler, Auth: true, Roles: owners},
+		{Method: http.MethodPut, Path: "/api/admin/password", Handler: h.ChangePasswordHandler, Auth: true},
+		{Method: http.MethodPost, Path: "/api/admin/sessions/revoke", Handler: h.RevokeOwnSessionsHandler, Auth: true},
+	}
+}
+
+// protectedHandler and anonymousHandler tag the handlers Register installs
+// so VerifyRoutes can tell, from the router alone, how a route was secured.
+type protectedHandler struct{ http.Handler }
+
+type anonymousHandler struct{ http.Handler }
+
+// Register installs routes on r, wrapping authenticated ones in
+// authenticate and RequireRole. Unknown paths and methods are answered with
+// the JSON error format too.
+func Register(r *mux.Router, routes []Route, authenticate func(http.Handler) http.Handler) error {
+	r.NotFoundHandler = NotFoundHandler()
+	r.MethodNotAllowedHandler = MethodNotAllowedHandler()
+	for _, route := range routes {
+		if isMutating(route.Method) && !route.Auth && !route.Anonymous {
+			return fmt.Errorf("route %s %s mutates state but requires no authentication", route.Method, route.Path)
+		}
+		var handler http.Handler = route.Handler
+		switch {
+		case route.Auth:
+			if len(route.Roles) > 0 {
+				handler = RequireRole(route.Roles...)(handler)
+			}
+			handler = protectedHandler{authenticate(handler)}
+		case route.Anonymous:
+			handler = anonymousHandler{handler}
+		}
+		r.Handle(route.Path, handler).Methods(route.Method, http.MethodOptions)
+	}
+	return nil
+}
+
+// VerifyRoutes walks the finished router and fails if any route accepting
+// POST, PUT, PATCH or DELETE was registered without authentication, for
+// example by calling r.HandleFunc directly instead of going through the
+// route table.
+func VerifyRoutes(r *mux.Router) error {
+	var unprotected []string
+	err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Routes without a method matcher accept every method.
+			methods = []string{http.MethodPost}
+		}
+		mutating := false
+		for _, m := range methods {
+			mutating = mutating || isMutating(m)
+		}
+		if !mutating || route.GetHandler() == nil {
+			return nil
+		}
+		switch route.GetHandler().(type) {
+		case protectedHandler, anonymousHandler:
+			return nil
+		}
+		path, _ := route.GetPathTemplate()
+		unprotected = append(unprotected, strings.Join(methods, ",")+" "+path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(unprotected) > 0 {
+		return fmt.Errorf("mutating routes without authentication: %s", strings.Join(unprotected, "; "))
+	}
+	return nil
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
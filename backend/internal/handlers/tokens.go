@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+type tokenResponse struct {
+	Token        string      `json:"token"`
+	RefreshToken string      `json:"refreshToken"`
+	ExpiresIn    int         `json:"expiresIn"`
+	User         models.User `json:"user"`
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func (h *Handler) generateAccessToken(user models.User, sessionVersion int) (string, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims := Claims{
+		UserID:         user.ID,
+		Username:       user.Username,
+		Role:           user.Role,
+		SessionVersion: sessionVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(h.AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(h.JWTSecret)
+}
+
+// issueTokens creates a fresh access token and a new refresh token for
+// user. It returns the id of the stored refresh token so rotation can link
+// the old token to its replacement.
+func (h *Handler) issueTokens(ctx context.Context, user models.User) (tokenResponse, int, error) {
+	version, err := h.Tokens.SessionVersion(ctx, user.ID)
+	if err != nil {
+		return tokenResponse{}, 0, err
+	}
+	access, err := h.generateAccessToken(user, version)
+	if err != nil {
+		return tokenResponse{}, 0, err
+	}
+	refresh, err := randomToken(32)
+	if err != nil {
+		return tokenResponse{}, 0, err
+	}
+	id, err := h.Tokens.CreateRefreshToken(ctx, models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: models.HashToken(refresh),
+		ExpiresAt: time.Now().UTC().Add(h.RefreshTokenTTL),
+	})
+	if err != nil {
+		return tokenResponse{}, 0, err
+	}
+	return tokenResponse{
+		Token:        access,
+		RefreshToken: refresh,
+		ExpiresIn:    int(h.AccessTokenTTL / time.Second),
+		User:         user,
+	}, id, nil
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/gorilla/mux"
+)
+
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+// GetMenuTrashHandler lists the deleted menu items that can still be
+// restored, most recently deleted first.
+func (h *Handler) GetMenuTrashHandler(w http.ResponseWriter, r *http.Request) {
+	items, err := h.Menu.ListDeleted(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	h.withMenuImages(items)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", staffCacheControl)
+	json.NewEncoder(w).Encode(items)
+}
+
+// RestoreMenuItemHandler takes the item at {id} out of the trash and
+// answers with the restored item.
+func (h *Handler) RestoreMenuItemHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.InvalidID))
+		return
+	}
+	err = h.Menu.Restore(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	item, err := h.Menu.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	item.Images = h.imageSets(item.ImageURLs)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(item.UpdatedAt))
+	json.NewEncoder(w).Encode(item)
+}
+
+// GetNewsTrashHandler lists the deleted news posts that can still be
+// restored, most recently deleted first.
+func (h *Handler) GetNewsTrashHandler(w http.ResponseWriter, r *http.Request) {
+	news, err := h.News.ListDeleted(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	h.withNewsImages(news)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", staffCacheControl)
+	json.NewEncoder(w).Encode(news)
+}
+
+// RestoreNewsHandler takes the post at {id} out of the trash and answers
+// with the restored post. It comes back with the status it had, so a
+// published post goes straight back up.
+func (h *Handler) RestoreNewsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.InvalidID))
+		return
+	}
+	err = h.News.Restore(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	item, err := h.News.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	item.Images = h.imageSets(item.ImageURLs)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(item.UpdatedAt))
+	json.NewEncoder(w).Encode(item)
+}
+
+// PurgeTrash permanently removes the menu items and news posts deleted
+// before the given time, then the uploaded images nothing references any
+// more. An entry's revisions go with it, so the images only they
+// referenced are candidates too; those are collected first, while the
+// revisions still exist. It returns how many entries were purged.
+func (h *Handler) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	revisionURLs := h.purgedRevisionImages(ctx, before)
+	items, menuErr := h.Menu.Purge(ctx, before)
+	news, newsErr := h.News.Purge(ctx, before)
+	for _, item := range items {
+		h.cleanupImages(ctx, item.ImageURLs)
+	}
+	for _, item := range news {
+		h.cleanupImages(ctx, item.ImageURLs)
+	}
+	h.cleanupImages(ctx, revisionURLs)
+	return len(items) + len(news), errors.Join(menuErr, newsErr)
+}
+
+// purgedRevisionImages returns the images referenced by revisions of the
+// entries trashed before the given time. An entry restored before the
+// purge only adds URLs that cleanupImages finds still in use; a lookup
+// that fails leaves its images in storage, which is harmless.
+func (h *Handler) purgedRevisionImages(ctx context.Context, before time.Time) []string {
+	var urls []string
+	items, err := h.Menu.ListDeleted(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to list trashed menu items", "err", err)
+	}
+	for _, item := range items {
+		if !item.DeletedAt.Before(before) {
+			continue
+		}
+		revisions, err := h.Menu.ListRevisions(ctx, item.ID)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to list menu item revisions", "id", item.ID, "err", err)
+		}
+		for _, rev := range revisions {
+			urls = append(urls, rev.Item.ImageURLs...)
+		}
+	}
+	news, err := h.News.ListDeleted(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to list trashed news", "err", err)
+	}
+	for _, item := range news {
+		if !item.DeletedAt.Before(before) {
+			continue
+		}
+		revisions, err := h.News.ListRevisions(ctx, item.ID)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to list news revisions", "id", item.ID, "err", err)
+		}
+		for _, rev := range revisions {
+			urls = append(urls, rev.Item.ImageURLs...)
+		}
+	}
+	slices.Sort(urls)
+	return slices.Compact(urls)
+}
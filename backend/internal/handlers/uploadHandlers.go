@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/imaging"
+	"github.com/andrey-918/cafe-between/internal/storage"
+	"github.com/andrey-918/cafe-between/models"
+)
+
+const defaultMaxUploadSize = 5 << 20
+
+// allowedImageTypes are the sniffed content types accepted for upload.
+// Everything is re-encoded into the variants from imaging.Variants.
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+	"image/gif":  true,
+}
+
+type uploadResponse struct {
+	URL         string          `json:"url"`
+	Key         string          `json:"key"`
+	ContentType string          `json:"contentType"`
+	Size        int64           `json:"size"`
+	Image       models.ImageSet `json:"image"`
+}
+
+// UploadHandler accepts a multipart form with a single "file" field,
+// renders the thumbnail, card and full-size variants in WebP and JPEG and
+// stores them. The returned URL, the full-size JPEG, can be put into a menu
+// item's or news post's imageURLs.
+func (h *Handler) UploadHandler(w http.ResponseWriter, r *http.Request) {
+	// Leave some room for the multipart headers around the file itself.
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxUploadSize+64<<10)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, r, apierr.Wrap(apierr.InvalidMultipart, err))
+		return
+	}
+
+	var data []byte
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			h.uploadReadError(w, r, err)
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+		data, err = io.ReadAll(io.LimitReader(part, h.MaxUploadSize+1))
+		part.Close()
+		if err != nil {
+			h.uploadReadError(w, r, err)
+			return
+		}
+		break
+	}
+	if data == nil {
+		writeError(w, r, apierr.New(apierr.MissingFile))
+		return
+	}
+	if int64(len(data)) > h.MaxUploadSize {
+		writeError(w, r, apierr.New(apierr.FileTooLarge))
+		return
+	}
+
+	// Trust the bytes, not the client's Content-Type.
+	contentType := http.DetectContentType(data)
+	if !allowedImageTypes[contentType] {
+		writeError(w, r, apierr.New(apierr.UnsupportedType))
+		return
+	}
+
+	name, err := randomName()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	renditions, key, err := imaging.Process(name, data)
+	if err != nil {
+		writeError(w, r, apierr.Wrap(apierr.InvalidImage, err))
+		return
+	}
+	for i, rendition := range renditions {
+		err := h.Storage.Put(r.Context(), rendition.Key, bytes.NewReader(rendition.Data), int64(len(rendition.Data)), rendition.ContentType)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to store upload", "key", rendition.Key, "err", err)
+			for _, stored := range renditions[:i] {
+				h.Storage.Delete(r.Context(), stored.Key)
+			}
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	url := h.Storage.URL(key)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(uploadResponse{
+		URL:         url,
+		Key:         key,
+		ContentType: "image/jpeg",
+		Size:        int64(len(data)),
+		Image:       h.imageSet(url),
+	})
+}
+
+func (h *Handler) imageSet(url string) models.ImageSet {
+	return imaging.Responsive(url, h.Storage.KeyFromURL, h.Storage.URL)
+}
+
+func (h *Handler) imageSets(urls []string) []models.ImageSet {
+	sets := make([]models.ImageSet, 0, len(urls))
+	for _, url := range urls {
+		sets = append(sets, h.imageSet(url))
+	}
+	return sets
+}
+
+// withMenuImages fills in the responsive image structure of items before
+// they are encoded.
+func (h *Handler) withMenuImages(items []models.MenuItem) {
+	for i := range items {
+		items[i].Images = h.imageSets(items[i].ImageURLs)
+	}
+}
+
+func (h *Handler) withNewsImages(items []models.News) {
+	for i := range items {
+		items[i].Images = h.imageSets(items[i].ImageURLs)
+	}
+}
+
+func (h *Handler) uploadReadError(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, r, apierr.New(apierr.FileTooLarge))
+		return
+	}
+	writeError(w, r, apierr.Wrap(apierr.InvalidMultipart, err))
+}
+
+// cleanupImages deletes uploaded files that were referenced by a removed
+// menu item or news post and are no longer used anywhere else. Failures are
+// logged: a leftover file is harmless, a failed delete request is not.
+func (h *Handler) cleanupImages(ctx context.Context, urls []string) {
+	for _, url := range urls {
+		key, ok := h.Storage.KeyFromURL(url)
+		if !ok {
+			continue
+		}
+		if h.imageInUse(ctx, url) {
+			continue
+		}
+		for _, k := range imaging.Keys(key) {
+			if err := h.Storage.Delete(ctx, k); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				slog.WarnContext(ctx, "failed to delete orphaned image", "key", k, "err", err)
+			}
+		}
+	}
+}
+
+// imageInUse reports whether anything still references url. Lookup
+// failures count as "in use" so an image is never deleted by mistake.
+func (h *Handler) imageInUse(ctx context.Context, url string) bool {
+	users := []interface {
+		ImageInUse(ctx context.Context, url string) (bool, error)
+	}{h.Menu, h.News, h.Categories}
+	for _, repo := range users {
+		inUse, err := repo.ImageInUse(ctx, url)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to check image", "url", url, "err", err)
+			return true
+		}
+		if inUse {
+			return true
+		}
+	}
+	return false
+}
+
+func randomName() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/validate"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+)
+
+const minPasswordLength = 8
+
+type userRequest struct {
+	Username string      `json:"username"`
+	Password string      `json:"password"`
+	Role     models.Role `json:"role"`
+}
+
+type passwordChangeRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+func (h *Handler) GetUsersHandler(w http.ResponseWriter, r *http.Request) {
+	users, err := h.Users.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+func (h *Handler) CreateUserHandler(w http.ResponseWriter, r *http.Request) {
+	var req userRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, apierr.Wrap(apierr.InvalidJSON, err))
+		return
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	var v validate.Validator
+	v.Required("username", req.Username)
+	v.Check(req.Role.Valid(), "role", validate.CodeInvalid, "unknown role")
+	checkPassword(&v, "password", req.Password)
+	if err := v.Err(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	hash, err := models.HashPassword(req.Password)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := h.Users.Create(r.Context(), models.User{Username: req.Username, PasswordHash: hash, Role: req.Role})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	created, err := h.Users.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// UpdateUserHandler changes a user's username or role and, when a password
+// is supplied, resets it.
+func (h *Handler) UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.InvalidID))
+		return
+	}
+	var req userRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, apierr.Wrap(apierr.InvalidJSON, err))
+		return
+	}
+	var v validate.Validator
+	v.Check(req.Role == "" || req.Role.Valid(), "role", validate.CodeInvalid, "unknown role")
+	if req.Password != "" {
+		checkPassword(&v, "password", req.Password)
+	}
+	if err := v.Err(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	user, err := h.Users.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if username := strings.TrimSpace(req.Username); username != "" {
+		user.Username = username
+	}
+	if req.Role != "" && req.Role != user.Role {
+		if err := h.checkOwnerRemains(r.Context(), user); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		user.Role = req.Role
+	}
+	if err := h.Users.Update(r.Context(), id, user); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if req.Password != "" {
+		if err := h.setPassword(r, id, req.Password); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+	// Outstanding tokens still carry the old role, so force a new login.
+	if err := h.Tokens.RevokeUserSessions(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) DelUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.InvalidID))
+		return
+	}
+	if claims, ok := ClaimsFromContext(r.Context()); ok && claims.UserID == id {
+		writeError(w, r, apierr.New(apierr.CannotDeleteSelf))
+		return
+	}
+	user, err := h.Users.GetByID(r.Context(), id)
+	if err == nil {
+		err = h.checkOwnerRemains(r.Context(), user)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := h.Tokens.RevokeUserSessions(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := h.Users.Delete(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeUserSessionsHandler signs another user out on every device.
+func (h *Handler) RevokeUserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.InvalidID))
+		return
+	}
+	if _, err := h.Users.GetByID(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := h.Tokens.RevokeUserSessions(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ChangePasswordHandler lets the signed-in user change their own password.
+// Every other session of the user is revoked and the response carries a
+// fresh token pair for the current one.
+func (h *Handler) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, r, apierr.New(apierr.MissingToken))
+		return
+	}
+	var req passwordChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, apierr.Wrap(apierr.InvalidJSON, err))
+		return
+	}
+	var v validate.Validator
+	checkPassword(&v, "newPassword", req.NewPassword)
+	if err := v.Err(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	user, err := h.Users.GetByID(r.Context(), claims.UserID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if !user.CheckPassword(req.CurrentPassword) {
+		writeError(w, r, apierr.New(apierr.WrongPassword))
+		return
+	}
+	if err := h.setPassword(r, user.ID, req.NewPassword); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := h.Tokens.RevokeUserSessions(r.Context(), user.ID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	tokens, _, err := h.issueTokens(r.Context(), user)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// checkOwnerRemains refuses to demote or delete user when they are the
+// last owner: nobody else could then manage accounts or read the audit
+// log, and only editing the database would bring an owner back.
+func (h *Handler) checkOwnerRemains(ctx context.Context, user models.User) error {
+	if user.Role != models.RoleOwner {
+		return nil
+	}
+	users, err := h.Users.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if u.ID != user.ID && u.Role == models.RoleOwner {
+			return nil
+		}
+	}
+	return apierr.New(apierr.LastOwner)
+}
+
+func (h *Handler) setPassword(r *http.Request, userID int, password string) error {
+	hash, err := models.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return h.Users.UpdatePassword(r.Context(), userID, hash)
+}
+
+func checkPassword(v *validate.Validator, field, password string) {
+	v.Check(len(password) >= minPasswordLength, field, validate.CodeTooShort, fmt.Sprintf("must be at least %d characters", minPasswordLength))
+}
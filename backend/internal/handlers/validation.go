@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/andrey-918/cafe-between/internal/validate"
+	"github.com/andrey-918/cafe-between/models"
+)
+
+// validateMenuItem applies the menu item rules and checks that its
+// category exists.
+func (h *Handler) validateMenuItem(ctx context.Context, item models.MenuItem) error {
+	var v validate.Validator
+	item.Validate(&v)
+	if item.Category != "" {
+		_, err := h.Categories.GetBySlug(ctx, item.Category)
+		switch {
+		case errors.Is(err, models.ErrCategoryNotFound):
+			v.Add("category", validate.CodeUnknown, "no such category")
+		case err != nil:
+			return err
+		}
+	}
+	return v.Err()
+}
+
+func validateNews(item models.News) error {
+	var v validate.Validator
+	item.Validate(&v)
+	return v.Err()
+}
@@ -0,0 +1,175 @@
+// Package imaging turns an uploaded photo into the resized WebP and JPEG
+// renditions served to the frontend, and maps stored image URLs back to
+// those renditions.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"github.com/andrey-918/cafe-between/models"
+)
+
+const jpegQuality = 82
+
+// Variant is one target size. Images narrower than Width are not upscaled.
+type Variant struct {
+	Name  string
+	Width int
+}
+
+// Variants are generated for every upload, smallest first. "full" is the
+// canonical rendition whose JPEG URL is stored in ImageURLs.
+var Variants = []Variant{
+	{Name: "thumb", Width: 160},
+	{Name: "card", Width: 480},
+	{Name: "full", Width: 1280},
+}
+
+var formats = []struct {
+	name, ext, contentType string
+}{
+	{"webp", ".webp", "image/webp"},
+	{"jpeg", ".jpg", "image/jpeg"},
+}
+
+// Rendition is one encoded file to be written to storage.
+type Rendition struct {
+	Key         string
+	ContentType string
+	Data        []byte
+}
+
+var ErrTooLarge = errors.New("image dimensions too large")
+
+// maxPixels bounds decoding so a tiny file claiming huge dimensions cannot
+// exhaust memory.
+const maxPixels = 50_000_000
+
+// Process decodes data and renders every variant in every format below the
+// directory images/<id>_<width>/, where width is the source width after
+// orientation is applied. It returns the renditions and the key of the
+// canonical full-size JPEG.
+func Process(id string, data []byte) ([]Rendition, string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, "", ErrTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	src = applyOrientation(src, exifOrientation(data))
+
+	srcWidth := src.Bounds().Dx()
+	dir := fmt.Sprintf("images/%s_%d/", id, srcWidth)
+	var renditions []Rendition
+	for _, v := range Variants {
+		img := resize(src, v.Width)
+		for _, f := range formats {
+			var buf bytes.Buffer
+			if f.name == "webp" {
+				err = nativewebp.Encode(&buf, img, nil)
+			} else {
+				err = jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: jpegQuality})
+			}
+			if err != nil {
+				return nil, "", fmt.Errorf("encode %s %s: %w", v.Name, f.name, err)
+			}
+			renditions = append(renditions, Rendition{Key: dir + v.Name + f.ext, ContentType: f.contentType, Data: buf.Bytes()})
+		}
+	}
+	return renditions, dir + "full.jpg", nil
+}
+
+func resize(src image.Image, width int) image.Image {
+	b := src.Bounds()
+	if b.Dx() <= width {
+		return src
+	}
+	height := max(1, b.Dy()*width/b.Dx())
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, b, xdraw.Over, nil)
+	return dst
+}
+
+// flatten draws img onto white, since JPEG has no alpha channel and
+// transparent PNG areas would otherwise turn black.
+func flatten(img image.Image) image.Image {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	return dst
+}
+
+var fullKey = regexp.MustCompile(`^(images/[0-9a-f]+_(\d+)/)full\.jpg$`)
+
+// Keys lists every stored object belonging to the image with key: all
+// renditions for a processed upload, or just key itself otherwise.
+func Keys(key string) []string {
+	m := fullKey.FindStringSubmatch(key)
+	if m == nil {
+		return []string{key}
+	}
+	var keys []string
+	for _, v := range Variants {
+		for _, f := range formats {
+			keys = append(keys, m[1]+v.Name+f.ext)
+		}
+	}
+	return keys
+}
+
+// Responsive builds the srcset structure for an ImageURLs entry. urlFor
+// turns a storage key into a public URL and keyFor does the reverse,
+// reporting false for URLs the backend does not manage.
+func Responsive(url string, keyFor func(string) (string, bool), urlFor func(string) string) models.ImageSet {
+	set := models.ImageSet{Src: url}
+	key, ok := keyFor(url)
+	if !ok {
+		return set
+	}
+	m := fullKey.FindStringSubmatch(key)
+	if m == nil {
+		return set
+	}
+	srcWidth, err := strconv.Atoi(m[2])
+	if err != nil {
+		return set
+	}
+	srcset := map[string][]string{}
+	for _, v := range Variants {
+		width := min(v.Width, srcWidth)
+		variant := models.ImageVariant{
+			Name:  v.Name,
+			Width: width,
+			WebP:  urlFor(m[1] + v.Name + ".webp"),
+			JPEG:  urlFor(m[1] + v.Name + ".jpg"),
+		}
+		set.Variants = append(set.Variants, variant)
+		srcset["webp"] = append(srcset["webp"], fmt.Sprintf("%s %dw", variant.WebP, width))
+		srcset["jpeg"] = append(srcset["jpeg"], fmt.Sprintf("%s %dw", variant.JPEG, width))
+	}
+	set.SrcSet = map[string]string{}
+	for format, entries := range srcset {
+		set.SrcSet[format] = strings.Join(entries, ", ")
+	}
+	return set
+}
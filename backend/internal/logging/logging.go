@@ -0,0 +1,89 @@
+// Package logging sets up the server's structured logger. Log lines are
+// JSON, one per line, and every line written with a request's context
+// carries that request's fields: its ID and, once authenticated, the user.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// New returns a JSON logger writing records at level and above to w.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(NewContextHandler(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})))
+}
+
+// ParseLevel reads one of debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToUpper(s))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", s)
+	}
+	return level, nil
+}
+
+// fields holds the attributes attached to a request. It is shared by
+// pointer, so attributes added deep in the handler chain, such as the user
+// once the token is checked, also reach the access log written outside it.
+type fields struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+type fieldsKey struct{}
+
+// With returns a context whose log records carry attrs. If ctx already has
+// request fields the attributes are added to them, and so are seen by
+// everyone holding that request's context.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	f, ok := ctx.Value(fieldsKey{}).(*fields)
+	if !ok {
+		f = &fields{}
+		ctx = context.WithValue(ctx, fieldsKey{}, f)
+	}
+	f.mu.Lock()
+	f.attrs = append(f.attrs, attrs...)
+	f.mu.Unlock()
+	return ctx
+}
+
+// Attrs returns the attributes With attached to ctx.
+func Attrs(ctx context.Context) []slog.Attr {
+	f, ok := ctx.Value(fieldsKey{}).(*fields)
+	if !ok {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.attrs)
+}
+
+// ContextHandler adds the request fields found in a record's context.
+type ContextHandler struct {
+	slog.Handler
+}
+
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs := Attrs(ctx); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
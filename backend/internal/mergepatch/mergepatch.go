@@ -0,0 +1,57 @@
+// Package mergepatch applies JSON Merge Patch documents (RFC 7396): members
+// of the patch replace those of the target, null removes a member, and
+// nested objects are merged recursively.
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ContentType is the media type of a merge patch request body.
+const ContentType = "application/merge-patch+json"
+
+// ErrInvalidPatch is returned when the patch is not valid JSON.
+var ErrInvalidPatch = errors.New("invalid merge patch")
+
+// Apply returns target with patch applied. target must be valid JSON.
+func Apply(target, patch []byte) ([]byte, error) {
+	var p any
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, errors.Join(ErrInvalidPatch, err)
+	}
+	var t any
+	if err := json.Unmarshal(target, &t); err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(t, p))
+}
+
+func merge(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = merge(t[key], value)
+	}
+	return t
+}
+
+// Members returns the top-level member names of an object patch, so callers
+// can tell which fields a client meant to change.
+func Members(patch []byte) (map[string]json.RawMessage, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil {
+		return nil, errors.Join(ErrInvalidPatch, err)
+	}
+	return members, nil
+}
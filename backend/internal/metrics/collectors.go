@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/readcache"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// scrapeTimeout bounds the queries a scrape runs against the database.
+const scrapeTimeout = 3 * time.Second
+
+// errorLog reports collector failures during a scrape.
+type errorLog struct{}
+
+func (errorLog) Println(v ...any) {
+	slog.Warn("metrics collection failed", "err", fmt.Sprint(v...))
+}
+
+func desc(subsystem, name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, name), help, labels, nil)
+}
+
+// PoolStater is satisfied by *pgxpool.Pool.
+type PoolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+// PoolCollector reports the connection pool's state at scrape time.
+type PoolCollector struct {
+	pool PoolStater
+
+	acquired, idle, constructing, total, max *prometheus.Desc
+	acquires, emptyAcquires, canceled        *prometheus.Desc
+	acquireSeconds, emptyWaitSeconds         *prometheus.Desc
+}
+
+func NewPoolCollector(pool PoolStater) *PoolCollector {
+	return &PoolCollector{
+		pool:             pool,
+		acquired:         desc("db_pool", "acquired_connections", "Connections currently checked out of the pool."),
+		idle:             desc("db_pool", "idle_connections", "Idle connections in the pool."),
+		constructing:     desc("db_pool", "constructing_connections", "Connections being opened."),
+		total:            desc("db_pool", "connections", "Connections in the pool, acquired, idle or being opened."),
+		max:              desc("db_pool", "max_connections", "Largest size the pool may grow to."),
+		acquires:         desc("db_pool", "acquires_total", "Connections acquired from the pool."),
+		emptyAcquires:    desc("db_pool", "empty_acquires_total", "Acquires that had to wait because no connection was idle."),
+		canceled:         desc("db_pool", "canceled_acquires_total", "Acquires abandoned because their context ended."),
+		acquireSeconds:   desc("db_pool", "acquire_seconds_total", "Time spent acquiring connections."),
+		emptyWaitSeconds: desc("db_pool", "empty_acquire_wait_seconds_total", "Time spent waiting for a connection when none was idle."),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	gauge := func(d *prometheus.Desc, v int32) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, float64(v))
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+	gauge(c.acquired, s.AcquiredConns())
+	gauge(c.idle, s.IdleConns())
+	gauge(c.constructing, s.ConstructingConns())
+	gauge(c.total, s.TotalConns())
+	gauge(c.max, s.MaxConns())
+	counter(c.acquires, float64(s.AcquireCount()))
+	counter(c.emptyAcquires, float64(s.EmptyAcquireCount()))
+	counter(c.canceled, float64(s.CanceledAcquireCount()))
+	counter(c.acquireSeconds, s.AcquireDuration().Seconds())
+	counter(c.emptyWaitSeconds, s.EmptyAcquireWaitTime().Seconds())
+}
+
+// CatalogueCollector counts menu items per category and news posts per
+// status at scrape time, so a scheduled post that never went live, or a
+// category left empty, can be alerted on.
+type CatalogueCollector struct {
+	menu models.MenuRepository
+	news models.NewsRepository
+
+	menuItems, newsPosts *prometheus.Desc
+}
+
+func NewCatalogueCollector(menu models.MenuRepository, news models.NewsRepository) *CatalogueCollector {
+	return &CatalogueCollector{
+		menu:      menu,
+		news:      news,
+		menuItems: desc("menu", "items", `Menu items by category; uncategorised items have category="".`, "category"),
+		newsPosts: desc("news", "posts", "News posts by status.", "status"),
+	}
+}
+
+func (c *CatalogueCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *CatalogueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	if counts, err := c.menu.CountByCategory(ctx); err != nil {
+		ch <- prometheus.NewInvalidMetric(c.menuItems, err)
+	} else {
+		for category, n := range counts {
+			ch <- prometheus.MustNewConstMetric(c.menuItems, prometheus.GaugeValue, float64(n), category)
+		}
+	}
+
+	counts, err := c.news.CountByStatus(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.newsPosts, err)
+		return
+	}
+	// Every status is reported, zeros included, so an alert on scheduled
+	// posts sees the series even when there are none.
+	for _, status := range models.NewsStatuses {
+		ch <- prometheus.MustNewConstMetric(c.newsPosts, prometheus.GaugeValue, float64(counts[status]), string(status))
+	}
+}
+
+// CacheStatser is satisfied by the readcache repository wrappers.
+type CacheStatser interface {
+	CacheStats() map[string]readcache.Stats
+}
+
+// CacheCollector reports the hit rate and size of the read caches.
+type CacheCollector struct {
+	caches []CacheStatser
+
+	hits, misses, shared, entries *prometheus.Desc
+}
+
+func NewCacheCollector(caches ...CacheStatser) *CacheCollector {
+	return &CacheCollector{
+		caches:  caches,
+		hits:    desc("read_cache", "hits_total", "Reads answered from the cache.", "cache"),
+		misses:  desc("read_cache", "misses_total", "Reads that went to the database.", "cache"),
+		shared:  desc("read_cache", "shared_total", "Reads that waited for an identical read already in flight.", "cache"),
+		entries: desc("read_cache", "entries", "Entries currently cached.", "cache"),
+	}
+}
+
+func (c *CacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *CacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, cache := range c.caches {
+		for name, s := range cache.CacheStats() {
+			ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits), name)
+			ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses), name)
+			ch <- prometheus.MustNewConstMetric(c.shared, prometheus.CounterValue, float64(s.Shared), name)
+			ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(s.Entries), name)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package openapi
+
+import _ "embed"
+
+// DocsPage is a self-contained HTML page that fetches openapi.json from
+// beside its own URL and renders the operations for people.
+//
+//go:embed docs.html
+var DocsPage []byte
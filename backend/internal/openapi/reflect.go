@@ -0,0 +1,157 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	rawJSONType   = reflect.TypeOf(json.RawMessage{})
+	byteSliceType = reflect.TypeOf([]byte{})
+)
+
+// Generator derives schemas from Go types the way encoding/json encodes
+// them. Named struct types become components, referred to by $ref, so each
+// model is described once.
+type Generator struct {
+	// Schemas collects the components generated so far.
+	Schemas map[string]*Schema
+	// Enums lists the values of named string types, such as a status.
+	Enums map[reflect.Type][]string
+	// ReadOnly names the JSON properties that only appear in responses.
+	ReadOnly map[string]bool
+}
+
+func NewGenerator() *Generator {
+	return &Generator{
+		Schemas:  map[string]*Schema{},
+		Enums:    map[reflect.Type][]string{},
+		ReadOnly: map[string]bool{},
+	}
+}
+
+// Enum registers the values of the string type of example.
+func Enum[T ~string](g *Generator, values ...T) {
+	var zero T
+	names := make([]string, len(values))
+	for i, v := range values {
+		names[i] = string(v)
+	}
+	g.Enums[reflect.TypeOf(zero)] = names
+}
+
+// Schema returns the schema of v's type.
+func (g *Generator) Schema(v any) *Schema {
+	return g.schema(reflect.TypeOf(v))
+}
+
+// ComponentName is the name a struct type is published under: its Go name
+// with the first letter upper-cased, so unexported response types read
+// like the exported ones.
+func ComponentName(t reflect.Type) string {
+	r := []rune(t.Name())
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func (g *Generator) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawJSONType:
+		return &Schema{}
+	case byteSliceType:
+		return &Schema{Type: "string", Format: "byte"}
+	}
+	if values, ok := g.Enums[t]; ok {
+		return &Schema{Type: "string", Enum: values}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		if s.Ref != "" {
+			// OpenAPI 3.0 ignores nullable next to $ref; the property is
+			// left out of required instead.
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := ComponentName(t)
+		if _, ok := g.Schemas[name]; !ok {
+			g.Schemas[name] = &Schema{} // placeholder for recursive types
+			g.Schemas[name] = g.object(t)
+		}
+		return Ref(name)
+	default:
+		// interface{} and anything else: no constraint.
+		return &Schema{}
+	}
+}
+
+// object describes a struct's JSON encoding. Fields without omitempty are
+// always encoded and so required; embedded structs are flattened.
+func (g *Generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *Generator) addFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		prop := g.schema(f.Type)
+		if g.ReadOnly[name] && prop.Ref == "" {
+			prop.ReadOnly = true
+		}
+		s.Properties[name] = prop
+		omitempty := strings.Contains(","+opts+",", ",omitempty,")
+		if !omitempty && f.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate checks a decoded JSON value (as produced by encoding/json into
+// an any) against s, resolving references against the document. The error
+// names the path of the first mismatch.
+func (d *Document) Validate(s *Schema, v any) error {
+	return d.validate(s, v, "$")
+}
+
+// ValidateResponse checks a response against the operation at method and
+// path, the path template as it appears in the document: the status must be
+// documented, and a JSON body must match the status's schema. For errors
+// the API code must be one of the documented x-error-codes.
+func (d *Document) ValidateResponse(method, path string, status int, contentType string, body []byte) error {
+	item, ok := d.Paths[path]
+	if !ok {
+		return fmt.Errorf("%s is not documented", path)
+	}
+	op, ok := (*item)[strings.ToLower(method)]
+	if !ok {
+		return fmt.Errorf("%s %s is not documented", method, path)
+	}
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("%s %s: status %d is not documented", method, path, status)
+	}
+	if len(resp.Content) == 0 {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return fmt.Errorf("%s %s: status %d is documented without a body, got %q", method, path, status, body)
+		}
+		return nil
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	media, ok := resp.Content[strings.TrimSpace(mediaType)]
+	if !ok {
+		return fmt.Errorf("%s %s: status %d is not documented as %q", method, path, status, contentType)
+	}
+	if mediaType != "application/json" {
+		return nil
+	}
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("%s %s: invalid JSON: %w", method, path, err)
+	}
+	if err := d.Validate(media.Schema, v); err != nil {
+		return fmt.Errorf("%s %s %d: %w", method, path, status, err)
+	}
+	if len(resp.ErrorCodes) > 0 {
+		code, _ := lookup(v, "error", "code").(string)
+		if !slices.Contains(resp.ErrorCodes, code) {
+			return fmt.Errorf("%s %s %d: error code %q is not documented, expected one of %v", method, path, status, code, resp.ErrorCodes)
+		}
+	}
+	return nil
+}
+
+func lookup(v any, keys ...string) any {
+	for _, k := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[k]
+	}
+	return v
+}
+
+func (d *Document) resolve(s *Schema) (*Schema, error) {
+	for s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		if !ok {
+			return nil, fmt.Errorf("unsupported reference %q", s.Ref)
+		}
+		target, ok := d.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown schema %q", name)
+		}
+		s = target
+	}
+	return s, nil
+}
+
+func (d *Document) validate(s *Schema, v any, path string) error {
+	s, err := d.resolve(s)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if v == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return fmt.Errorf("%s: null is not allowed", path)
+	}
+	switch s.Type {
+	case "":
+		return nil
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return mismatch(path, s.Type, v)
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		for name, value := range obj {
+			prop, ok := s.Properties[name]
+			if !ok {
+				prop = s.AdditionalProperties
+			}
+			if prop == nil {
+				if s.Properties != nil {
+					return fmt.Errorf("%s: undocumented property %q", path, name)
+				}
+				continue
+			}
+			if err := d.validate(prop, value, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return mismatch(path, s.Type, v)
+		}
+		for i, item := range items {
+			if err := d.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return mismatch(path, s.Type, v)
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			return fmt.Errorf("%s: %q is not one of %v", path, str, s.Enum)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				return fmt.Errorf("%s: %q is not an RFC 3339 date-time", path, str)
+			}
+		}
+	case "integer", "number":
+		n, err := number(v)
+		if err != nil {
+			return mismatch(path, s.Type, v)
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			return fmt.Errorf("%s: %v is not an integer", path, n)
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return fmt.Errorf("%s: %v is below the minimum %v", path, n, *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return fmt.Errorf("%s: %v is above the maximum %v", path, n, *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return mismatch(path, s.Type, v)
+		}
+	default:
+		return fmt.Errorf("%s: unsupported schema type %q", path, s.Type)
+	}
+	return nil
+}
+
+func number(v any) (float64, error) {
+	switch n := v.(type) {
+	case json.Number:
+		return n.Float64()
+	case float64:
+		return n, nil
+	}
+	return 0, fmt.Errorf("not a number")
+}
+
+func mismatch(path, want string, got any) error {
+	return fmt.Errorf("%s: expected %s, got %T", path, want, got)
+}
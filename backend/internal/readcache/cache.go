@@ -0,0 +1,140 @@
+// Package readcache keeps recent repository reads in memory. The
+// repositories it wraps are only written through the wrappers, so every
+// write drops the affected cache at once; the TTL bounds how stale a read
+// can be when another instance writes to the same database.
+package readcache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxEntries bounds each cache. Menu searches make keys from user
+// input, so the key space is not otherwise limited.
+const DefaultMaxEntries = 1000
+
+// Stats counts how reads were served. Shared reads were misses that waited
+// for a load another caller had already started instead of starting their
+// own.
+type Stats struct {
+	Hits    uint64
+	Misses  uint64
+	Shared  uint64
+	Entries int
+}
+
+// Cache maps string keys to values loaded on demand. Concurrent misses for
+// the same key share one load.
+type Cache[V any] struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu sync.Mutex
+	// generation is bumped by Invalidate; a load that started before the
+	// bump may have read old data and is not stored.
+	generation uint64
+	entries    map[string]entry[V]
+	calls      map[string]*call[V]
+
+	hits, misses, shared atomic.Uint64
+}
+
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+type call[V any] struct {
+	done       chan struct{}
+	generation uint64
+	value      V
+	err        error
+}
+
+// New returns a cache whose entries live for ttl.
+func New[V any](ttl time.Duration) *Cache[V] {
+	return &Cache[V]{
+		ttl:        ttl,
+		maxEntries: DefaultMaxEntries,
+		now:        time.Now,
+		entries:    map[string]entry[V]{},
+		calls:      map[string]*call[V]{},
+	}
+}
+
+// Get returns the cached value for key, calling load on a miss. Errors are
+// returned to every caller sharing the load but are not cached. The load
+// runs detached from ctx's cancellation, since other callers may be
+// waiting for it.
+func (c *Cache[V]) Get(ctx context.Context, key string, load func(context.Context) (V, error)) (V, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && c.now().Before(e.expires) {
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return e.value, nil
+	}
+	if cl, ok := c.calls[key]; ok && cl.generation == c.generation {
+		c.mu.Unlock()
+		c.shared.Add(1)
+		select {
+		case <-cl.done:
+			return cl.value, cl.err
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+	cl := &call[V]{done: make(chan struct{}), generation: c.generation}
+	c.calls[key] = cl
+	c.mu.Unlock()
+	c.misses.Add(1)
+
+	cl.value, cl.err = load(context.WithoutCancel(ctx))
+
+	c.mu.Lock()
+	if c.calls[key] == cl {
+		delete(c.calls, key)
+	}
+	if cl.err == nil && cl.generation == c.generation {
+		c.store(key, cl.value)
+	}
+	c.mu.Unlock()
+	close(cl.done)
+	return cl.value, cl.err
+}
+
+// store saves value under key, making room by dropping expired entries.
+// If the cache is still full the value is simply not kept. c.mu is held.
+func (c *Cache[V]) store(key string, value V) {
+	now := c.now()
+	if len(c.entries) >= c.maxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+	c.entries[key] = entry[V]{value: value, expires: now.Add(c.ttl)}
+}
+
+// Invalidate drops every entry, and makes loads already in flight skip
+// storing what they read.
+func (c *Cache[V]) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	clear(c.entries)
+}
+
+func (c *Cache[V]) Stats() Stats {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load(), Shared: c.shared.Load(), Entries: entries}
+}
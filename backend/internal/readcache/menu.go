@@ -0,0 +1,129 @@
+package readcache
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/andrey-918/cafe-between/models"
+)
+
+// MenuRepository caches menu reads in front of another MenuRepository.
+// Any write through it invalidates every cached menu read. The trash is
+// not cached, and purging it changes nothing a cached read holds.
+type MenuRepository struct {
+	models.MenuRepository
+	items *Cache[models.MenuItem]
+	pages *Cache[models.MenuPage]
+	stats *Cache[models.ListStats]
+}
+
+var _ models.MenuRepository = (*MenuRepository)(nil)
+
+func NewMenuRepository(inner models.MenuRepository, ttl time.Duration) *MenuRepository {
+	return &MenuRepository{
+		MenuRepository: inner,
+		items:          New[models.MenuItem](ttl),
+		pages:          New[models.MenuPage](ttl),
+		stats:          New[models.ListStats](ttl),
+	}
+}
+
+func (r *MenuRepository) GetByID(ctx context.Context, id int) (models.MenuItem, error) {
+	item, err := r.items.Get(ctx, strconv.Itoa(id), func(ctx context.Context) (models.MenuItem, error) {
+		return r.MenuRepository.GetByID(ctx, id)
+	})
+	return cloneMenuItem(item), err
+}
+
+func (r *MenuRepository) List(ctx context.Context, q models.MenuQuery) (models.MenuPage, error) {
+	key, err := json.Marshal(q)
+	if err != nil {
+		return r.MenuRepository.List(ctx, q)
+	}
+	page, err := r.pages.Get(ctx, string(key), func(ctx context.Context) (models.MenuPage, error) {
+		return r.MenuRepository.List(ctx, q)
+	})
+	if err != nil {
+		return page, err
+	}
+	items := make([]models.MenuItem, len(page.Items))
+	for i, item := range page.Items {
+		items[i] = cloneMenuItem(item)
+	}
+	page.Items = items
+	return page, nil
+}
+
+func (r *MenuRepository) Stats(ctx context.Context) (models.ListStats, error) {
+	return r.stats.Get(ctx, "", r.MenuRepository.Stats)
+}
+
+func (r *MenuRepository) Create(ctx context.Context, item models.MenuItem) (int, error) {
+	defer r.Invalidate()
+	return r.MenuRepository.Create(ctx, item)
+}
+
+func (r *MenuRepository) Update(ctx context.Context, id int, item models.MenuItem) error {
+	defer r.Invalidate()
+	return r.MenuRepository.Update(ctx, id, item)
+}
+
+func (r *MenuRepository) UpdateIfUnmodified(ctx context.Context, id int, item models.MenuItem, version time.Time) error {
+	defer r.Invalidate()
+	return r.MenuRepository.UpdateIfUnmodified(ctx, id, item, version)
+}
+
+func (r *MenuRepository) SetAvailability(ctx context.Context, id int, change models.AvailabilityChange) error {
+	defer r.Invalidate()
+	return r.MenuRepository.SetAvailability(ctx, id, change)
+}
+
+func (r *MenuRepository) ResetSoldOut(ctx context.Context, before time.Time) (int, error) {
+	reset, err := r.MenuRepository.ResetSoldOut(ctx, before)
+	if reset > 0 {
+		r.Invalidate()
+	}
+	return reset, err
+}
+
+func (r *MenuRepository) Delete(ctx context.Context, id int) error {
+	defer r.Invalidate()
+	return r.MenuRepository.Delete(ctx, id)
+}
+
+func (r *MenuRepository) Restore(ctx context.Context, id int) error {
+	defer r.Invalidate()
+	return r.MenuRepository.Restore(ctx, id)
+}
+
+// Invalidate drops every cached menu read. Writes call it themselves; it
+// is exported for changes made behind the repository's back.
+func (r *MenuRepository) Invalidate() {
+	r.items.Invalidate()
+	r.pages.Invalidate()
+	r.stats.Invalidate()
+}
+
+// CacheStats reports hits and misses per cache, keyed by what it holds.
+func (r *MenuRepository) CacheStats() map[string]Stats {
+	return map[string]Stats{
+		"menu_item":  r.items.Stats(),
+		"menu_page":  r.pages.Stats(),
+		"menu_stats": r.stats.Stats(),
+	}
+}
+
+// cloneMenuItem copies the slices of a cached item so callers can modify
+// what they get back.
+func cloneMenuItem(item models.MenuItem) models.MenuItem {
+	item.ImageURLs = slices.Clone(item.ImageURLs)
+	item.Images = slices.Clone(item.Images)
+	if item.SoldOutAt != nil {
+		soldOutAt := *item.SoldOutAt
+		item.SoldOutAt = &soldOutAt
+	}
+	return item
+}
@@ -0,0 +1,169 @@
+package readcache
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/andrey-918/cafe-between/models"
+)
+
+// NewsRepository caches news reads in front of another NewsRepository.
+//
+// The public listing depends on the time of the request: a scheduled post
+// appears at its PostedAt without any write. So rather than caching
+// ListVisible per timestamp, it caches every post and applies the
+// visibility rule on each read, which keeps scheduled posts exact however
+// long the TTL.
+//
+// The trash is not cached, except for the time of the latest deletion,
+// which VisibleStats needs.
+type NewsRepository struct {
+	models.NewsRepository
+	items   *Cache[models.News]
+	all     *Cache[[]models.News]
+	deleted *Cache[time.Time]
+}
+
+var _ models.NewsRepository = (*NewsRepository)(nil)
+
+func NewNewsRepository(inner models.NewsRepository, ttl time.Duration) *NewsRepository {
+	return &NewsRepository{
+		NewsRepository: inner,
+		items:          New[models.News](ttl),
+		all:            New[[]models.News](ttl),
+		deleted:        New[time.Time](ttl),
+	}
+}
+
+func (r *NewsRepository) GetByID(ctx context.Context, id int) (models.News, error) {
+	item, err := r.items.Get(ctx, strconv.Itoa(id), func(ctx context.Context) (models.News, error) {
+		return r.NewsRepository.GetByID(ctx, id)
+	})
+	return cloneNews(item), err
+}
+
+func (r *NewsRepository) List(ctx context.Context) ([]models.News, error) {
+	return r.list(ctx, func(models.News) bool { return true })
+}
+
+func (r *NewsRepository) ListVisible(ctx context.Context, now time.Time) ([]models.News, error) {
+	return r.list(ctx, func(item models.News) bool { return item.VisibleAt(now) })
+}
+
+// VisibleStats follows the same rule as the repositories: count the
+// visible posts, and take the newest of any post's UpdatedAt, the visible
+// posts' PostedAt and the latest deletion.
+func (r *NewsRepository) VisibleStats(ctx context.Context, now time.Time) (models.ListStats, error) {
+	news, err := r.all.Get(ctx, "", r.NewsRepository.List)
+	if err != nil {
+		return models.ListStats{}, err
+	}
+	deleted, err := r.deleted.Get(ctx, "", r.lastDeleted)
+	if err != nil {
+		return models.ListStats{}, err
+	}
+	stats := models.ListStats{LastModified: deleted}
+	for _, item := range news {
+		if item.VisibleAt(now) {
+			stats.Count++
+			if item.PostedAt.After(stats.LastModified) {
+				stats.LastModified = item.PostedAt
+			}
+		}
+		if item.UpdatedAt.After(stats.LastModified) {
+			stats.LastModified = item.UpdatedAt
+		}
+	}
+	return stats, nil
+}
+
+// lastDeleted returns when the most recently trashed post was deleted, or
+// the zero time when the trash is empty.
+func (r *NewsRepository) lastDeleted(ctx context.Context) (time.Time, error) {
+	trash, err := r.NewsRepository.ListDeleted(ctx)
+	if err != nil || len(trash) == 0 || trash[0].DeletedAt == nil {
+		return time.Time{}, err
+	}
+	return *trash[0].DeletedAt, nil
+}
+
+// list filters the cached posts, which are already newest first.
+func (r *NewsRepository) list(ctx context.Context, keep func(models.News) bool) ([]models.News, error) {
+	news, err := r.all.Get(ctx, "", r.NewsRepository.List)
+	if err != nil {
+		return nil, err
+	}
+	out := []models.News{}
+	for _, item := range news {
+		if keep(item) {
+			out = append(out, cloneNews(item))
+		}
+	}
+	return out, nil
+}
+
+func (r *NewsRepository) Create(ctx context.Context, item models.News) (int, error) {
+	defer r.Invalidate()
+	return r.NewsRepository.Create(ctx, item)
+}
+
+func (r *NewsRepository) Update(ctx context.Context, id int, item models.News) error {
+	defer r.Invalidate()
+	return r.NewsRepository.Update(ctx, id, item)
+}
+
+func (r *NewsRepository) UpdateIfUnmodified(ctx context.Context, id int, item models.News, version time.Time) error {
+	defer r.Invalidate()
+	return r.NewsRepository.UpdateIfUnmodified(ctx, id, item, version)
+}
+
+func (r *NewsRepository) Delete(ctx context.Context, id int) error {
+	defer r.Invalidate()
+	return r.NewsRepository.Delete(ctx, id)
+}
+
+func (r *NewsRepository) Restore(ctx context.Context, id int) error {
+	defer r.Invalidate()
+	return r.NewsRepository.Restore(ctx, id)
+}
+
+func (r *NewsRepository) Purge(ctx context.Context, before time.Time) ([]models.News, error) {
+	defer r.Invalidate()
+	return r.NewsRepository.Purge(ctx, before)
+}
+
+func (r *NewsRepository) PublishDue(ctx context.Context, now time.Time) (int, error) {
+	published, err := r.NewsRepository.PublishDue(ctx, now)
+	if published > 0 {
+		r.Invalidate()
+	}
+	return published, err
+}
+
+// Invalidate drops every cached news read.
+func (r *NewsRepository) Invalidate() {
+	r.items.Invalidate()
+	r.all.Invalidate()
+	r.deleted.Invalidate()
+}
+
+// CacheStats reports hits and misses per cache, keyed by what it holds.
+func (r *NewsRepository) CacheStats() map[string]Stats {
+	return map[string]Stats{
+		"news_item":    r.items.Stats(),
+		"news_list":    r.all.Stats(),
+		"news_deleted": r.deleted.Stats(),
+	}
+}
+
+func cloneNews(item models.News) models.News {
+	item.ImageURLs = slices.Clone(item.ImageURLs)
+	item.Images = slices.Clone(item.Images)
+	if item.PublishedAt != nil {
+		publishedAt := *item.PublishedAt
+		item.PublishedAt = &publishedAt
+	}
+	return item
+}
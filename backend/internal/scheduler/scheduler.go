@@ -0,0 +1,51 @@
+// Package scheduler runs the backend's periodic background jobs, such as
+// publishing scheduled news and purging expired tokens.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/logging"
+)
+
+// Job is a task repeated every Interval. Run should do one pass and return;
+// errors are logged and the job is retried on the next tick.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Run starts every job, running each once straight away and then on its
+// interval, and blocks until ctx is cancelled and all jobs have returned.
+func Run(ctx context.Context, jobs ...Job) {
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			loop(ctx, job)
+		}()
+	}
+	wg.Wait()
+}
+
+func loop(ctx context.Context, job Job) {
+	// Everything the job logs, its queries included, names the job.
+	ctx = logging.With(ctx, slog.String("job", job.Name))
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "job failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
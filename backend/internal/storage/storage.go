@@ -0,0 +1,50 @@
+// Package storage keeps uploaded files such as menu and news photos behind
+// a small interface so the backend can write to local disk in development
+// and to an S3-compatible bucket in production.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+type Storage interface {
+	// Put stores body under key, replacing any existing object.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// URL is the public address the object is served from.
+	URL(key string) string
+	// KeyFromURL is the inverse of URL. It reports false for URLs that do
+	// not point into this storage, such as photos hosted elsewhere.
+	KeyFromURL(url string) (string, bool)
+}
+
+// keyFromURL strips base from url, treating base as a directory.
+func keyFromURL(base, url string) (string, bool) {
+	prefix := strings.TrimRight(base, "/") + "/"
+	if !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	key := strings.TrimPrefix(url, prefix)
+	if !validKey(key) {
+		return "", false
+	}
+	return key, true
+}
+
+// validKey rejects keys that could escape the storage root.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
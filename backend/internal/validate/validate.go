@@ -0,0 +1,118 @@
+// Package validate collects per-field problems with a request payload so
+// they can be reported to the client all at once instead of one by one.
+package validate
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Machine-readable codes the admin UI keys its messages on.
+const (
+	CodeRequired   = "required"
+	CodeTooShort   = "too_short"
+	CodeTooLong    = "too_long"
+	CodeTooMany    = "too_many"
+	CodeTooSmall   = "too_small"
+	CodeTooLarge   = "too_large"
+	CodeInvalidURL = "invalid_url"
+	CodeInvalid    = "invalid"
+	CodeUnknown    = "unknown"
+)
+
+// FieldError is one failed rule. Field uses the JSON name of the input,
+// with an index for list elements, e.g. "imageURLs[2]".
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors is the set of failed rules for a payload. It is returned as an
+// error so callers can pass it through the usual error paths.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Validator accumulates field errors. The zero value is ready to use.
+type Validator struct {
+	errs Errors
+}
+
+// Add records a failed rule.
+func (v *Validator) Add(field, code, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Check records a failed rule when ok is false.
+func (v *Validator) Check(ok bool, field, code, message string) {
+	if !ok {
+		v.Add(field, code, message)
+	}
+}
+
+// Has reports whether field already failed a rule, so later rules that
+// depend on it can be skipped.
+func (v *Validator) Has(field string) bool {
+	for _, fe := range v.errs {
+		if fe.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the collected errors, or nil if every rule passed.
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// Required checks that s is not blank.
+func (v *Validator) Required(field, s string) {
+	v.Check(strings.TrimSpace(s) != "", field, CodeRequired, "must not be empty")
+}
+
+// MaxLength checks that s has at most max characters, counted the way a
+// Postgres VARCHAR(max) column counts them.
+func (v *Validator) MaxLength(field, s string, max int) {
+	v.Check(utf8.RuneCountInString(s) <= max, field, CodeTooLong, fmt.Sprintf("must be at most %d characters", max))
+}
+
+// Range checks that min <= n <= max.
+func (v *Validator) Range(field string, n, min, max int) {
+	v.Check(n >= min, field, CodeTooSmall, fmt.Sprintf("must be at least %d", min))
+	v.Check(n <= max, field, CodeTooLarge, fmt.Sprintf("must be at most %d", max))
+}
+
+// URL checks that s is an absolute http(s) URL.
+func (v *Validator) URL(field, s string) {
+	v.Check(validHTTPURL(s), field, CodeInvalidURL, "must be an absolute http or https URL")
+}
+
+// ImageURLs checks a list of image links: at most max entries, each an
+// absolute http(s) URL.
+func (v *Validator) ImageURLs(field string, urls []string, max int) {
+	v.Check(len(urls) <= max, field, CodeTooMany, fmt.Sprintf("must have at most %d entries", max))
+	for i, u := range urls {
+		v.URL(fmt.Sprintf("%s[%d]", field, i), u)
+	}
+}
+
+func validHTTPURL(s string) bool {
+	if len(s) > 2048 {
+		return false
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/internal/scheduler"
+)
+
+// backgroundJobs lists the periodic jobs the server runs alongside the API.
+func backgroundJobs(h *handlers.Handler) []scheduler.Job {
+	return []scheduler.Job{
+		// Public reads already hide posts until postedAt; this records
+		// when each scheduled post actually went live.
+		{
+			Name:     "publish-news",
+			Interval: time.Minute,
+			Run: func(ctx context.Context) error {
+				published, err := h.News.PublishDue(ctx, time.Now().UTC())
+				if published > 0 {
+					slog.InfoContext(ctx, "published scheduled news posts", "count", published)
+				}
+				return err
+			},
+		},
+		// Items go on the stop-list for the day. Checking often, rather
+		// than once at midnight, puts them back on sale soon after
+		// midnight in the cafe's timezone even if the server was down
+		// then.
+		{
+			Name:     "reset-stop-list",
+			Interval: 5 * time.Minute,
+			Run: func(ctx context.Context) error {
+				reset, err := h.Menu.ResetSoldOut(ctx, h.DayStart(time.Now()))
+				if reset > 0 {
+					slog.InfoContext(ctx, "returned sold-out items to the menu", "count", reset)
+				}
+				return err
+			},
+		},
+		// Deleted entries stay restorable for the retention period; their
+		// images are only removed once the entries are gone for good.
+		{
+			Name:     "purge-trash",
+			Interval: time.Hour,
+			Run: func(ctx context.Context) error {
+				purged, err := h.PurgeTrash(ctx, time.Now().Add(-h.TrashRetention))
+				if purged > 0 {
+					slog.InfoContext(ctx, "purged deleted menu items and news", "count", purged)
+				}
+				return err
+			},
+		},
+		// Expired tokens are rejected anyway; purging them only keeps the
+		// tables small.
+		{
+			Name:     "purge-tokens",
+			Interval: time.Hour,
+			Run: func(ctx context.Context) error {
+				deleted, err := h.Tokens.DeleteExpired(ctx, time.Now().UTC())
+				if deleted > 0 {
+					slog.InfoContext(ctx, "purged expired tokens", "count", deleted)
+				}
+				return err
+			},
+		},
+	}
+}
@@ -1,32 +1,138 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
 
+	"github.com/andrey-918/cafe-between/internal/audit"
+	"github.com/andrey-918/cafe-between/internal/config"
 	"github.com/andrey-918/cafe-between/internal/database"
 	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/internal/logging"
+	"github.com/andrey-918/cafe-between/internal/metrics"
+	"github.com/andrey-918/cafe-between/internal/migrate"
+	"github.com/andrey-918/cafe-between/internal/readcache"
+	"github.com/andrey-918/cafe-between/internal/scheduler"
+	"github.com/andrey-918/cafe-between/internal/storage"
+	"github.com/andrey-918/cafe-between/migrations"
+	"github.com/andrey-918/cafe-between/models"
 
 	"github.com/gorilla/mux"
-	"github.com/joho/godotenv"
 )
 
 func main() {
-	_ = godotenv.Load("../.env")
-	database.Init()
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
 	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+	slog.SetDefault(logging.New(os.Stdout, cfg.Log.Level))
+	slog.Info("configuration loaded", "profile", cfg.Profile, "files", cfg.Files)
+	database.Init(cfg.Database)
+
+	migrator, err := migrate.New(database.Pool, migrations.FS)
+	if err != nil {
+		fatal("failed to load migrations", err)
+	}
+	command := cfg.Args
+	if len(command) > 0 && command[0] == "migrate" {
+		runMigrate(migrator, command[1:])
+		return
+	}
+	if cfg.Database.AutoMigrate {
+		applied, err := migrator.Up(context.Background())
+		for _, m := range applied {
+			slog.Info("applied migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			fatal("migration failed", err)
+		}
+	}
+	if err := migrator.Check(context.Background()); err != nil {
+		fatal("schema check failed", err)
+	}
+
+	store, err := newStorage(cfg.Storage)
+	if err != nil {
+		fatal("failed to set up upload storage", err)
+	}
+	m := metrics.New()
+	m.Register(metrics.NewPoolCollector(database.Pool))
+	auditLog := models.NewPostgresAuditRepository(database.Pool)
+	var menu models.MenuRepository = audit.NewMenuRepository(models.NewPostgresMenuRepository(database.Pool), auditLog)
+	var news models.NewsRepository = audit.NewNewsRepository(models.NewPostgresNewsRepository(database.Pool), auditLog)
+	if ttl := cfg.Cache.ReadTTL; ttl > 0 {
+		cachedMenu := readcache.NewMenuRepository(menu, ttl)
+		cachedNews := readcache.NewNewsRepository(news, ttl)
+		m.Register(metrics.NewCacheCollector(cachedMenu, cachedNews))
+		menu, news = cachedMenu, cachedNews
+	}
+	m.Register(metrics.NewCatalogueCollector(menu, news))
+	h := handlers.New(
+		menu,
+		models.NewPostgresCategoryRepository(database.Pool),
+		news,
+		models.NewPostgresUserRepository(database.Pool),
+		models.NewPostgresTokenRepository(database.Pool),
+		auditLog,
+		store,
+	)
+	h.JWTSecret = cfg.Auth.JWTSecret
+	h.AccessTokenTTL = cfg.Auth.AccessTokenTTL
+	h.RefreshTokenTTL = cfg.Auth.RefreshTokenTTL
+	h.CacheControl = cfg.Cache.Control
+	h.Location = cfg.Cafe.Timezone
+	h.TrashRetention = cfg.Trash.Retention
+	if cfg.Metrics.Token != "" {
+		h.Metrics = m.Handler()
+		h.MetricsToken = cfg.Metrics.Token
+	}
+	if len(command) > 0 && command[0] == "user" {
+		runUser(h.Users, command[1:])
+		return
+	}
+	if err := bootstrapOwner(context.Background(), h.Users, cfg.Bootstrap); err != nil {
+		fatal("failed to create owner account", err)
+	}
+	h.ReadinessChecks = []handlers.ReadinessCheck{
+		{Name: "database", Check: database.Pool.Ping},
+		{Name: "migrations", Check: migrator.Check},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	jobsDone := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx, backgroundJobs(h)...)
+		close(jobsDone)
+	}()
+
 	r := mux.NewRouter()
 
 	// CORS middleware
 	r.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization")
+			origin := r.Header.Get("Origin")
+			if origin == "" || !slices.Contains(cfg.Server.CORSOrigins, origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization,Accept-Language,If-Match,If-None-Match,X-Request-ID")
+			w.Header().Set("Access-Control-Expose-Headers", "ETag,Last-Modified,X-Request-ID")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 			if r.Method == "OPTIONS" {
@@ -38,35 +144,64 @@ func main() {
 		})
 	})
 
-	r.HandleFunc("/api/menu", handlers.GetMenuHandler).Methods("GET", "OPTIONS")
-	r.HandleFunc("/api/menu", handlers.CreateMenuItemHandler).Methods("POST", "OPTIONS")
-	r.HandleFunc("/api/menu/{id}", handlers.GetMenuItemHandler).Methods("GET", "OPTIONS")
-	r.HandleFunc("/api/menu/{id}", handlers.DelMenuItemHandler).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/api/menu/{id}", handlers.UpdateMenuHandler).Methods("PUT", "OPTIONS")
-
-	r.HandleFunc("/api/news", handlers.GetNewsHandler).Methods("GET", "OPTIONS")
-	r.HandleFunc("/api/news", handlers.CreateNewsHandler).Methods("POST", "OPTIONS")
-	r.HandleFunc("/api/news/{id}", handlers.GetNewsByIdHandler).Methods("GET", "OPTIONS")
-	r.HandleFunc("/api/news/{id}", handlers.DelNewsHandler).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/api/news/{id}", handlers.UpdateNewsHandler).Methods("PUT", "OPTIONS")
-
-	r.HandleFunc("/api/login", handlers.LoginHandler).Methods("POST", "OPTIONS")
-	r.HandleFunc("/api/logout", handlers.LogoutHandler).Methods("POST", "OPTIONS")
-
-	adminRouter := r.PathPrefix("/api/admin").Subrouter()
-	adminRouter.Use(handlers.JWTMiddleware)
-	adminRouter.HandleFunc("/menu", handlers.GetMenuHandler).Methods("GET", "OPTIONS")
-	adminRouter.HandleFunc("/menu", handlers.CreateMenuItemHandler).Methods("POST", "OPTIONS")
-	adminRouter.HandleFunc("/menu/{id}", handlers.UpdateMenuHandler).Methods("PUT", "OPTIONS")
-	adminRouter.HandleFunc("/menu/{id}", handlers.DelMenuItemHandler).Methods("DELETE", "OPTIONS")
-
-	adminRouter.HandleFunc("/news", handlers.GetNewsHandler).Methods("GET", "OPTIONS")
-	adminRouter.HandleFunc("/news", handlers.CreateNewsHandler).Methods("POST", "OPTIONS")
-	adminRouter.HandleFunc("/news/{id}", handlers.UpdateNewsHandler).Methods("PUT", "OPTIONS")
-	adminRouter.HandleFunc("/news/{id}", handlers.DelNewsHandler).Methods("DELETE", "OPTIONS")
-
-	log.Printf("Server started at :%s", port)
-	if err := http.ListenAndServe(":"+port, r); err != nil {
-		log.Fatalf("Connection failed: %v", err)
+	if err := handlers.Register(r, h.Routes(), h.JWTMiddleware); err != nil {
+		fatal("invalid route table", err)
+	}
+	if local, ok := store.(*storage.Local); ok {
+		r.PathPrefix(uploadsPath).Handler(http.StripPrefix(uploadsPath, local.Handler())).Methods("GET", "HEAD")
+	}
+	if err := handlers.VerifyRoutes(r); err != nil {
+		fatal("route self-check failed", err)
+	}
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           handlers.RequestID(m.Instrument(r, handlers.AccessLog(r))),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("server started", "addr", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		fatal("server failed", err)
+	case <-ctx.Done():
 	}
+	stop()
+	shutdown(srv, h, cfg.Server, jobsDone)
+}
+
+// shutdown fails the readiness probe, waits out the drain delay, then stops
+// accepting connections and lets in-flight requests finish. The pool is
+// closed last, once neither requests nor background jobs can use it.
+func shutdown(srv *http.Server, h *handlers.Handler, cfg config.Server, jobsDone <-chan struct{}) {
+	slog.Info("shutting down")
+	h.Drain()
+	time.Sleep(cfg.DrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Warn("requests still running were cut off", "timeout", cfg.ShutdownTimeout.String(), "err", err)
+	}
+	select {
+	case <-jobsDone:
+	case <-ctx.Done():
+		slog.Warn("background jobs did not stop in time")
+	}
+	database.Pool.Close()
+	slog.Info("server stopped")
+}
+
+// fatal logs err and exits. It is the structured counterpart of log.Fatal
+// for the steps that follow logger setup.
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/andrey-918/cafe-between/internal/migrate"
+)
+
+// runMigrate implements the "migrate up|down [n]|status" subcommand.
+func runMigrate(migrator *migrate.Migrator, args []string) {
+	ctx := context.Background()
+	if len(args) == 0 {
+		fatal("missing migrate command", errors.New("usage: migrate up | down [n] | status"))
+	}
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			slog.Info("applied migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			fatal("migration failed", err)
+		}
+		if len(applied) == 0 {
+			slog.Info("schema is up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				fatal("invalid migrate command", fmt.Errorf("invalid step count %q", args[1]))
+			}
+			steps = n
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			slog.Info("reverted migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			fatal("migration failed", err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+		if err != nil {
+			fatal("migration status failed", err)
+		}
+	default:
+		fatal("invalid migrate command", fmt.Errorf("unknown migrate command %q", args[0]))
+	}
+}
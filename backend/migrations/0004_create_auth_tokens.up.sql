@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    userId INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tokenHash TEXT NOT NULL UNIQUE,
+    expiresAt TIMESTAMP NOT NULL,
+    revokedAt TIMESTAMP,
+    replacedBy INT REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_idx ON refresh_tokens (userId);
+
+-- Access tokens revoked before their expiry, keyed by JWT ID.
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti TEXT PRIMARY KEY,
+    expiresAt TIMESTAMP NOT NULL
+);
+
+-- Access tokens carry the session version current when they were issued;
+-- bumping the version signs the user out everywhere.
+CREATE TABLE IF NOT EXISTS session_versions (
+    userId INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    version INT NOT NULL DEFAULT 0
+);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS menu_description_trgm_idx;
+DROP INDEX IF EXISTS menu_title_trgm_idx;
+DROP INDEX IF EXISTS menu_calories_idx;
+DROP INDEX IF EXISTS menu_createdAt_id_idx;
+DROP INDEX IF EXISTS menu_title_id_idx;
+DROP INDEX IF EXISTS menu_price_id_idx;
+DROP INDEX IF EXISTS menu_category_id_idx;
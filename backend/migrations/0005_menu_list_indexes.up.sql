@@ -0,0 +1,12 @@
+-- Keyset pagination orders by (sort column, id); each ordering gets a
+-- matching composite index so pages are served from an index scan.
+CREATE INDEX IF NOT EXISTS menu_category_id_idx ON menu (category, id);
+CREATE INDEX IF NOT EXISTS menu_price_id_idx ON menu (price, id);
+CREATE INDEX IF NOT EXISTS menu_title_id_idx ON menu (title, id);
+CREATE INDEX IF NOT EXISTS menu_createdAt_id_idx ON menu (createdAt, id);
+CREATE INDEX IF NOT EXISTS menu_calories_idx ON menu (calories);
+
+-- Trigram indexes back the case-insensitive substring search on q.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS menu_title_trgm_idx ON menu USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS menu_description_trgm_idx ON menu USING GIN (description gin_trgm_ops);
@@ -0,0 +1,2 @@
+ALTER TABLE menu DROP CONSTRAINT IF EXISTS menu_category_fkey;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS categories (
+    slug TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    imageURL TEXT NOT NULL DEFAULT '',
+    visible BOOLEAN NOT NULL DEFAULT TRUE,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The labels and order the menu page used to hard-code.
+INSERT INTO categories (slug, name, position) VALUES
+    ('main_meal', 'Основное меню', 10),
+    ('snacks', 'Закуски', 20),
+    ('breakfast', 'Завтрак', 30),
+    ('desserts', 'Десерты', 40),
+    ('drinks', 'Напитки', 50)
+ON CONFLICT (slug) DO NOTHING;
+
+-- Any other free-text category already in use becomes a category of its
+-- own, sorted after the known ones and named after its slug until renamed.
+UPDATE menu SET category = NULL WHERE category = '';
+INSERT INTO categories (slug, name, position)
+SELECT DISTINCT category, category, 1000 FROM menu WHERE category IS NOT NULL
+ON CONFLICT (slug) DO NOTHING;
+
+ALTER TABLE menu
+    ADD CONSTRAINT menu_category_fkey FOREIGN KEY (category)
+    REFERENCES categories (slug) ON DELETE RESTRICT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS news_status_postedAt_idx;
+ALTER TABLE news DROP COLUMN IF EXISTS publishedAt, DROP COLUMN IF EXISTS status;
@@ -0,0 +1,12 @@
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'published'
+        CHECK (status IN ('draft', 'scheduled', 'published', 'archived')),
+    ADD COLUMN IF NOT EXISTS publishedAt TIMESTAMP;
+
+-- postedAt holds UTC. Posts dated in the future were never meant to be
+-- live yet; everything else is taken to have gone live at postedAt.
+UPDATE news SET
+    status = CASE WHEN postedAt > (NOW() AT TIME ZONE 'UTC') THEN 'scheduled' ELSE 'published' END,
+    publishedAt = CASE WHEN postedAt > (NOW() AT TIME ZONE 'UTC') THEN NULL ELSE postedAt END;
+
+CREATE INDEX IF NOT EXISTS news_status_postedAt_idx ON news (status, postedAt DESC);
@@ -0,0 +1,36 @@
+-- Back to wall-clock timestamps, in the zones the previous code expects.
+SET LOCAL timezone = 'UTC';
+
+ALTER TABLE schema_migrations
+    ALTER COLUMN appliedAt TYPE TIMESTAMP USING appliedAt AT TIME ZONE 'UTC';
+
+ALTER TABLE revoked_tokens
+    ALTER COLUMN expiresAt TYPE TIMESTAMP USING expiresAt AT TIME ZONE 'UTC';
+
+ALTER TABLE refresh_tokens
+    ALTER COLUMN expiresAt TYPE TIMESTAMP USING expiresAt AT TIME ZONE 'UTC',
+    ALTER COLUMN revokedAt TYPE TIMESTAMP USING revokedAt AT TIME ZONE 'UTC',
+    ALTER COLUMN createdAt TYPE TIMESTAMP USING createdAt AT TIME ZONE 'UTC';
+
+ALTER TABLE users
+    ALTER COLUMN createdAt TYPE TIMESTAMP USING createdAt AT TIME ZONE 'UTC',
+    ALTER COLUMN updatedAt TYPE TIMESTAMP USING updatedAt AT TIME ZONE 'UTC';
+
+ALTER TABLE categories
+    ALTER COLUMN updatedAt TYPE TIMESTAMP USING (CASE
+        WHEN updatedAt = createdAt THEN updatedAt AT TIME ZONE 'UTC'
+        ELSE (updatedAt AT TIME ZONE 'UTC') + INTERVAL '3 hours'
+    END),
+    ALTER COLUMN createdAt TYPE TIMESTAMP USING createdAt AT TIME ZONE 'UTC';
+
+ALTER TABLE news ALTER COLUMN postedAt DROP DEFAULT;
+ALTER TABLE news
+    ALTER COLUMN createdAt TYPE TIMESTAMP USING (createdAt AT TIME ZONE 'UTC') + INTERVAL '3 hours',
+    ALTER COLUMN updatedAt TYPE TIMESTAMP USING (updatedAt AT TIME ZONE 'UTC') + INTERVAL '3 hours',
+    ALTER COLUMN postedAt TYPE TIMESTAMP USING postedAt AT TIME ZONE 'UTC',
+    ALTER COLUMN publishedAt TYPE TIMESTAMP USING publishedAt AT TIME ZONE 'UTC';
+ALTER TABLE news ALTER COLUMN postedAt SET DEFAULT '2025-11-16 23:59:59';
+
+ALTER TABLE menu
+    ALTER COLUMN createdAt TYPE TIMESTAMP USING (createdAt AT TIME ZONE 'UTC') + INTERVAL '3 hours',
+    ALTER COLUMN updatedAt TYPE TIMESTAMP USING (updatedAt AT TIME ZONE 'UTC') + INTERVAL '3 hours';
@@ -0,0 +1,45 @@
+-- Store every timestamp as timestamptz. How the old wall-clock values are
+-- read depends on who wrote them:
+--   * menu and news createdAt/updatedAt were written by the application as
+--     Moscow time (UTC+3);
+--   * categories.updatedAt too, once a category had been edited; until then
+--     it equals createdAt, which came from the column default;
+--   * column defaults, news.postedAt, news.publishedAt and the token tables
+--     hold UTC.
+-- Sessions are pinned to UTC below so the conversions do not depend on the
+-- server's timezone setting.
+SET LOCAL timezone = 'UTC';
+
+ALTER TABLE menu
+    ALTER COLUMN createdAt TYPE TIMESTAMPTZ USING (createdAt - INTERVAL '3 hours') AT TIME ZONE 'UTC',
+    ALTER COLUMN updatedAt TYPE TIMESTAMPTZ USING (updatedAt - INTERVAL '3 hours') AT TIME ZONE 'UTC';
+
+ALTER TABLE news ALTER COLUMN postedAt DROP DEFAULT;
+ALTER TABLE news
+    ALTER COLUMN createdAt TYPE TIMESTAMPTZ USING (createdAt - INTERVAL '3 hours') AT TIME ZONE 'UTC',
+    ALTER COLUMN updatedAt TYPE TIMESTAMPTZ USING (updatedAt - INTERVAL '3 hours') AT TIME ZONE 'UTC',
+    ALTER COLUMN postedAt TYPE TIMESTAMPTZ USING postedAt AT TIME ZONE 'UTC',
+    ALTER COLUMN publishedAt TYPE TIMESTAMPTZ USING publishedAt AT TIME ZONE 'UTC';
+ALTER TABLE news ALTER COLUMN postedAt SET DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE categories
+    ALTER COLUMN createdAt TYPE TIMESTAMPTZ USING createdAt AT TIME ZONE 'UTC',
+    ALTER COLUMN updatedAt TYPE TIMESTAMPTZ USING (CASE
+        WHEN updatedAt = createdAt THEN updatedAt
+        ELSE updatedAt - INTERVAL '3 hours'
+    END) AT TIME ZONE 'UTC';
+
+ALTER TABLE users
+    ALTER COLUMN createdAt TYPE TIMESTAMPTZ USING createdAt AT TIME ZONE 'UTC',
+    ALTER COLUMN updatedAt TYPE TIMESTAMPTZ USING updatedAt AT TIME ZONE 'UTC';
+
+ALTER TABLE refresh_tokens
+    ALTER COLUMN expiresAt TYPE TIMESTAMPTZ USING expiresAt AT TIME ZONE 'UTC',
+    ALTER COLUMN revokedAt TYPE TIMESTAMPTZ USING revokedAt AT TIME ZONE 'UTC',
+    ALTER COLUMN createdAt TYPE TIMESTAMPTZ USING createdAt AT TIME ZONE 'UTC';
+
+ALTER TABLE revoked_tokens
+    ALTER COLUMN expiresAt TYPE TIMESTAMPTZ USING expiresAt AT TIME ZONE 'UTC';
+
+ALTER TABLE schema_migrations
+    ALTER COLUMN appliedAt TYPE TIMESTAMPTZ USING appliedAt AT TIME ZONE 'UTC';
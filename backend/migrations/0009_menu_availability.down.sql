@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS menu_sold_out_idx;
+ALTER TABLE menu
+    DROP COLUMN IF EXISTS seasonEnd,
+    DROP COLUMN IF EXISTS seasonStart,
+    DROP COLUMN IF EXISTS soldOutAt,
+    DROP COLUMN IF EXISTS availability;
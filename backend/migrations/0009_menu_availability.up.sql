@@ -0,0 +1,9 @@
+ALTER TABLE menu
+    ADD COLUMN IF NOT EXISTS availability VARCHAR(16) NOT NULL DEFAULT 'available'
+        CHECK (availability IN ('available', 'sold_out', 'hidden', 'seasonal')),
+    ADD COLUMN IF NOT EXISTS soldOutAt TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS seasonStart DATE,
+    ADD COLUMN IF NOT EXISTS seasonEnd DATE;
+
+-- The stop-list and its nightly reset only ever look at sold-out items.
+CREATE INDEX IF NOT EXISTS menu_sold_out_idx ON menu (soldOutAt) WHERE availability = 'sold_out';
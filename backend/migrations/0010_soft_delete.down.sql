@@ -0,0 +1,8 @@
+-- Going back to hard deletes means the trash is emptied for good.
+DELETE FROM menu WHERE deletedAt IS NOT NULL;
+DELETE FROM news WHERE deletedAt IS NOT NULL;
+
+DROP INDEX IF EXISTS news_deletedAt_idx;
+DROP INDEX IF EXISTS menu_deletedAt_idx;
+ALTER TABLE news DROP COLUMN IF EXISTS deletedAt;
+ALTER TABLE menu DROP COLUMN IF EXISTS deletedAt;
@@ -0,0 +1,6 @@
+ALTER TABLE menu ADD COLUMN IF NOT EXISTS deletedAt TIMESTAMPTZ;
+ALTER TABLE news ADD COLUMN IF NOT EXISTS deletedAt TIMESTAMPTZ;
+
+-- The trash listing and the retention purge only ever look at deleted rows.
+CREATE INDEX IF NOT EXISTS menu_deletedAt_idx ON menu (deletedAt) WHERE deletedAt IS NOT NULL;
+CREATE INDEX IF NOT EXISTS news_deletedAt_idx ON news (deletedAt) WHERE deletedAt IS NOT NULL;
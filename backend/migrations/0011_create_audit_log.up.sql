@@ -0,0 +1,17 @@
+-- userId is not a foreign key: entries outlive the accounts they name,
+-- which is why the username is kept alongside it.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    userId INT,
+    username VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(16) NOT NULL,
+    entity VARCHAR(16) NOT NULL,
+    entityId INT,
+    changes JSONB NOT NULL DEFAULT '{}',
+    ip VARCHAR(64) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (entity, entityId, id DESC);
+CREATE INDEX IF NOT EXISTS audit_log_user_idx ON audit_log (userId, id DESC);
+CREATE INDEX IF NOT EXISTS audit_log_at_idx ON audit_log (at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS news_revisions;
+DROP TABLE IF EXISTS menu_revisions;
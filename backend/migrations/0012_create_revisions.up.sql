@@ -0,0 +1,19 @@
+-- Each row is an item as it was before an update replaced it, stored as
+-- its API JSON so the tables need no change when the items gain fields.
+-- Revisions go with their item when the trash is purged.
+CREATE TABLE IF NOT EXISTS menu_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    menuId INT NOT NULL REFERENCES menu(id) ON DELETE CASCADE,
+    item JSONB NOT NULL,
+    createdAt TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS news_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    newsId INT NOT NULL REFERENCES news(id) ON DELETE CASCADE,
+    item JSONB NOT NULL,
+    createdAt TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS menu_revisions_menuId_idx ON menu_revisions (menuId, id DESC);
+CREATE INDEX IF NOT EXISTS news_revisions_newsId_idx ON news_revisions (newsId, id DESC);
@@ -0,0 +1,3 @@
+DELETE FROM session_versions WHERE userId NOT IN (SELECT id FROM users);
+ALTER TABLE session_versions ADD CONSTRAINT session_versions_userid_fkey
+    FOREIGN KEY (userId) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+-- Deleting a user bumps their session version before removing the row.
+-- With the cascade, the bump was deleted with the user and their access
+-- tokens kept working until they expired; the version now outlives the
+-- account. User IDs are never reused, so a leftover row matches no one.
+ALTER TABLE session_versions DROP CONSTRAINT IF EXISTS session_versions_userid_fkey;
@@ -0,0 +1,11 @@
+// Package migrations embeds the versioned SQL schema files so the backend
+// binary can apply them without access to the source tree.
+//
+// Files are named NNNN_description.up.sql and NNNN_description.down.sql,
+// where NNNN is the schema version the pair introduces.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
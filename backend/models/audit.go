@@ -0,0 +1,157 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// AuditAction is what an audit log entry records being done.
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	// AuditLogin and AuditLoginFailed record sign-in attempts. A failed
+	// attempt for an unknown username has no UserID.
+	AuditLogin       AuditAction = "login"
+	AuditLoginFailed AuditAction = "login_failed"
+)
+
+var AuditActions = []AuditAction{AuditCreate, AuditUpdate, AuditDelete, AuditRestore, AuditLogin, AuditLoginFailed}
+
+func (a AuditAction) Valid() bool {
+	for _, action := range AuditActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditEntity is the kind of thing an audit log entry is about.
+type AuditEntity string
+
+const (
+	AuditMenuItem AuditEntity = "menu_item"
+	AuditNews     AuditEntity = "news"
+	// AuditSession entries are sign-ins; their EntityID is the user's.
+	AuditSession AuditEntity = "session"
+)
+
+var AuditEntities = []AuditEntity{AuditMenuItem, AuditNews, AuditSession}
+
+func (e AuditEntity) Valid() bool {
+	for _, entity := range AuditEntities {
+		if e == entity {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditEntry is one line of the audit log: who did what to which entity,
+// from where and when.
+type AuditEntry struct {
+	ID int64     `json:"id"`
+	At time.Time `json:"at"`
+	// UserID is nil when there was no signed-in user, as for background
+	// jobs and failed logins with an unknown username.
+	UserID   *int        `json:"userId,omitempty"`
+	Username string      `json:"username"`
+	Action   AuditAction `json:"action"`
+	Entity   AuditEntity `json:"entity"`
+	EntityID *int        `json:"entityId,omitempty"`
+	// Changes holds the fields that differ between the entity before and
+	// after the action, by their JSON names.
+	Changes map[string]AuditChange `json:"changes,omitempty"`
+	IP      string                 `json:"ip"`
+}
+
+// AuditChange is one field's value before and after an action, as JSON.
+// Before is left out for a field that was added, After for one that was
+// removed.
+type AuditChange struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// auditIgnored are fields every save changes, which would only add noise.
+var auditIgnored = map[string]bool{"updatedAt": true}
+
+// AuditDiff compares the JSON encodings of before and after, either of
+// which may be nil, and returns the fields that differ.
+func AuditDiff(before, after any) (map[string]AuditChange, error) {
+	b, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := map[string]AuditChange{}
+	for name, value := range b {
+		if !auditIgnored[name] && !bytes.Equal(value, a[name]) {
+			changes[name] = AuditChange{Before: value, After: a[name]}
+		}
+	}
+	for name, value := range a {
+		if _, ok := b[name]; !ok && !auditIgnored[name] {
+			changes[name] = AuditChange{After: value}
+		}
+	}
+	return changes, nil
+}
+
+// jsonFields splits the JSON object v encodes to into its members. nil,
+// and nil pointers, have none.
+func jsonFields(v any) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if string(data) == "null" {
+		return fields, nil
+	}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// AuditQuery narrows down the audit log. Zero fields match everything.
+// Entries come newest first; Before continues a listing after the entry
+// with that ID.
+type AuditQuery struct {
+	Entity   AuditEntity
+	EntityID int
+	UserID   int
+	Action   AuditAction
+	// From and To bound At; To is exclusive.
+	From   time.Time
+	To     time.Time
+	Before int64
+	Limit  int
+}
+
+func (q AuditQuery) matches(e AuditEntry) bool {
+	switch {
+	case q.Entity != "" && e.Entity != q.Entity:
+		return false
+	case q.EntityID != 0 && (e.EntityID == nil || *e.EntityID != q.EntityID):
+		return false
+	case q.UserID != 0 && (e.UserID == nil || *e.UserID != q.UserID):
+		return false
+	case q.Action != "" && e.Action != q.Action:
+		return false
+	case !q.From.IsZero() && e.At.Before(q.From):
+		return false
+	case !q.To.IsZero() && !e.At.Before(q.To):
+		return false
+	case q.Before != 0 && e.ID >= q.Before:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryAuditRepository keeps the audit log in a slice for tests.
+type MemoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []AuditEntry
+}
+
+func NewMemoryAuditRepository() *MemoryAuditRepository {
+	return &MemoryAuditRepository{}
+}
+
+func (r *MemoryAuditRepository) Record(ctx context.Context, entry AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry.At.IsZero() {
+		entry.At = time.Now().UTC()
+	}
+	entry.ID = int64(len(r.entries) + 1)
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *MemoryAuditRepository) List(ctx context.Context, q AuditQuery) ([]AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := []AuditEntry{}
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if q.Limit > 0 && len(entries) == q.Limit {
+			break
+		}
+		if q.matches(r.entries[i]) {
+			entries = append(entries, r.entries[i])
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AuditRepository stores the audit log. Entries are only ever added.
+type AuditRepository interface {
+	// Record adds entry to the log. ID and, when zero, At are filled in.
+	Record(ctx context.Context, entry AuditEntry) error
+	// List returns the entries matching q, newest first, at most q.Limit
+	// of them when it is set.
+	List(ctx context.Context, q AuditQuery) ([]AuditEntry, error)
+}
+
+var (
+	_ AuditRepository = (*PostgresAuditRepository)(nil)
+	_ AuditRepository = (*MemoryAuditRepository)(nil)
+)
+
+type PostgresAuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresAuditRepository(pool *pgxpool.Pool) *PostgresAuditRepository {
+	return &PostgresAuditRepository{pool: pool}
+}
+
+func (r *PostgresAuditRepository) Record(ctx context.Context, entry AuditEntry) error {
+	if entry.At.IsZero() {
+		entry.At = time.Now().UTC()
+	}
+	if entry.Changes == nil {
+		entry.Changes = map[string]AuditChange{}
+	}
+	query := `INSERT INTO audit_log (at, userId, username, action, entity, entityId, changes, ip) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.pool.Exec(ctx, query, entry.At, entry.UserID, entry.Username, entry.Action, entry.Entity, entry.EntityID, entry.Changes, entry.IP)
+	return err
+}
+
+func (r *PostgresAuditRepository) List(ctx context.Context, q AuditQuery) ([]AuditEntry, error) {
+	var where []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	if q.Entity != "" {
+		where = append(where, "entity = "+arg(q.Entity))
+	}
+	if q.EntityID != 0 {
+		where = append(where, "entityId = "+arg(q.EntityID))
+	}
+	if q.UserID != 0 {
+		where = append(where, "userId = "+arg(q.UserID))
+	}
+	if q.Action != "" {
+		where = append(where, "action = "+arg(q.Action))
+	}
+	if !q.From.IsZero() {
+		where = append(where, "at >= "+arg(q.From))
+	}
+	if !q.To.IsZero() {
+		where = append(where, "at < "+arg(q.To))
+	}
+	if q.Before != 0 {
+		where = append(where, "id < "+arg(q.Before))
+	}
+	query := `SELECT id, at, userId, username, action, entity, entityId, changes, ip FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if q.Limit > 0 {
+		query += " LIMIT " + arg(q.Limit)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		if err := rows.Scan(&e.ID, &e.At, &e.UserID, &e.Username, &e.Action, &e.Entity, &e.EntityID, &e.Changes, &e.IP); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
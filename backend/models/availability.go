@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Availability is whether, and when, a menu item can be ordered.
+type Availability string
+
+const (
+	// Available items are listed and can be ordered.
+	Available Availability = "available"
+	// SoldOut items are on the stop-list for the rest of the day: still
+	// listed, marked as sold out, and back on sale from the next midnight
+	// in the cafe's timezone.
+	SoldOut Availability = "sold_out"
+	// Hidden items are kept for staff but left out of the public menu.
+	Hidden Availability = "hidden"
+	// Seasonal items are listed from SeasonStart to SeasonEnd inclusive;
+	// either bound may be open.
+	Seasonal Availability = "seasonal"
+)
+
+var Availabilities = []Availability{Available, SoldOut, Hidden, Seasonal}
+
+func (a Availability) Valid() bool {
+	for _, known := range Availabilities {
+		if a == known {
+			return true
+		}
+	}
+	return false
+}
+
+// AvailabilityChange is what the stop-list changes on an item, leaving the
+// rest of it alone. Season bounds are dates, YYYY-MM-DD, and only apply to
+// seasonal items.
+type AvailabilityChange struct {
+	Availability Availability `json:"availability"`
+	SeasonStart  string       `json:"seasonStart,omitempty"`
+	SeasonEnd    string       `json:"seasonEnd,omitempty"`
+}
+
+// ListedOn reports whether the public menu shows the item on day, a date in
+// the cafe's timezone formatted as YYYY-MM-DD. Dates in that form compare
+// correctly as strings.
+func (m MenuItem) ListedOn(day string) bool {
+	switch m.Availability {
+	case Hidden:
+		return false
+	case Seasonal:
+		return (m.SeasonStart == "" || m.SeasonStart <= day) && (m.SeasonEnd == "" || day <= m.SeasonEnd)
+	}
+	return true
+}
+
+func (m *MenuItem) applyAvailability(c AvailabilityChange) {
+	m.Availability, m.SeasonStart, m.SeasonEnd = c.Availability, c.SeasonStart, c.SeasonEnd
+}
+
+// soldOutSince returns when an item saved with availability a went on the
+// stop-list: previous if it already was there, now if it has just been put
+// there, and nil if it is not sold out.
+func soldOutSince(a Availability, previous *time.Time, now time.Time) *time.Time {
+	if a != SoldOut {
+		return nil
+	}
+	if previous != nil {
+		return previous
+	}
+	return &now
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// Category groups menu items. Menu items reference it by Slug; Position
+// decides the order sections appear in on the public menu, and hidden
+// categories are left out of it together with their items.
+type Category struct {
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	Position  int       `json:"position"`
+	ImageURL  string    `json:"imageURL,omitempty"`
+	Visible   bool      `json:"visible"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ValidSlug reports whether s can be used as a category slug: lowercase
+// latin letters, digits, "_" and "-", at most 63 characters.
+func ValidSlug(s string) bool {
+	return slugPattern.MatchString(s)
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryCategoryRepository keeps categories in a map for tests. It has no
+// view of the menu, so Delete never reports ErrCategoryInUse; handlers check
+// for referencing items themselves.
+type MemoryCategoryRepository struct {
+	mu         sync.RWMutex
+	categories map[string]Category
+}
+
+func NewMemoryCategoryRepository() *MemoryCategoryRepository {
+	return &MemoryCategoryRepository{categories: map[string]Category{}}
+}
+
+func (r *MemoryCategoryRepository) Create(ctx context.Context, c Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.categories[c.Slug]; ok {
+		return ErrCategoryExists
+	}
+	now := time.Now().UTC()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	r.categories[c.Slug] = c
+	return nil
+}
+
+func (r *MemoryCategoryRepository) GetBySlug(ctx context.Context, slug string) (Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.categories[slug]
+	if !ok {
+		return Category{}, ErrCategoryNotFound
+	}
+	return c, nil
+}
+
+func (r *MemoryCategoryRepository) List(ctx context.Context) ([]Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	categories := make([]Category, 0, len(r.categories))
+	for _, c := range r.categories {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Position != categories[j].Position {
+			return categories[i].Position < categories[j].Position
+		}
+		return categories[i].Slug < categories[j].Slug
+	})
+	return categories, nil
+}
+
+func (r *MemoryCategoryRepository) Update(ctx context.Context, slug string, c Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.categories[slug]
+	if !ok {
+		return ErrCategoryNotFound
+	}
+	c.Slug = slug
+	c.CreatedAt = current.CreatedAt
+	c.UpdatedAt = time.Now().UTC()
+	r.categories[slug] = c
+	return nil
+}
+
+func (r *MemoryCategoryRepository) Delete(ctx context.Context, slug string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.categories[slug]; !ok {
+		return ErrCategoryNotFound
+	}
+	delete(r.categories, slug)
+	return nil
+}
+
+func (r *MemoryCategoryRepository) ImageInUse(ctx context.Context, url string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.categories {
+		if c.ImageURL == url {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryExists   = errors.New("category already exists")
+	// ErrCategoryInUse is returned when deleting a category that menu items
+	// still reference.
+	ErrCategoryInUse = errors.New("category is in use")
+)
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category Category) error
+	GetBySlug(ctx context.Context, slug string) (Category, error)
+	// List returns every category ordered by position, then slug.
+	List(ctx context.Context) ([]Category, error)
+	// Update replaces the name, position, image and visibility of the
+	// category stored under slug. The slug itself never changes.
+	Update(ctx context.Context, slug string, category Category) error
+	Delete(ctx context.Context, slug string) error
+	// ImageInUse reports whether any category uses the image URL.
+	ImageInUse(ctx context.Context, url string) (bool, error)
+}
+
+var (
+	_ CategoryRepository = (*PostgresCategoryRepository)(nil)
+	_ CategoryRepository = (*MemoryCategoryRepository)(nil)
+)
+
+type PostgresCategoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresCategoryRepository(pool *pgxpool.Pool) *PostgresCategoryRepository {
+	return &PostgresCategoryRepository{pool: pool}
+}
+
+func (r *PostgresCategoryRepository) Create(ctx context.Context, c Category) error {
+	query := `INSERT INTO categories (slug, name, position, imageURL, visible) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.pool.Exec(ctx, query, c.Slug, c.Name, c.Position, c.ImageURL, c.Visible)
+	if isUniqueViolation(err) {
+		return ErrCategoryExists
+	}
+	return err
+}
+
+func (r *PostgresCategoryRepository) GetBySlug(ctx context.Context, slug string) (Category, error) {
+	query := `SELECT slug, name, position, imageURL, visible, createdAt, updatedAt FROM categories WHERE slug = $1`
+	var c Category
+	err := r.pool.QueryRow(ctx, query, slug).Scan(&c.Slug, &c.Name, &c.Position, &c.ImageURL, &c.Visible, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return Category{}, ErrCategoryNotFound
+		}
+		return Category{}, err
+	}
+	return c, nil
+}
+
+func (r *PostgresCategoryRepository) List(ctx context.Context) ([]Category, error) {
+	query := `SELECT slug, name, position, imageURL, visible, createdAt, updatedAt FROM categories ORDER BY position, slug`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	categories := []Category{}
+	for rows.Next() {
+		var c Category
+		if err := rows.Scan(&c.Slug, &c.Name, &c.Position, &c.ImageURL, &c.Visible, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+func (r *PostgresCategoryRepository) Update(ctx context.Context, slug string, c Category) error {
+	query := `UPDATE categories SET name = $1, position = $2, imageURL = $3, visible = $4, updatedAt = NOW() WHERE slug = $5`
+	result, err := r.pool.Exec(ctx, query, c.Name, c.Position, c.ImageURL, c.Visible, slug)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (r *PostgresCategoryRepository) Delete(ctx context.Context, slug string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM categories WHERE slug = $1`, slug)
+	if isForeignKeyViolation(err) {
+		return ErrCategoryInUse
+	}
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (r *PostgresCategoryRepository) ImageInUse(ctx context.Context, url string) (bool, error) {
+	var inUse bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE imageURL = $1)`, url).Scan(&inUse)
+	return inUse, err
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
@@ -0,0 +1,18 @@
+package models
+
+// ImageSet describes the responsive renditions of one entry of ImageURLs.
+// Images that were not uploaded through the backend only have Src.
+type ImageSet struct {
+	Src      string         `json:"src"`
+	Variants []ImageVariant `json:"variants,omitempty"`
+	// SrcSet holds ready-made srcset attribute values keyed by format
+	// ("webp", "jpeg").
+	SrcSet map[string]string `json:"srcset,omitempty"`
+}
+
+type ImageVariant struct {
+	Name  string `json:"name"`
+	Width int    `json:"width"`
+	WebP  string `json:"webp"`
+	JPEG  string `json:"jpeg"`
+}
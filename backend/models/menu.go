@@ -7,9 +7,19 @@ type MenuItem struct {
 	Title 		string 		`json:"title"`
 	Price 		int			`json:"price"`
 	ImageURLs 	[]string 	`json:"imageURLs"`
+	Images		[]ImageSet	`json:"images,omitempty"`
 	Calories 	int 		`json:"calories,omitempty"`
 	Description string		`json:"description,omitempty"`
 	Category 	string		`json:"category"`
 	CreatedAt	time.Time	`json:"createdAt"`
 	UpdatedAt	time.Time	`json:"updatedAt"`
+	Availability	Availability	`json:"availability"`
+	// SoldOutAt is when the item went on the stop-list; it is set by
+	// the repository while the item is sold out.
+	SoldOutAt	*time.Time	`json:"soldOutAt,omitempty"`
+	SeasonStart	string		`json:"seasonStart,omitempty"`
+	SeasonEnd	string		`json:"seasonEnd,omitempty"`
+	// DeletedAt is when the item was moved to the trash; it is only set
+	// on items listed from the trash.
+	DeletedAt	*time.Time	`json:"deletedAt,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,280 @@
+package models
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryMenuRepository keeps menu items in a map. It is meant for tests and
+// local experiments; nothing survives a restart.
+type MemoryMenuRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	items  map[int]MenuItem
+	// trash holds deleted items, with DeletedAt set, apart from items so
+	// that every other method leaves them out without checking.
+	trash map[int]MenuItem
+	// revisions holds each item's replaced versions, oldest first.
+	revisions    map[int][]MenuRevision
+	nextRevision int64
+}
+
+func NewMemoryMenuRepository() *MemoryMenuRepository {
+	return &MemoryMenuRepository{nextID: 1, items: map[int]MenuItem{}, trash: map[int]MenuItem{}, revisions: map[int][]MenuRevision{}, nextRevision: 1}
+}
+
+func (r *MemoryMenuRepository) Create(ctx context.Context, item MenuItem) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now().UTC()
+	item.ID = r.nextID
+	item.ImageURLs = append([]string(nil), item.ImageURLs...)
+	item.CreatedAt = now
+	item.UpdatedAt = now
+	item.SoldOutAt = soldOutSince(item.Availability, nil, now)
+	r.items[item.ID] = item
+	r.nextID++
+	return item.ID, nil
+}
+
+func (r *MemoryMenuRepository) GetByID(ctx context.Context, id int) (MenuItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	item, ok := r.items[id]
+	if !ok {
+		return MenuItem{}, ErrMenuItemNotFound
+	}
+	item.ImageURLs = append([]string(nil), item.ImageURLs...)
+	return item, nil
+}
+
+func (r *MemoryMenuRepository) List(ctx context.Context, q MenuQuery) (MenuPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	menu := make([]MenuItem, 0, len(r.items))
+	for _, item := range r.items {
+		if !q.matches(item) {
+			continue
+		}
+		if q.Cursor != nil && q.compare(item, q.Cursor.item()) <= 0 {
+			continue
+		}
+		item.ImageURLs = append([]string(nil), item.ImageURLs...)
+		menu = append(menu, item)
+	}
+	slices.SortFunc(menu, q.compare)
+	if q.Limit > 0 && len(menu) > q.Limit+1 {
+		menu = menu[:q.Limit+1]
+	}
+	return pageOf(menu, q), nil
+}
+
+func (r *MemoryMenuRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, ok := r.items[id]
+	if !ok {
+		return ErrMenuItemNotFound
+	}
+	deletedAt := time.Now().UTC()
+	item.DeletedAt = &deletedAt
+	r.trash[id] = item
+	delete(r.items, id)
+	return nil
+}
+
+func (r *MemoryMenuRepository) ListDeleted(ctx context.Context) ([]MenuItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return deletedMenuItems(r.trash, func(MenuItem) bool { return true }), nil
+}
+
+func (r *MemoryMenuRepository) Restore(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, ok := r.trash[id]
+	if !ok {
+		return ErrMenuItemNotFound
+	}
+	item.DeletedAt = nil
+	item.UpdatedAt = time.Now().UTC()
+	r.items[id] = item
+	delete(r.trash, id)
+	return nil
+}
+
+func (r *MemoryMenuRepository) Purge(ctx context.Context, before time.Time) ([]MenuItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	purged := deletedMenuItems(r.trash, func(item MenuItem) bool { return item.DeletedAt.Before(before) })
+	for _, item := range purged {
+		delete(r.trash, item.ID)
+		delete(r.revisions, item.ID)
+	}
+	return purged, nil
+}
+
+// deletedMenuItems returns copies of the trashed items keep accepts, most
+// recently deleted first.
+func deletedMenuItems(trash map[int]MenuItem, keep func(MenuItem) bool) []MenuItem {
+	items := []MenuItem{}
+	for _, item := range trash {
+		if keep(item) {
+			item.ImageURLs = append([]string(nil), item.ImageURLs...)
+			items = append(items, item)
+		}
+	}
+	slices.SortFunc(items, func(a, b MenuItem) int {
+		if c := b.DeletedAt.Compare(*a.DeletedAt); c != 0 {
+			return c
+		}
+		return b.ID - a.ID
+	})
+	return items
+}
+
+func (r *MemoryMenuRepository) Update(ctx context.Context, id int, item MenuItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.items[id]
+	if !ok {
+		return ErrMenuItemNotFound
+	}
+	r.saveRevision(current)
+	item.ID = id
+	item.ImageURLs = append([]string(nil), item.ImageURLs...)
+	item.CreatedAt = current.CreatedAt
+	item.UpdatedAt = time.Now().UTC()
+	item.SoldOutAt = soldOutSince(item.Availability, current.SoldOutAt, item.UpdatedAt)
+	r.items[id] = item
+	return nil
+}
+
+func (r *MemoryMenuRepository) UpdateIfUnmodified(ctx context.Context, id int, item MenuItem, version time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.items[id]
+	if !ok {
+		return ErrMenuItemNotFound
+	}
+	if !current.UpdatedAt.Equal(version) {
+		return ErrEditConflict
+	}
+	r.saveRevision(current)
+	item.ID = id
+	item.ImageURLs = append([]string(nil), item.ImageURLs...)
+	item.CreatedAt = current.CreatedAt
+	item.UpdatedAt = time.Now().UTC()
+	item.SoldOutAt = soldOutSince(item.Availability, current.SoldOutAt, item.UpdatedAt)
+	r.items[id] = item
+	return nil
+}
+
+func (r *MemoryMenuRepository) SetAvailability(ctx context.Context, id int, change AvailabilityChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, ok := r.items[id]
+	if !ok {
+		return ErrMenuItemNotFound
+	}
+	item.applyAvailability(change)
+	item.UpdatedAt = time.Now().UTC()
+	item.SoldOutAt = soldOutSince(item.Availability, item.SoldOutAt, item.UpdatedAt)
+	r.items[id] = item
+	return nil
+}
+
+func (r *MemoryMenuRepository) ResetSoldOut(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reset := 0
+	for id, item := range r.items {
+		if item.Availability != SoldOut || item.SoldOutAt == nil || !item.SoldOutAt.Before(before) {
+			continue
+		}
+		item.Availability = Available
+		item.SoldOutAt = nil
+		item.UpdatedAt = time.Now().UTC()
+		r.items[id] = item
+		reset++
+	}
+	return reset, nil
+}
+
+func (r *MemoryMenuRepository) Stats(ctx context.Context) (ListStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var stats ListStats
+	for _, item := range r.items {
+		stats.observe(item.UpdatedAt)
+	}
+	for _, item := range r.trash {
+		if item.DeletedAt.After(stats.LastModified) {
+			stats.LastModified = *item.DeletedAt
+		}
+	}
+	return stats, nil
+}
+
+func (r *MemoryMenuRepository) CountByCategory(ctx context.Context) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	counts := map[string]int{}
+	for _, item := range r.items {
+		counts[item.Category]++
+	}
+	return counts, nil
+}
+
+func (r *MemoryMenuRepository) ImageInUse(ctx context.Context, url string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, items := range []map[int]MenuItem{r.items, r.trash} {
+		for _, item := range items {
+			if slices.Contains(item.ImageURLs, url) {
+				return true, nil
+			}
+		}
+	}
+	for _, revisions := range r.revisions {
+		for _, rev := range revisions {
+			if slices.Contains(rev.Item.ImageURLs, url) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// saveRevision keeps item as a replaced version. The caller holds r.mu.
+func (r *MemoryMenuRepository) saveRevision(item MenuItem) {
+	item.ImageURLs = append([]string(nil), item.ImageURLs...)
+	rev := MenuRevision{ID: r.nextRevision, CreatedAt: time.Now().UTC(), Item: item}
+	r.revisions[item.ID] = append(r.revisions[item.ID], rev)
+	r.nextRevision++
+}
+
+func (r *MemoryMenuRepository) ListRevisions(ctx context.Context, id int) ([]MenuRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	revisions := []MenuRevision{}
+	for _, rev := range slices.Backward(r.revisions[id]) {
+		rev.Item.ImageURLs = append([]string(nil), rev.Item.ImageURLs...)
+		revisions = append(revisions, rev)
+	}
+	return revisions, nil
+}
+
+func (r *MemoryMenuRepository) GetRevision(ctx context.Context, id int, revision int64) (MenuRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, rev := range r.revisions[id] {
+		if rev.ID == revision {
+			rev.Item.ImageURLs = append([]string(nil), rev.Item.ImageURLs...)
+			return rev, nil
+		}
+	}
+	return MenuRevision{}, ErrRevisionNotFound
+}
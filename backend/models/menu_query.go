@@ -0,0 +1,197 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded or
+// was issued for a different sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// MenuSort names an ordering of the menu list. A leading "-" reverses it.
+// Every ordering breaks ties by id so that keyset pagination is stable. The
+// default ordering groups items by category, in MenuQuery.CategoryOrder.
+type MenuSort string
+
+const (
+	MenuSortDefault     MenuSort = ""
+	MenuSortPrice       MenuSort = "price"
+	MenuSortPriceDesc   MenuSort = "-price"
+	MenuSortTitle       MenuSort = "title"
+	MenuSortTitleDesc   MenuSort = "-title"
+	MenuSortCreated     MenuSort = "created"
+	MenuSortCreatedDesc MenuSort = "-created"
+)
+
+var MenuSorts = []MenuSort{MenuSortPrice, MenuSortPriceDesc, MenuSortTitle, MenuSortTitleDesc, MenuSortCreated, MenuSortCreatedDesc}
+
+func (s MenuSort) Valid() bool {
+	if s == MenuSortDefault {
+		return true
+	}
+	for _, known := range MenuSorts {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Desc reports whether the ordering is descending.
+func (s MenuSort) Desc() bool {
+	return strings.HasPrefix(string(s), "-")
+}
+
+// MenuQuery filters, orders and pages a menu listing. Zero values mean "no
+// constraint"; a Limit of zero returns every matching item.
+type MenuQuery struct {
+	Category    string
+	MinPrice    *int
+	MaxPrice    *int
+	MaxCalories *int
+	Search      string
+	// CategoryOrder lists category slugs in display order for the default
+	// sort. Items in categories missing from it come last.
+	CategoryOrder []string
+	// HiddenCategories excludes items in these categories.
+	HiddenCategories []string
+	// Availability keeps only the items in that state.
+	Availability Availability
+	// AvailableOn, a date formatted as YYYY-MM-DD, keeps only the items
+	// the public menu lists on that day; see MenuItem.ListedOn.
+	AvailableOn string
+	Sort        MenuSort
+	Limit       int
+	Cursor      *MenuCursor
+}
+
+// MenuPage is one page of a menu listing. NextCursor is empty on the last
+// page.
+type MenuPage struct {
+	Items      []MenuItem `json:"items"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// MenuCursor marks the last item of a page: its id and the value of the sort
+// key. Only the field matching Sort is set.
+type MenuCursor struct {
+	Sort     MenuSort  `json:"s,omitempty"`
+	ID       int       `json:"id"`
+	Category string    `json:"g,omitempty"`
+	Price    int       `json:"p,omitempty"`
+	Title    string    `json:"t,omitempty"`
+	Created  time.Time `json:"c,omitzero"`
+}
+
+// CursorAfter builds the cursor that continues a listing after item.
+func CursorAfter(item MenuItem, sort MenuSort) *MenuCursor {
+	c := &MenuCursor{Sort: sort, ID: item.ID}
+	switch strings.TrimPrefix(string(sort), "-") {
+	case "":
+		c.Category = item.Category
+	case "price":
+		c.Price = item.Price
+	case "title":
+		c.Title = item.Title
+	case "created":
+		c.Created = item.CreatedAt
+	}
+	return c
+}
+
+// Encode returns the opaque form handed to clients.
+func (c *MenuCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeMenuCursor parses a cursor produced by Encode and checks that it
+// belongs to the requested sort order.
+func DecodeMenuCursor(s string, sort MenuSort) (*MenuCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c MenuCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 || c.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// matches applies the query filters to a single item. It mirrors the WHERE
+// clause built by the Postgres repository.
+func (q MenuQuery) matches(item MenuItem) bool {
+	if q.Category != "" && item.Category != q.Category {
+		return false
+	}
+	if slices.Contains(q.HiddenCategories, item.Category) {
+		return false
+	}
+	if q.Availability != "" && item.Availability != q.Availability {
+		return false
+	}
+	if q.AvailableOn != "" && !item.ListedOn(q.AvailableOn) {
+		return false
+	}
+	if q.MinPrice != nil && item.Price < *q.MinPrice {
+		return false
+	}
+	if q.MaxPrice != nil && item.Price > *q.MaxPrice {
+		return false
+	}
+	if q.MaxCalories != nil && item.Calories > *q.MaxCalories {
+		return false
+	}
+	if q.Search != "" {
+		needle := strings.ToLower(q.Search)
+		if !strings.Contains(strings.ToLower(item.Title), needle) && !strings.Contains(strings.ToLower(item.Description), needle) {
+			return false
+		}
+	}
+	return true
+}
+
+// compare orders a against b by the query's sort key, then by id. The
+// cursor is compared the same way, so it is expressed as an item.
+func (q MenuQuery) compare(a, b MenuItem) int {
+	c := 0
+	switch strings.TrimPrefix(string(q.Sort), "-") {
+	case "":
+		c = q.categoryRank(a.Category) - q.categoryRank(b.Category)
+		if c == 0 {
+			c = strings.Compare(a.Category, b.Category)
+		}
+	case "price":
+		c = a.Price - b.Price
+	case "title":
+		c = strings.Compare(a.Title, b.Title)
+	case "created":
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c == 0 {
+		c = a.ID - b.ID
+	}
+	if q.Sort.Desc() {
+		return -c
+	}
+	return c
+}
+
+// categoryRank is the position of slug in CategoryOrder, or one past the
+// end for categories that are not listed.
+func (q MenuQuery) categoryRank(slug string) int {
+	if i := slices.Index(q.CategoryOrder, slug); i >= 0 {
+		return i
+	}
+	return len(q.CategoryOrder)
+}
+
+func (c *MenuCursor) item() MenuItem {
+	return MenuItem{ID: c.ID, Category: c.Category, Price: c.Price, Title: c.Title, CreatedAt: c.Created}
+}
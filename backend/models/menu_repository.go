@@ -3,27 +3,105 @@ package models
 import (
 	"context"
 	"errors"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/andrey-918/cafe-between/internal/database"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var ErrMenuItemNotFound = errors.New("menu item not found")
 
-func CreateMenuItem(item MenuItem) (int, error) {
-	query := `INSERT INTO menu (title, price, imageURLs, calories, description, category, createdAt, updatedAt) values ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+// ErrEditConflict is returned by conditional updates when the stored row
+// was changed after the version the caller based its edit on.
+var ErrEditConflict = errors.New("modified since it was read")
+
+// MenuRepository is the storage boundary for menu items. Handlers depend on
+// this interface so the Postgres implementation can be swapped for the
+// in-memory one in tests or wrapped by decorators.
+type MenuRepository interface {
+	Create(ctx context.Context, item MenuItem) (int, error)
+	GetByID(ctx context.Context, id int) (MenuItem, error)
+	// List returns the items matching q in q.Sort order, at most q.Limit of
+	// them, continuing after q.Cursor when it is set.
+	List(ctx context.Context, q MenuQuery) (MenuPage, error)
+	// Stats summarises the whole menu, so readers can tell whether a
+	// listing they hold is still current.
+	Stats(ctx context.Context) (ListStats, error)
+	// CountByCategory returns how many items each category holds.
+	// Uncategorised items are counted under "".
+	CountByCategory(ctx context.Context) (map[string]int, error)
+	// Update replaces the item, saving the version it replaces as a
+	// revision.
+	Update(ctx context.Context, id int, item MenuItem) error
+	// UpdateIfUnmodified is Update for an edit based on the version of the
+	// item last updated at version. It fails with ErrEditConflict if the
+	// item has been updated since.
+	UpdateIfUnmodified(ctx context.Context, id int, item MenuItem, version time.Time) error
+	// SetAvailability changes only the availability of the item, for the
+	// stop-list, where baristas toggle items without editing them.
+	SetAvailability(ctx context.Context, id int, change AvailabilityChange) error
+	// ResetSoldOut puts the items that went on the stop-list before the
+	// given time back on sale, and returns how many there were.
+	ResetSoldOut(ctx context.Context, before time.Time) (int, error)
+	// Delete moves the item to the trash. Every other method treats a
+	// trashed item as missing, until Restore takes it back out or Purge
+	// removes it for good.
+	Delete(ctx context.Context, id int) error
+	// ListDeleted returns the trashed items, most recently deleted first.
+	ListDeleted(ctx context.Context) ([]MenuItem, error)
+	Restore(ctx context.Context, id int) error
+	// Purge permanently removes the items trashed before the given time and
+	// returns them, so the caller can clean up what they referenced.
+	Purge(ctx context.Context, before time.Time) ([]MenuItem, error)
+	// ImageInUse reports whether any menu item references the image URL.
+	// Trashed items and revisions count, so restoring one never finds its
+	// images gone.
+	ImageInUse(ctx context.Context, url string) (bool, error)
+	// ListRevisions returns the versions Update and UpdateIfUnmodified
+	// replaced, newest first. SetAvailability and the other state changes
+	// leave no revision.
+	ListRevisions(ctx context.Context, id int) ([]MenuRevision, error)
+	// GetRevision returns a revision of the item, or ErrRevisionNotFound
+	// if the item has none with that ID.
+	GetRevision(ctx context.Context, id int, revision int64) (MenuRevision, error)
+}
+
+var (
+	_ MenuRepository = (*PostgresMenuRepository)(nil)
+	_ MenuRepository = (*MemoryMenuRepository)(nil)
+)
+
+type PostgresMenuRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresMenuRepository(pool *pgxpool.Pool) *PostgresMenuRepository {
+	return &PostgresMenuRepository{pool: pool}
+}
+
+func (r *PostgresMenuRepository) Create(ctx context.Context, item MenuItem) (int, error) {
+	query := `INSERT INTO menu (title, price, imageURLs, calories, description, category, createdAt, updatedAt, availability, soldOutAt, seasonStart, seasonEnd)
+		values ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10, NULLIF($11, '')::date, NULLIF($12, '')::date) RETURNING id`
 	var id int
-	now := time.Now().UTC().Add(3 * time.Hour) // UTC+3 for Moscow
-	err := database.Pool.QueryRow(context.Background(), query, item.Title, item.Price, item.ImageURLs, item.Category, item.Calories, item.Description, now, now).Scan(&id)
+	now := time.Now().UTC()
+	err := r.pool.QueryRow(ctx, query, item.Title, item.Price, item.ImageURLs, item.Calories, item.Description, item.Category, now, now,
+		item.Availability, soldOutSince(item.Availability, nil, now), item.SeasonStart, item.SeasonEnd).Scan(&id)
 	return id, err
 }
 
-func GetMenuItemByID(id int) (MenuItem, error) {
-	query := `SELECT id, title, price, imageURLs, calories, description, category, createdAt, updatedAt FROM menu WHERE id = $1`
+func (r *PostgresMenuRepository) GetByID(ctx context.Context, id int) (MenuItem, error) {
+	query := `SELECT ` + menuColumns + ` FROM menu WHERE id = $1 AND deletedAt IS NULL`
+	return scanMenuItem(r.pool.QueryRow(ctx, query, id))
+}
+
+func scanMenuItem(row pgx.Row) (MenuItem, error) {
 	var item MenuItem
-	err := database.Pool.QueryRow(context.Background(), query, id).Scan(
+	err := row.Scan(
 		&item.ID, &item.Title, &item.Price, &item.ImageURLs, &item.Calories, &item.Description, &item.Category, &item.CreatedAt, &item.UpdatedAt,
+		&item.Availability, &item.SoldOutAt, &item.SeasonStart, &item.SeasonEnd,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -34,28 +112,169 @@ func GetMenuItemByID(id int) (MenuItem, error) {
 	return item, nil
 }
 
-func GetMenu() ([]MenuItem, error) {
-	query := `SELECT id, title, price, imageURLs, calories, description, category, createdAt, updatedAt FROM menu`
-	rows, err := database.Pool.Query(context.Background(), query)
+func (r *PostgresMenuRepository) List(ctx context.Context, q MenuQuery) (MenuPage, error) {
+	query, args := menuListQuery(q)
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return MenuPage{}, err
 	}
 	defer rows.Close()
-	var menu []MenuItem
+	menu := []MenuItem{}
 	for rows.Next() {
 		var item MenuItem
-		err := rows.Scan(&item.ID, &item.Title, &item.Price, &item.ImageURLs, &item.Calories, &item.Description, &item.Category, &item.CreatedAt, &item.UpdatedAt)
+		err := rows.Scan(&item.ID, &item.Title, &item.Price, &item.ImageURLs, &item.Calories, &item.Description, &item.Category, &item.CreatedAt, &item.UpdatedAt,
+			&item.Availability, &item.SoldOutAt, &item.SeasonStart, &item.SeasonEnd)
 		if err != nil {
-			return nil, err
+			return MenuPage{}, err
 		}
 		menu = append(menu, item)
 	}
-	return menu, nil
+	if err := rows.Err(); err != nil {
+		return MenuPage{}, err
+	}
+	return pageOf(menu, q), nil
+}
+
+func (r *PostgresMenuRepository) Stats(ctx context.Context) (ListStats, error) {
+	var stats ListStats
+	var latest *time.Time
+	// Trashing an item changes the menu as much as editing it does, and it
+	// leaves updatedAt alone.
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FILTER (WHERE deletedAt IS NULL), GREATEST(MAX(updatedAt), MAX(deletedAt)) FROM menu`).Scan(&stats.Count, &latest)
+	if latest != nil {
+		stats.LastModified = *latest
+	}
+	return stats, err
+}
+
+func (r *PostgresMenuRepository) CountByCategory(ctx context.Context) (map[string]int, error) {
+	rows, err := r.pool.Query(ctx, `SELECT COALESCE(category, ''), COUNT(*) FROM menu WHERE deletedAt IS NULL GROUP BY 1`)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	var category string
+	var count int
+	_, err = pgx.ForEachRow(rows, []any{&category, &count}, func() error {
+		counts[category] = count
+		return nil
+	})
+	return counts, err
+}
+
+// menuColumns is the column list every menu SELECT scans into a MenuItem.
+// Uncategorised items have a NULL category, which reads back as "", and
+// open season bounds read back as "" too.
+const menuColumns = `id, title, price, imageURLs, calories, description, COALESCE(category, ''), createdAt, updatedAt,
+	availability, soldOutAt, COALESCE(seasonStart::text, ''), COALESCE(seasonEnd::text, '')`
+
+// menuSortColumns maps a sort key to the column compared against the cursor.
+// Each has a composite (column, id) index so keyset pages are index scans.
+var menuSortColumns = map[string]string{
+	"price":   "price",
+	"title":   "title",
+	"created": "createdAt",
+}
+
+// menuListQuery builds the SELECT for q. One row more than the limit is
+// requested so the caller can tell whether another page follows.
+func menuListQuery(q MenuQuery) (string, []any) {
+	where := []string{"deletedAt IS NULL"}
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	if q.Category != "" {
+		where = append(where, "category = "+arg(q.Category))
+	}
+	if q.MinPrice != nil {
+		where = append(where, "price >= "+arg(*q.MinPrice))
+	}
+	if q.MaxPrice != nil {
+		where = append(where, "price <= "+arg(*q.MaxPrice))
+	}
+	if q.MaxCalories != nil {
+		where = append(where, "calories <= "+arg(*q.MaxCalories))
+	}
+	if q.Search != "" {
+		pattern := arg("%" + likeEscaper.Replace(q.Search) + "%")
+		where = append(where, "(title ILIKE "+pattern+" OR description ILIKE "+pattern+")")
+	}
+	if len(q.HiddenCategories) > 0 {
+		where = append(where, "NOT (COALESCE(category, '') = ANY("+arg(q.HiddenCategories)+"))")
+	}
+	if q.Availability != "" {
+		where = append(where, "availability = "+arg(q.Availability))
+	}
+	if q.AvailableOn != "" {
+		day := arg(q.AvailableOn) + "::date"
+		where = append(where, "availability <> 'hidden'",
+			"(availability <> 'seasonal' OR ((seasonStart IS NULL OR seasonStart <= "+day+") AND (seasonEnd IS NULL OR seasonEnd >= "+day+")))")
+	}
+
+	// The default order follows the category positions passed in by the
+	// caller. It is computed rather than indexed, which is fine for a menu
+	// of a few hundred rows.
+	column := menuSortColumns[strings.TrimPrefix(string(q.Sort), "-")]
+	var keys []string
+	switch column {
+	case "":
+		order := arg(q.CategoryOrder)
+		keys = []string{"COALESCE(array_position(" + order + "::text[], category), " + arg(len(q.CategoryOrder)+1) + ")", "COALESCE(category, '')"}
+	default:
+		keys = []string{column}
+	}
+	direction, op := "ASC", ">"
+	if q.Sort.Desc() {
+		direction, op = "DESC", "<"
+	}
+	if c := q.Cursor; c != nil {
+		var values []string
+		switch column {
+		case "":
+			rank := len(q.CategoryOrder) + 1
+			if i := slices.Index(q.CategoryOrder, c.Category); i >= 0 {
+				rank = i + 1
+			}
+			values = []string{arg(rank), arg(c.Category)}
+		case "price":
+			values = []string{arg(c.Price)}
+		case "title":
+			values = []string{arg(c.Title)}
+		case "createdAt":
+			values = []string{arg(c.Created)}
+		}
+		where = append(where, "("+strings.Join(keys, ", ")+", id) "+op+" ("+strings.Join(values, ", ")+", "+arg(c.ID)+")")
+	}
+
+	query := `SELECT ` + menuColumns + ` FROM menu WHERE ` + strings.Join(where, " AND ")
+	query += " ORDER BY "
+	for _, key := range keys {
+		query += key + " " + direction + ", "
+	}
+	query += "id " + direction
+	if q.Limit > 0 {
+		query += " LIMIT " + arg(q.Limit+1)
+	}
+	return query, args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// pageOf trims the extra lookahead row fetched by List and turns it into a
+// cursor pointing at the last returned item.
+func pageOf(items []MenuItem, q MenuQuery) MenuPage {
+	if q.Limit <= 0 || len(items) <= q.Limit {
+		return MenuPage{Items: items}
+	}
+	items = items[:q.Limit]
+	return MenuPage{Items: items, NextCursor: CursorAfter(items[len(items)-1], q.Sort).Encode()}
 }
 
-func DelMenuItem(id int) error {
-	query := `DELETE FROM menu WHERE id = $1`
-	result, err := database.Pool.Exec(context.Background(), query, id)
+func (r *PostgresMenuRepository) Delete(ctx context.Context, id int) error {
+	query := `UPDATE menu SET deletedAt = NOW() WHERE id = $1 AND deletedAt IS NULL`
+	result, err := r.pool.Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -65,15 +284,143 @@ func DelMenuItem(id int) error {
 	return nil
 }
 
-func UpdateMenuItem(id int, item MenuItem) error {
-	query := `UPDATE menu SET title = $1, price = $2, imageURLs = $3, calories = $4, description = $5, category = $6, updatedAt = NOW() + INTERVAL '3 hours' WHERE id = $7`
-	result, err := database.Pool.Exec(context.Background(), query, item.Title, item.Price, item.ImageURLs, item.Calories, item.Description, item.Category, id)
+func (r *PostgresMenuRepository) ListDeleted(ctx context.Context) ([]MenuItem, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+menuColumns+`, deletedAt FROM menu WHERE deletedAt IS NOT NULL ORDER BY deletedAt DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	return scanDeletedMenuItems(rows)
+}
+
+// Restore bumps updatedAt, so cached listings that left the item out are
+// no longer current.
+func (r *PostgresMenuRepository) Restore(ctx context.Context, id int) error {
+	result, err := r.pool.Exec(ctx, `UPDATE menu SET deletedAt = NULL, updatedAt = NOW() WHERE id = $1 AND deletedAt IS NOT NULL`, id)
 	if err != nil {
 		return err
 	}
+	if result.RowsAffected() == 0 {
+		return ErrMenuItemNotFound
+	}
+	return nil
+}
+
+func (r *PostgresMenuRepository) Purge(ctx context.Context, before time.Time) ([]MenuItem, error) {
+	rows, err := r.pool.Query(ctx, `DELETE FROM menu WHERE deletedAt < $1 RETURNING `+menuColumns+`, deletedAt`, before)
+	if err != nil {
+		return nil, err
+	}
+	return scanDeletedMenuItems(rows)
+}
 
+func scanDeletedMenuItems(rows pgx.Rows) ([]MenuItem, error) {
+	defer rows.Close()
+	items := []MenuItem{}
+	for rows.Next() {
+		var item MenuItem
+		err := rows.Scan(&item.ID, &item.Title, &item.Price, &item.ImageURLs, &item.Calories, &item.Description, &item.Category, &item.CreatedAt, &item.UpdatedAt,
+			&item.Availability, &item.SoldOutAt, &item.SeasonStart, &item.SeasonEnd, &item.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *PostgresMenuRepository) Update(ctx context.Context, id int, item MenuItem) error {
+	return r.update(ctx, id, item, nil)
+}
+
+func (r *PostgresMenuRepository) UpdateIfUnmodified(ctx context.Context, id int, item MenuItem, version time.Time) error {
+	return r.update(ctx, id, item, &version)
+}
+
+// update saves the item as it is to menu_revisions and then replaces it.
+// The row is locked from the first read, so the revision is exactly the
+// version the update replaced. With a version, the update is refused if
+// the item has changed since.
+func (r *PostgresMenuRepository) update(ctx context.Context, id int, item MenuItem, version *time.Time) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		current, err := scanMenuItem(tx.QueryRow(ctx, `SELECT `+menuColumns+` FROM menu WHERE id = $1 AND deletedAt IS NULL FOR UPDATE`, id))
+		if err != nil {
+			return err
+		}
+		if version != nil && !current.UpdatedAt.Equal(*version) {
+			return ErrEditConflict
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO menu_revisions (menuId, item) VALUES ($1, $2)`, id, current); err != nil {
+			return err
+		}
+		query := `UPDATE menu SET title = $1, price = $2, imageURLs = $3, calories = $4, description = $5, category = NULLIF($6, ''), ` + menuAvailabilitySet(8) + `, updatedAt = NOW() WHERE id = $7`
+		_, err = tx.Exec(ctx, query, item.Title, item.Price, item.ImageURLs, item.Calories, item.Description, item.Category, id,
+			item.Availability, item.SeasonStart, item.SeasonEnd)
+		return err
+	})
+}
+
+// menuAvailabilitySet is the SET clause for the availability, season start
+// and season end passed as parameters first, first+1 and first+2. An item
+// already on the stop-list keeps its soldOutAt, so editing it does not
+// carry it over into the next day.
+func menuAvailabilitySet(first int) string {
+	a, start, end := "$"+strconv.Itoa(first), "$"+strconv.Itoa(first+1), "$"+strconv.Itoa(first+2)
+	return `availability = ` + a + `, ` +
+		`soldOutAt = CASE WHEN ` + a + ` = 'sold_out' THEN COALESCE(soldOutAt, NOW()) END, ` +
+		`seasonStart = NULLIF(` + start + `, '')::date, seasonEnd = NULLIF(` + end + `, '')::date`
+}
+
+func (r *PostgresMenuRepository) SetAvailability(ctx context.Context, id int, change AvailabilityChange) error {
+	query := `UPDATE menu SET ` + menuAvailabilitySet(2) + `, updatedAt = NOW() WHERE id = $1 AND deletedAt IS NULL`
+	result, err := r.pool.Exec(ctx, query, id, change.Availability, change.SeasonStart, change.SeasonEnd)
+	if err != nil {
+		return err
+	}
 	if result.RowsAffected() == 0 {
 		return ErrMenuItemNotFound
 	}
 	return nil
 }
+
+func (r *PostgresMenuRepository) ResetSoldOut(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.pool.Exec(ctx, `UPDATE menu SET availability = 'available', soldOutAt = NULL, updatedAt = NOW()
+		WHERE availability = 'sold_out' AND soldOutAt < $1 AND deletedAt IS NULL`, before)
+	if err != nil {
+		return 0, err
+	}
+	return int(result.RowsAffected()), nil
+}
+
+func (r *PostgresMenuRepository) ImageInUse(ctx context.Context, url string) (bool, error) {
+	var inUse bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM menu WHERE $1 = ANY(imageURLs))
+		OR EXISTS (SELECT 1 FROM menu_revisions WHERE item->'imageURLs' ? $1)`, url).Scan(&inUse)
+	return inUse, err
+}
+
+func (r *PostgresMenuRepository) ListRevisions(ctx context.Context, id int) ([]MenuRevision, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id, createdAt, item FROM menu_revisions WHERE menuId = $1 ORDER BY id DESC`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	revisions := []MenuRevision{}
+	for rows.Next() {
+		var rev MenuRevision
+		if err := rows.Scan(&rev.ID, &rev.CreatedAt, &rev.Item); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *PostgresMenuRepository) GetRevision(ctx context.Context, id int, revision int64) (MenuRevision, error) {
+	var rev MenuRevision
+	err := r.pool.QueryRow(ctx, `SELECT id, createdAt, item FROM menu_revisions WHERE id = $1 AND menuId = $2`, revision, id).
+		Scan(&rev.ID, &rev.CreatedAt, &rev.Item)
+	if err == pgx.ErrNoRows {
+		return MenuRevision{}, ErrRevisionNotFound
+	}
+	return rev, err
+}
@@ -8,7 +8,83 @@ type News struct {
 	Preview		string		`json:"preview"`
 	Description string 		`json:"description"`
 	ImageURLs 	[]string 	`json:"imageURLs"`
+	Images		[]ImageSet	`json:"images,omitempty"`
 	CreatedAt 	time.Time 	`json:"createdAt"`
 	UpdatedAt 	time.Time 	`json:"updatedAt"`
 	PostedAt 	time.Time 	`json:"postedAt"`
+	Status		NewsStatus	`json:"status"`
+	PublishedAt	*time.Time	`json:"publishedAt,omitempty"`
+	// DeletedAt is when the post was moved to the trash; it is only set
+	// on posts listed from the trash.
+	DeletedAt	*time.Time	`json:"deletedAt,omitempty"`
+}
+
+// NewsStatus is the publishing state of a post. Only published posts, and
+// scheduled ones whose PostedAt has passed, are shown to the public.
+type NewsStatus string
+
+const (
+	// NewsDraft is being written and is visible to staff only.
+	NewsDraft NewsStatus = "draft"
+	// NewsScheduled goes live at PostedAt.
+	NewsScheduled NewsStatus = "scheduled"
+	// NewsPublished is live; PublishedAt records when it went live.
+	NewsPublished NewsStatus = "published"
+	// NewsArchived has been taken down but is kept for reference.
+	NewsArchived NewsStatus = "archived"
+)
+
+var NewsStatuses = []NewsStatus{NewsDraft, NewsScheduled, NewsPublished, NewsArchived}
+
+func (s NewsStatus) Valid() bool {
+	for _, status := range NewsStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// VisibleAt reports whether the public may see the post at now. Scheduled
+// posts count as soon as PostedAt has passed, even if the scheduler has not
+// marked them published yet.
+func (n News) VisibleAt(now time.Time) bool {
+	return (n.Status == NewsPublished || n.Status == NewsScheduled) && !n.PostedAt.After(now)
+}
+
+// Schedule settles Status and PublishedAt for a post being saved at now.
+// previous is the stored version, or nil for a new post. A post saved
+// without a status keeps its previous one, and a new post is published,
+// which is what older clients expect. Publishing with a future PostedAt
+// schedules the post instead. A post saved without PostedAt keeps its
+// previous one, and a new post is posted at now.
+func (n *News) Schedule(now time.Time, previous *News) {
+	if n.PostedAt.IsZero() && previous != nil {
+		n.PostedAt = previous.PostedAt
+	}
+	if n.PostedAt.IsZero() {
+		n.PostedAt = now
+	}
+	if n.Status == "" && previous != nil {
+		n.Status = previous.Status
+	}
+	if n.Status == "" {
+		n.Status = NewsPublished
+	}
+	switch n.Status {
+	case NewsPublished, NewsScheduled:
+		if n.PostedAt.After(now) {
+			n.Status = NewsScheduled
+		} else {
+			n.Status = NewsPublished
+		}
+	}
+	n.PublishedAt = nil
+	if previous != nil && previous.PublishedAt != nil && (n.Status == NewsPublished || n.Status == NewsArchived) {
+		n.PublishedAt = previous.PublishedAt
+	}
+	if n.Status == NewsPublished && n.PublishedAt == nil {
+		published := now
+		n.PublishedAt = &published
+	}
 }
\ No newline at end of file
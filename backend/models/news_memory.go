@@ -0,0 +1,274 @@
+package models
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryNewsRepository keeps news posts in a map. It is meant for tests and
+// local experiments; nothing survives a restart.
+type MemoryNewsRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	items  map[int]News
+	// trash holds deleted posts, as in MemoryMenuRepository.
+	trash map[int]News
+	// revisions holds each post's replaced versions, oldest first.
+	revisions    map[int][]NewsRevision
+	nextRevision int64
+}
+
+func NewMemoryNewsRepository() *MemoryNewsRepository {
+	return &MemoryNewsRepository{nextID: 1, items: map[int]News{}, trash: map[int]News{}, revisions: map[int][]NewsRevision{}, nextRevision: 1}
+}
+
+func (r *MemoryNewsRepository) Create(ctx context.Context, item News) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now().UTC()
+	item.ID = r.nextID
+	item.ImageURLs = append([]string(nil), item.ImageURLs...)
+	item.CreatedAt = now
+	item.UpdatedAt = now
+	r.items[item.ID] = item
+	r.nextID++
+	return item.ID, nil
+}
+
+func (r *MemoryNewsRepository) GetByID(ctx context.Context, id int) (News, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	item, ok := r.items[id]
+	if !ok {
+		return News{}, ErrNewsNotFound
+	}
+	item.ImageURLs = append([]string(nil), item.ImageURLs...)
+	return item, nil
+}
+
+func (r *MemoryNewsRepository) List(ctx context.Context) ([]News, error) {
+	return r.list(func(News) bool { return true }), nil
+}
+
+func (r *MemoryNewsRepository) ListVisible(ctx context.Context, now time.Time) ([]News, error) {
+	return r.list(func(item News) bool { return item.VisibleAt(now) }), nil
+}
+
+func (r *MemoryNewsRepository) VisibleStats(ctx context.Context, now time.Time) (ListStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var stats ListStats
+	for _, item := range r.items {
+		if item.VisibleAt(now) {
+			stats.observe(item.PostedAt)
+		}
+		if item.UpdatedAt.After(stats.LastModified) {
+			stats.LastModified = item.UpdatedAt
+		}
+	}
+	for _, item := range r.trash {
+		if item.DeletedAt.After(stats.LastModified) {
+			stats.LastModified = *item.DeletedAt
+		}
+	}
+	return stats, nil
+}
+
+func (r *MemoryNewsRepository) CountByStatus(ctx context.Context) (map[NewsStatus]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	counts := map[NewsStatus]int{}
+	for _, item := range r.items {
+		counts[item.Status]++
+	}
+	return counts, nil
+}
+
+func (r *MemoryNewsRepository) list(keep func(News) bool) []News {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	news := make([]News, 0, len(r.items))
+	for _, item := range r.items {
+		if !keep(item) {
+			continue
+		}
+		item.ImageURLs = append([]string(nil), item.ImageURLs...)
+		news = append(news, item)
+	}
+	sort.Slice(news, func(i, j int) bool {
+		if !news[i].PostedAt.Equal(news[j].PostedAt) {
+			return news[i].PostedAt.After(news[j].PostedAt)
+		}
+		return news[i].ID > news[j].ID
+	})
+	return news
+}
+
+func (r *MemoryNewsRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, ok := r.items[id]
+	if !ok {
+		return ErrNewsNotFound
+	}
+	deletedAt := time.Now().UTC()
+	item.DeletedAt = &deletedAt
+	r.trash[id] = item
+	delete(r.items, id)
+	return nil
+}
+
+func (r *MemoryNewsRepository) ListDeleted(ctx context.Context) ([]News, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return deletedNews(r.trash, func(News) bool { return true }), nil
+}
+
+func (r *MemoryNewsRepository) Restore(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, ok := r.trash[id]
+	if !ok {
+		return ErrNewsNotFound
+	}
+	item.DeletedAt = nil
+	item.UpdatedAt = time.Now().UTC()
+	r.items[id] = item
+	delete(r.trash, id)
+	return nil
+}
+
+func (r *MemoryNewsRepository) Purge(ctx context.Context, before time.Time) ([]News, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	purged := deletedNews(r.trash, func(item News) bool { return item.DeletedAt.Before(before) })
+	for _, item := range purged {
+		delete(r.trash, item.ID)
+		delete(r.revisions, item.ID)
+	}
+	return purged, nil
+}
+
+// deletedNews returns copies of the trashed posts keep accepts, most
+// recently deleted first.
+func deletedNews(trash map[int]News, keep func(News) bool) []News {
+	news := []News{}
+	for _, item := range trash {
+		if keep(item) {
+			item.ImageURLs = append([]string(nil), item.ImageURLs...)
+			news = append(news, item)
+		}
+	}
+	slices.SortFunc(news, func(a, b News) int {
+		if c := b.DeletedAt.Compare(*a.DeletedAt); c != 0 {
+			return c
+		}
+		return b.ID - a.ID
+	})
+	return news
+}
+
+func (r *MemoryNewsRepository) Update(ctx context.Context, id int, item News) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.items[id]
+	if !ok {
+		return ErrNewsNotFound
+	}
+	r.saveRevision(current)
+	item.ID = id
+	item.ImageURLs = append([]string(nil), item.ImageURLs...)
+	item.CreatedAt = current.CreatedAt
+	item.UpdatedAt = time.Now().UTC()
+	r.items[id] = item
+	return nil
+}
+
+func (r *MemoryNewsRepository) UpdateIfUnmodified(ctx context.Context, id int, item News, version time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.items[id]
+	if !ok {
+		return ErrNewsNotFound
+	}
+	if !current.UpdatedAt.Equal(version) {
+		return ErrEditConflict
+	}
+	r.saveRevision(current)
+	item.ID = id
+	item.ImageURLs = append([]string(nil), item.ImageURLs...)
+	item.CreatedAt = current.CreatedAt
+	item.UpdatedAt = time.Now().UTC()
+	r.items[id] = item
+	return nil
+}
+
+func (r *MemoryNewsRepository) ImageInUse(ctx context.Context, url string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, items := range []map[int]News{r.items, r.trash} {
+		for _, item := range items {
+			if slices.Contains(item.ImageURLs, url) {
+				return true, nil
+			}
+		}
+	}
+	for _, revisions := range r.revisions {
+		for _, rev := range revisions {
+			if slices.Contains(rev.Item.ImageURLs, url) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (r *MemoryNewsRepository) PublishDue(ctx context.Context, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	published := 0
+	for id, item := range r.items {
+		if item.Status == NewsScheduled && !item.PostedAt.After(now) {
+			item.Status = NewsPublished
+			publishedAt := now
+			item.PublishedAt = &publishedAt
+			r.items[id] = item
+			published++
+		}
+	}
+	return published, nil
+}
+
+// saveRevision keeps item as a replaced version. The caller holds r.mu.
+func (r *MemoryNewsRepository) saveRevision(item News) {
+	item.ImageURLs = append([]string(nil), item.ImageURLs...)
+	rev := NewsRevision{ID: r.nextRevision, CreatedAt: time.Now().UTC(), Item: item}
+	r.revisions[item.ID] = append(r.revisions[item.ID], rev)
+	r.nextRevision++
+}
+
+func (r *MemoryNewsRepository) ListRevisions(ctx context.Context, id int) ([]NewsRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	revisions := []NewsRevision{}
+	for _, rev := range slices.Backward(r.revisions[id]) {
+		rev.Item.ImageURLs = append([]string(nil), rev.Item.ImageURLs...)
+		revisions = append(revisions, rev)
+	}
+	return revisions, nil
+}
+
+func (r *MemoryNewsRepository) GetRevision(ctx context.Context, id int, revision int64) (NewsRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, rev := range r.revisions[id] {
+		if rev.ID == revision {
+			rev.Item.ImageURLs = append([]string(nil), rev.Item.ImageURLs...)
+			return rev, nil
+		}
+	}
+	return NewsRevision{}, ErrRevisionNotFound
+}
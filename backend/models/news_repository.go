@@ -5,26 +5,88 @@ import (
 	"errors"
 	"time"
 
-	"github.com/andrey-918/cafe-between/internal/database"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var ErrNewsNotFound = errors.New("News item not found")
 
-func CreateNews(item News) (int, error) {
-	query := `INSERT INTO news (title, preview, description, imageURLs, createdAt, updatedAt, postedAt) values ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+// NewsRepository is the storage boundary for news posts, mirroring
+// MenuRepository.
+type NewsRepository interface {
+	Create(ctx context.Context, item News) (int, error)
+	GetByID(ctx context.Context, id int) (News, error)
+	// List returns every post, whatever its status, newest first.
+	List(ctx context.Context) ([]News, error)
+	// ListVisible returns the posts the public may see at now, newest
+	// first.
+	ListVisible(ctx context.Context, now time.Time) ([]News, error)
+	// VisibleStats summarises what ListVisible would return at now. Its
+	// LastModified also moves when a scheduled post goes live, and when a
+	// post is taken down, so it never goes backwards.
+	VisibleStats(ctx context.Context, now time.Time) (ListStats, error)
+	// CountByStatus returns how many posts have each status.
+	CountByStatus(ctx context.Context) (map[NewsStatus]int, error)
+	// Update replaces the post, saving the version it replaces as a
+	// revision.
+	Update(ctx context.Context, id int, item News) error
+	// UpdateIfUnmodified is Update for an edit based on the version of the
+	// post last updated at version. It fails with ErrEditConflict if the
+	// post has been updated since.
+	UpdateIfUnmodified(ctx context.Context, id int, item News, version time.Time) error
+	// Delete moves the post to the trash, like MenuRepository.Delete.
+	Delete(ctx context.Context, id int) error
+	// ListDeleted returns the trashed posts, most recently deleted first.
+	ListDeleted(ctx context.Context) ([]News, error)
+	Restore(ctx context.Context, id int) error
+	// Purge permanently removes the posts trashed before the given time and
+	// returns them.
+	Purge(ctx context.Context, before time.Time) ([]News, error)
+	// ImageInUse reports whether any news post or revision, trashed posts
+	// included, references the image URL.
+	ImageInUse(ctx context.Context, url string) (bool, error)
+	// PublishDue marks scheduled posts whose PostedAt has passed as
+	// published at now and returns how many were changed.
+	PublishDue(ctx context.Context, now time.Time) (int, error)
+	// ListRevisions and GetRevision read the versions Update and
+	// UpdateIfUnmodified replaced, like their MenuRepository counterparts.
+	// PublishDue leaves no revision.
+	ListRevisions(ctx context.Context, id int) ([]NewsRevision, error)
+	GetRevision(ctx context.Context, id int, revision int64) (NewsRevision, error)
+}
+
+var (
+	_ NewsRepository = (*PostgresNewsRepository)(nil)
+	_ NewsRepository = (*MemoryNewsRepository)(nil)
+)
+
+type PostgresNewsRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresNewsRepository(pool *pgxpool.Pool) *PostgresNewsRepository {
+	return &PostgresNewsRepository{pool: pool}
+}
+
+func (r *PostgresNewsRepository) Create(ctx context.Context, item News) (int, error) {
+	query := `INSERT INTO news (title, preview, description, imageURLs, createdAt, updatedAt, postedAt, status, publishedAt) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
 	var id int
-	now := time.Now().UTC().Add(3 * time.Hour) // UTC+3 for Moscow
-	err := database.Pool.QueryRow(context.Background(), query, item.Title, item.Preview, item.Description, item.ImageURLs, now, now, item.PostedAt).Scan(&id)
+	now := time.Now().UTC()
+	err := r.pool.QueryRow(ctx, query, item.Title, item.Preview, item.Description, item.ImageURLs, now, now, item.PostedAt, item.Status, item.PublishedAt).Scan(&id)
 	return id, err
 }
 
-func GetNewsByID(id int) (News, error) {
-	query := `SELECT id, title, preview, description, imageURLs, createdAt, updatedAt, postedAt FROM news WHERE id = $1`
+const newsColumns = `id, title, preview, description, imageURLs, createdAt, updatedAt, postedAt, status, publishedAt`
+
+func scanNews(row pgx.Row) (News, error) {
 	var item News
-	err := database.Pool.QueryRow(context.Background(), query, id).Scan(
-		&item.ID, &item.Title, &item.Preview, &item.Description, &item.ImageURLs, &item.CreatedAt, &item.UpdatedAt, &item.PostedAt,
-	)
+	err := row.Scan(&item.ID, &item.Title, &item.Preview, &item.Description, &item.ImageURLs, &item.CreatedAt, &item.UpdatedAt, &item.PostedAt, &item.Status, &item.PublishedAt)
+	return item, err
+}
+
+func (r *PostgresNewsRepository) GetByID(ctx context.Context, id int) (News, error) {
+	query := `SELECT ` + newsColumns + ` FROM news WHERE id = $1 AND deletedAt IS NULL`
+	item, err := scanNews(r.pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return News{}, ErrNewsNotFound
@@ -34,28 +96,65 @@ func GetNewsByID(id int) (News, error) {
 	return item, nil
 }
 
-func GetNews() ([]News, error) {
-	query := `SELECT id, title, preview, description, imageURLs, createdAt, updatedAt, postedAt FROM news ORDER BY postedAt DESC`
-	rows, err := database.Pool.Query(context.Background(), query)
+func (r *PostgresNewsRepository) List(ctx context.Context) ([]News, error) {
+	query := `SELECT ` + newsColumns + ` FROM news WHERE deletedAt IS NULL ORDER BY postedAt DESC, id DESC`
+	return r.list(ctx, query)
+}
+
+func (r *PostgresNewsRepository) ListVisible(ctx context.Context, now time.Time) ([]News, error) {
+	query := `SELECT ` + newsColumns + ` FROM news WHERE status IN ('published', 'scheduled') AND postedAt <= $1 AND deletedAt IS NULL ORDER BY postedAt DESC, id DESC`
+	return r.list(ctx, query, now)
+}
+
+func (r *PostgresNewsRepository) CountByStatus(ctx context.Context) (map[NewsStatus]int, error) {
+	rows, err := r.pool.Query(ctx, `SELECT status, COUNT(*) FROM news WHERE deletedAt IS NULL GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[NewsStatus]int{}
+	var status NewsStatus
+	var count int
+	_, err = pgx.ForEachRow(rows, []any{&status, &count}, func() error {
+		counts[status] = count
+		return nil
+	})
+	return counts, err
+}
+
+func (r *PostgresNewsRepository) VisibleStats(ctx context.Context, now time.Time) (ListStats, error) {
+	query := `SELECT
+		COUNT(*) FILTER (WHERE status IN ('published', 'scheduled') AND postedAt <= $1 AND deletedAt IS NULL),
+		GREATEST(MAX(updatedAt), MAX(deletedAt), MAX(postedAt) FILTER (WHERE status IN ('published', 'scheduled') AND postedAt <= $1 AND deletedAt IS NULL))
+		FROM news`
+	var stats ListStats
+	var latest *time.Time
+	err := r.pool.QueryRow(ctx, query, now).Scan(&stats.Count, &latest)
+	if latest != nil {
+		stats.LastModified = *latest
+	}
+	return stats, err
+}
+
+func (r *PostgresNewsRepository) list(ctx context.Context, query string, args ...any) ([]News, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var news []News
+	news := []News{}
 	for rows.Next() {
-		var item News
-		err := rows.Scan(&item.ID, &item.Title, &item.Preview, &item.Description, &item.ImageURLs, &item.CreatedAt, &item.UpdatedAt, &item.PostedAt)
+		item, err := scanNews(rows)
 		if err != nil {
 			return nil, err
 		}
 		news = append(news, item)
 	}
-	return news, nil
+	return news, rows.Err()
 }
 
-func DelNews(id int) error {
-	query := `DELETE FROM news WHERE id = $1`
-	result, err := database.Pool.Exec(context.Background(), query, id)
+func (r *PostgresNewsRepository) Delete(ctx context.Context, id int) error {
+	query := `UPDATE news SET deletedAt = NOW() WHERE id = $1 AND deletedAt IS NULL`
+	result, err := r.pool.Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -65,15 +164,114 @@ func DelNews(id int) error {
 	return nil
 }
 
-func UpdateNews(id int, item News) error {
-	query := `UPDATE news SET title = $1, preview = $2, description = $3, imageURLs = $4, updatedAt = NOW() + INTERVAL '3 hours', postedAt = $5 WHERE id = $6`
-	result, err := database.Pool.Exec(context.Background(), query, item.Title, item.Preview, item.Description, item.ImageURLs, item.PostedAt, id)
+func (r *PostgresNewsRepository) ListDeleted(ctx context.Context) ([]News, error) {
+	query := `SELECT ` + newsColumns + `, deletedAt FROM news WHERE deletedAt IS NOT NULL ORDER BY deletedAt DESC, id DESC`
+	return r.listDeleted(ctx, query)
+}
+
+func (r *PostgresNewsRepository) Restore(ctx context.Context, id int) error {
+	result, err := r.pool.Exec(ctx, `UPDATE news SET deletedAt = NULL, updatedAt = NOW() WHERE id = $1 AND deletedAt IS NOT NULL`, id)
 	if err != nil {
 		return err
 	}
-
 	if result.RowsAffected() == 0 {
 		return ErrNewsNotFound
 	}
 	return nil
 }
+
+func (r *PostgresNewsRepository) Purge(ctx context.Context, before time.Time) ([]News, error) {
+	return r.listDeleted(ctx, `DELETE FROM news WHERE deletedAt < $1 RETURNING `+newsColumns+`, deletedAt`, before)
+}
+
+func (r *PostgresNewsRepository) listDeleted(ctx context.Context, query string, args ...any) ([]News, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	news := []News{}
+	for rows.Next() {
+		var item News
+		err := rows.Scan(&item.ID, &item.Title, &item.Preview, &item.Description, &item.ImageURLs, &item.CreatedAt, &item.UpdatedAt, &item.PostedAt, &item.Status, &item.PublishedAt, &item.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		news = append(news, item)
+	}
+	return news, rows.Err()
+}
+
+func (r *PostgresNewsRepository) Update(ctx context.Context, id int, item News) error {
+	return r.update(ctx, id, item, nil)
+}
+
+func (r *PostgresNewsRepository) UpdateIfUnmodified(ctx context.Context, id int, item News, version time.Time) error {
+	return r.update(ctx, id, item, &version)
+}
+
+// update saves the post to news_revisions and then replaces it, as
+// PostgresMenuRepository.update does.
+func (r *PostgresNewsRepository) update(ctx context.Context, id int, item News, version *time.Time) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		current, err := scanNews(tx.QueryRow(ctx, `SELECT `+newsColumns+` FROM news WHERE id = $1 AND deletedAt IS NULL FOR UPDATE`, id))
+		if err == pgx.ErrNoRows {
+			return ErrNewsNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if version != nil && !current.UpdatedAt.Equal(*version) {
+			return ErrEditConflict
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO news_revisions (newsId, item) VALUES ($1, $2)`, id, current); err != nil {
+			return err
+		}
+		query := `UPDATE news SET title = $1, preview = $2, description = $3, imageURLs = $4, updatedAt = NOW(), postedAt = $5, status = $6, publishedAt = $7 WHERE id = $8`
+		_, err = tx.Exec(ctx, query, item.Title, item.Preview, item.Description, item.ImageURLs, item.PostedAt, item.Status, item.PublishedAt, id)
+		return err
+	})
+}
+
+func (r *PostgresNewsRepository) ImageInUse(ctx context.Context, url string) (bool, error) {
+	var inUse bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM news WHERE $1 = ANY(imageURLs))
+		OR EXISTS (SELECT 1 FROM news_revisions WHERE item->'imageURLs' ? $1)`, url).Scan(&inUse)
+	return inUse, err
+}
+
+func (r *PostgresNewsRepository) PublishDue(ctx context.Context, now time.Time) (int, error) {
+	query := `UPDATE news SET status = 'published', publishedAt = $1 WHERE status = 'scheduled' AND postedAt <= $1 AND deletedAt IS NULL`
+	result, err := r.pool.Exec(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return int(result.RowsAffected()), nil
+}
+
+func (r *PostgresNewsRepository) ListRevisions(ctx context.Context, id int) ([]NewsRevision, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id, createdAt, item FROM news_revisions WHERE newsId = $1 ORDER BY id DESC`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	revisions := []NewsRevision{}
+	for rows.Next() {
+		var rev NewsRevision
+		if err := rows.Scan(&rev.ID, &rev.CreatedAt, &rev.Item); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *PostgresNewsRepository) GetRevision(ctx context.Context, id int, revision int64) (NewsRevision, error) {
+	var rev NewsRevision
+	err := r.pool.QueryRow(ctx, `SELECT id, createdAt, item FROM news_revisions WHERE id = $1 AND newsId = $2`, revision, id).
+		Scan(&rev.ID, &rev.CreatedAt, &rev.Item)
+	if err == pgx.ErrNoRows {
+		return NewsRevision{}, ErrRevisionNotFound
+	}
+	return rev, err
+}
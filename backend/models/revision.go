@@ -0,0 +1,23 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// MenuRevision is a menu item as it was before an update replaced it.
+// CreatedAt is when it was replaced.
+type MenuRevision struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Item      MenuItem  `json:"item"`
+}
+
+// NewsRevision is a news post as it was before an update replaced it.
+type NewsRevision struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Item      News      `json:"item"`
+}
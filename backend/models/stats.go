@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ListStats summarises a listing without loading it: how many rows it has
+// and when the newest change to them was made. Handlers derive cache
+// validators from it; any save changes LastModified and any insert or
+// delete changes Count.
+type ListStats struct {
+	Count        int
+	LastModified time.Time
+}
+
+// observe folds one row's modification time into s.
+func (s *ListStats) observe(t time.Time) {
+	s.Count++
+	if t.After(s.LastModified) {
+		s.LastModified = t
+	}
+}
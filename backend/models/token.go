@@ -0,0 +1,24 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// RefreshToken is the server-side record of an issued refresh token. Only
+// the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID         int
+	UserID     int
+	TokenHash  string
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+	ReplacedBy *int
+	CreatedAt  time.Time
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryTokenRepository keeps refresh tokens and revocations in maps for
+// tests.
+type MemoryTokenRepository struct {
+	mu          sync.Mutex
+	nextID      int
+	refresh     map[int]RefreshToken
+	revokedJTIs map[string]time.Time
+	// versions outlive the users they belong to, as in Postgres, so a
+	// deleted user's access tokens stay revoked.
+	versions map[int]int
+}
+
+func NewMemoryTokenRepository() *MemoryTokenRepository {
+	return &MemoryTokenRepository{
+		nextID:      1,
+		refresh:     map[int]RefreshToken{},
+		revokedJTIs: map[string]time.Time{},
+		versions:    map[int]int{},
+	}
+}
+
+func (r *MemoryTokenRepository) CreateRefreshToken(ctx context.Context, token RefreshToken) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token.ID = r.nextID
+	token.CreatedAt = time.Now().UTC()
+	r.refresh[token.ID] = token
+	r.nextID++
+	return token.ID, nil
+}
+
+func (r *MemoryTokenRepository) GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.refresh {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return RefreshToken{}, ErrRefreshTokenNotFound
+}
+
+func (r *MemoryTokenRepository) RevokeRefreshToken(ctx context.Context, id int, replacedBy *int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.refresh[id]
+	if !ok || token.RevokedAt != nil {
+		return ErrRefreshTokenRevoked
+	}
+	now := time.Now().UTC()
+	token.RevokedAt = &now
+	token.ReplacedBy = replacedBy
+	r.refresh[id] = token
+	return nil
+}
+
+func (r *MemoryTokenRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revokedJTIs[jti] = expiresAt
+	return nil
+}
+
+func (r *MemoryTokenRepository) SessionVersion(ctx context.Context, userID int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.versions[userID], nil
+}
+
+func (r *MemoryTokenRepository) RevokeUserSessions(ctx context.Context, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now().UTC()
+	for id, token := range r.refresh {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			r.refresh[id] = token
+		}
+	}
+	r.versions[userID]++
+	return nil
+}
+
+func (r *MemoryTokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string, userID int, sessionVersion int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.revokedJTIs[jti]; ok {
+		return true, nil
+	}
+	return r.versions[userID] > sessionVersion, nil
+}
+
+func (r *MemoryTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted int64
+	for jti, expiresAt := range r.revokedJTIs {
+		if expiresAt.Before(now) {
+			delete(r.revokedJTIs, jti)
+			deleted++
+		}
+	}
+	for id, token := range r.refresh {
+		if token.ExpiresAt.Before(now) {
+			delete(r.refresh, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
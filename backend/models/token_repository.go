@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenRevoked  = errors.New("refresh token already revoked")
+)
+
+// TokenRepository stores refresh tokens and the revocation list that
+// JWTMiddleware consults on every authenticated request.
+type TokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token RefreshToken) (int, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error)
+	// RevokeRefreshToken marks the token revoked, recording the token that
+	// replaced it on rotation. It returns ErrRefreshTokenRevoked if the
+	// token was already revoked, which callers treat as token reuse.
+	RevokeRefreshToken(ctx context.Context, id int, replacedBy *int) error
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// SessionVersion is embedded in every access token issued to the user.
+	SessionVersion(ctx context.Context, userID int) (int, error)
+	// RevokeUserSessions revokes every refresh token of the user and bumps
+	// the session version, which rejects all access tokens issued so far.
+	RevokeUserSessions(ctx context.Context, userID int) error
+	IsAccessTokenRevoked(ctx context.Context, jti string, userID int, sessionVersion int) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+var (
+	_ TokenRepository = (*PostgresTokenRepository)(nil)
+	_ TokenRepository = (*MemoryTokenRepository)(nil)
+)
+
+type PostgresTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresTokenRepository(pool *pgxpool.Pool) *PostgresTokenRepository {
+	return &PostgresTokenRepository{pool: pool}
+}
+
+func (r *PostgresTokenRepository) CreateRefreshToken(ctx context.Context, token RefreshToken) (int, error) {
+	query := `INSERT INTO refresh_tokens (userId, tokenHash, expiresAt) VALUES ($1, $2, $3) RETURNING id`
+	var id int
+	err := r.pool.QueryRow(ctx, query, token.UserID, token.TokenHash, token.ExpiresAt).Scan(&id)
+	return id, err
+}
+
+func (r *PostgresTokenRepository) GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	query := `SELECT id, userId, tokenHash, expiresAt, revokedAt, replacedBy, createdAt FROM refresh_tokens WHERE tokenHash = $1`
+	var token RefreshToken
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &token.RevokedAt, &token.ReplacedBy, &token.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return RefreshToken{}, ErrRefreshTokenNotFound
+		}
+		return RefreshToken{}, err
+	}
+	return token, nil
+}
+
+func (r *PostgresTokenRepository) RevokeRefreshToken(ctx context.Context, id int, replacedBy *int) error {
+	query := `UPDATE refresh_tokens SET revokedAt = CURRENT_TIMESTAMP, replacedBy = $1 WHERE id = $2 AND revokedAt IS NULL`
+	result, err := r.pool.Exec(ctx, query, replacedBy, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrRefreshTokenRevoked
+	}
+	return nil
+}
+
+func (r *PostgresTokenRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	query := `INSERT INTO revoked_tokens (jti, expiresAt) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`
+	_, err := r.pool.Exec(ctx, query, jti, expiresAt)
+	return err
+}
+
+func (r *PostgresTokenRepository) SessionVersion(ctx context.Context, userID int) (int, error) {
+	var version int
+	err := r.pool.QueryRow(ctx, `SELECT COALESCE((SELECT version FROM session_versions WHERE userId = $1), 0)`, userID).Scan(&version)
+	return version, err
+}
+
+func (r *PostgresTokenRepository) RevokeUserSessions(ctx context.Context, userID int) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `UPDATE refresh_tokens SET revokedAt = CURRENT_TIMESTAMP WHERE userId = $1 AND revokedAt IS NULL`, userID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO session_versions (userId, version) VALUES ($1, 1)
+			ON CONFLICT (userId) DO UPDATE SET version = session_versions.version + 1`, userID)
+		return err
+	})
+}
+
+func (r *PostgresTokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string, userID int, sessionVersion int) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+		OR EXISTS (SELECT 1 FROM session_versions WHERE userId = $2 AND version > $3)`
+	var revoked bool
+	err := r.pool.QueryRow(ctx, query, jti, userID, sessionVersion).Scan(&revoked)
+	return revoked, err
+}
+
+func (r *PostgresTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `DELETE FROM revoked_tokens WHERE expiresAt < $1`, now)
+		if err != nil {
+			return err
+		}
+		deleted += result.RowsAffected()
+		result, err = tx.Exec(ctx, `DELETE FROM refresh_tokens WHERE expiresAt < $1`, now)
+		if err != nil {
+			return err
+		}
+		deleted += result.RowsAffected()
+		return nil
+	})
+	return deleted, err
+}
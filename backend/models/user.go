@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Role decides which admin routes a staff account may call.
+type Role string
+
+const (
+	// RoleOwner can do everything, including managing other accounts.
+	RoleOwner Role = "owner"
+	// RoleEditor manages news posts only.
+	RoleEditor Role = "editor"
+	// RoleMenuManager manages the menu.
+	RoleMenuManager Role = "menu_manager"
+	// RoleBarista can view the menu and mark dishes as available or not.
+	RoleBarista Role = "barista"
+)
+
+var Roles = []Role{RoleOwner, RoleEditor, RoleMenuManager, RoleBarista}
+
+func (r Role) Valid() bool {
+	for _, role := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type User struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Role         Role      `json:"role"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+func (u User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryUserRepository keeps staff accounts in a map for tests.
+type MemoryUserRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	users  map[int]User
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{nextID: 1, users: map[int]User{}}
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, user User) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.usernameTaken(user.Username, 0) {
+		return 0, ErrUsernameTaken
+	}
+	now := time.Now().UTC()
+	user.ID = r.nextID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	r.users[user.ID] = user
+	r.nextID++
+	return user.ID, nil
+}
+
+func (r *MemoryUserRepository) GetByID(ctx context.Context, id int) (User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (r *MemoryUserRepository) GetByUsername(ctx context.Context, username string) (User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return User{}, ErrUserNotFound
+}
+
+func (r *MemoryUserRepository) List(ctx context.Context) ([]User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := make([]User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *MemoryUserRepository) Update(ctx context.Context, id int, user User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.users[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+	if r.usernameTaken(user.Username, id) {
+		return ErrUsernameTaken
+	}
+	current.Username = user.Username
+	current.Role = user.Role
+	current.UpdatedAt = time.Now().UTC()
+	r.users[id] = current
+	return nil
+}
+
+func (r *MemoryUserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.users[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+	current.PasswordHash = passwordHash
+	current.UpdatedAt = time.Now().UTC()
+	r.users[id] = current
+	return nil
+}
+
+func (r *MemoryUserRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[id]; !ok {
+		return ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+func (r *MemoryUserRepository) Count(ctx context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.users), nil
+}
+
+func (r *MemoryUserRepository) usernameTaken(username string, exceptID int) bool {
+	for id, user := range r.users {
+		if id != exceptID && user.Username == username {
+			return true
+		}
+	}
+	return false
+}
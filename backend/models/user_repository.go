@@ -0,0 +1,139 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrUsernameTaken = errors.New("username already taken")
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, user User) (int, error)
+	GetByID(ctx context.Context, id int) (User, error)
+	GetByUsername(ctx context.Context, username string) (User, error)
+	List(ctx context.Context) ([]User, error)
+	// Update changes the username and role; the password is left alone.
+	Update(ctx context.Context, id int, user User) error
+	UpdatePassword(ctx context.Context, id int, passwordHash string) error
+	Delete(ctx context.Context, id int) error
+	Count(ctx context.Context) (int, error)
+}
+
+var (
+	_ UserRepository = (*PostgresUserRepository)(nil)
+	_ UserRepository = (*MemoryUserRepository)(nil)
+)
+
+type PostgresUserRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresUserRepository(pool *pgxpool.Pool) *PostgresUserRepository {
+	return &PostgresUserRepository{pool: pool}
+}
+
+func (r *PostgresUserRepository) Create(ctx context.Context, user User) (int, error) {
+	query := `INSERT INTO users (username, passwordHash, role) VALUES ($1, $2, $3) RETURNING id`
+	var id int
+	err := r.pool.QueryRow(ctx, query, user.Username, user.PasswordHash, user.Role).Scan(&id)
+	if isUniqueViolation(err) {
+		return 0, ErrUsernameTaken
+	}
+	return id, err
+}
+
+func (r *PostgresUserRepository) GetByID(ctx context.Context, id int) (User, error) {
+	query := `SELECT id, username, passwordHash, role, createdAt, updatedAt FROM users WHERE id = $1`
+	return r.getOne(ctx, query, id)
+}
+
+func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username string) (User, error) {
+	query := `SELECT id, username, passwordHash, role, createdAt, updatedAt FROM users WHERE username = $1`
+	return r.getOne(ctx, query, username)
+}
+
+func (r *PostgresUserRepository) getOne(ctx context.Context, query string, arg any) (User, error) {
+	var user User
+	err := r.pool.QueryRow(ctx, query, arg).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return User{}, ErrUserNotFound
+		}
+		return User{}, err
+	}
+	return user, nil
+}
+
+func (r *PostgresUserRepository) List(ctx context.Context) ([]User, error) {
+	query := `SELECT id, username, passwordHash, role, createdAt, updatedAt FROM users ORDER BY id`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []User
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *PostgresUserRepository) Update(ctx context.Context, id int, user User) error {
+	query := `UPDATE users SET username = $1, role = $2, updatedAt = CURRENT_TIMESTAMP WHERE id = $3`
+	result, err := r.pool.Exec(ctx, query, user.Username, user.Role, id)
+	if isUniqueViolation(err) {
+		return ErrUsernameTaken
+	}
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	query := `UPDATE users SET passwordHash = $1, updatedAt = CURRENT_TIMESTAMP WHERE id = $2`
+	result, err := r.pool.Exec(ctx, query, passwordHash, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users`).Scan(&count)
+	return count, err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
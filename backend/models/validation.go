@@ -0,0 +1,122 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/validate"
+)
+
+// Limits enforced on admin payloads. Title lengths match the VARCHAR(255)
+// columns; the rest keep obviously broken input out of the database.
+const (
+	MaxTitleLength       = 255
+	MaxPreviewLength     = 500
+	MaxDescriptionLength = 5000
+	MaxImages            = 10
+	MaxPrice             = 1_000_000
+	MaxCalories          = 10_000
+)
+
+// Normalize trims surrounding whitespace and drops blank image links, which
+// the admin forms send for empty inputs. The image list is never nil, as
+// the column is NOT NULL.
+func (m *MenuItem) Normalize() {
+	m.Title = strings.TrimSpace(m.Title)
+	m.Category = strings.TrimSpace(m.Category)
+	m.ImageURLs = compactURLs(m.ImageURLs)
+	change := m.AvailabilityChange()
+	change.Normalize()
+	m.applyAvailability(change)
+}
+
+// Validate checks the fields of a menu item that can be judged on their
+// own. Whether the category exists is up to the caller.
+func (m MenuItem) Validate(v *validate.Validator) {
+	v.Required("title", m.Title)
+	v.MaxLength("title", m.Title, MaxTitleLength)
+	v.Range("price", m.Price, 0, MaxPrice)
+	v.Range("calories", m.Calories, 0, MaxCalories)
+	v.MaxLength("description", m.Description, MaxDescriptionLength)
+	v.ImageURLs("imageURLs", m.ImageURLs, MaxImages)
+	m.AvailabilityChange().Validate(v)
+}
+
+// AvailabilityChange returns the availability fields of the item.
+func (m MenuItem) AvailabilityChange() AvailabilityChange {
+	return AvailabilityChange{Availability: m.Availability, SeasonStart: m.SeasonStart, SeasonEnd: m.SeasonEnd}
+}
+
+// Normalize defaults the state to available, as older clients do not send
+// one, and drops season bounds from items that are not seasonal.
+func (c *AvailabilityChange) Normalize() {
+	c.SeasonStart = strings.TrimSpace(c.SeasonStart)
+	c.SeasonEnd = strings.TrimSpace(c.SeasonEnd)
+	if c.Availability == "" {
+		c.Availability = Available
+	}
+	if c.Availability != Seasonal {
+		c.SeasonStart, c.SeasonEnd = "", ""
+	}
+}
+
+func (c AvailabilityChange) Validate(v *validate.Validator) {
+	v.Check(c.Availability.Valid(), "availability", validate.CodeInvalid, "must be one of available, sold_out, hidden, seasonal")
+	if c.Availability != Seasonal {
+		return
+	}
+	v.Check(c.SeasonStart != "" || c.SeasonEnd != "", "seasonStart", validate.CodeRequired, "a seasonal item needs a start or an end date")
+	for _, f := range []struct{ field, date string }{{"seasonStart", c.SeasonStart}, {"seasonEnd", c.SeasonEnd}} {
+		if _, err := time.Parse(time.DateOnly, f.date); f.date != "" && err != nil {
+			v.Add(f.field, validate.CodeInvalid, "must be a date, YYYY-MM-DD")
+		}
+	}
+	if c.SeasonStart != "" && c.SeasonEnd != "" && !v.Has("seasonStart") && !v.Has("seasonEnd") {
+		v.Check(c.SeasonStart <= c.SeasonEnd, "seasonEnd", validate.CodeTooSmall, "must not be before seasonStart")
+	}
+}
+
+// Normalize and Validate do for news posts what the MenuItem methods of the
+// same name do for menu items.
+func (n *News) Normalize() {
+	n.Title = strings.TrimSpace(n.Title)
+	n.ImageURLs = compactURLs(n.ImageURLs)
+	// Clients may send any offset; only the instant is kept.
+	n.PostedAt = n.PostedAt.UTC()
+}
+
+func (n News) Validate(v *validate.Validator) {
+	v.Required("title", n.Title)
+	v.MaxLength("title", n.Title, MaxTitleLength)
+	v.MaxLength("preview", n.Preview, MaxPreviewLength)
+	v.MaxLength("description", n.Description, MaxDescriptionLength)
+	v.ImageURLs("imageURLs", n.ImageURLs, MaxImages)
+	if n.Status != "" {
+		v.Check(n.Status.Valid(), "status", validate.CodeInvalid, "must be one of draft, scheduled, published, archived")
+	}
+}
+
+func (c *Category) Normalize() {
+	c.Slug = strings.TrimSpace(c.Slug)
+	c.Name = strings.TrimSpace(c.Name)
+}
+
+// Validate checks the editable fields of a category. The slug is only
+// checked when a category is created, as older ones may predate the rule.
+func (c Category) Validate(v *validate.Validator) {
+	v.Required("name", c.Name)
+	v.MaxLength("name", c.Name, MaxTitleLength)
+	if c.ImageURL != "" {
+		v.URL("imageURL", c.ImageURL)
+	}
+}
+
+func compactURLs(urls []string) []string {
+	out := []string{}
+	for _, u := range urls {
+		if u = strings.TrimSpace(u); u != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrey-918/cafe-between/internal/config"
+	"github.com/andrey-918/cafe-between/internal/storage"
+)
+
+// uploadsPath is where the local storage backend is served from.
+const uploadsPath = "/uploads/"
+
+// newStorage opens the upload backend cfg selects.
+func newStorage(cfg config.Storage) (storage.Storage, error) {
+	switch cfg.Backend {
+	case "local":
+		return storage.NewLocal(cfg.UploadDir, strings.TrimRight(cfg.PublicURL, "/")+strings.TrimSuffix(uploadsPath, "/"))
+	case "s3":
+		return storage.NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/audit"
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/internal/validate"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func auditLog(t *testing.T, r *mux.Router, token, query string) []models.AuditEntry {
+	t.Helper()
+	rec := staffRequest(t, r, token, http.MethodGet, "/api/admin/audit"+query, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var entries []models.AuditEntry
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&entries))
+	return entries
+}
+
+func TestMenuChangesAreAudited(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "olga", "owner-pass", models.RoleOwner)
+	createTestUser(t, h, "mila", "manager-pass", models.RoleMenuManager)
+	r := newTestRouter(t, h)
+	owner := loginToken(t, h, "olga", "owner-pass")
+	manager := loginToken(t, h, "mila", "manager-pass")
+	mila, err := h.Users.GetByUsername(context.Background(), "mila")
+	require.NoError(t, err)
+
+	rec := staffRequest(t, r, manager, http.MethodPost, "/api/admin/menu", `{"title":"Latte","price":250}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var item models.MenuItem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&item))
+	itemPath := "/api/admin/menu/" + strconv.Itoa(item.ID)
+	rec = staffRequest(t, r, manager, http.MethodPut, itemPath, `{"title":"Latte","price":270}`)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	rec = staffRequest(t, r, manager, http.MethodDelete, itemPath, "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	entries := auditLog(t, r, owner, "?entity=menu_item&entityId="+strconv.Itoa(item.ID))
+	require.Len(t, entries, 3)
+	deleted, updated, created := entries[0], entries[1], entries[2]
+	assert.Equal(t, models.AuditDelete, deleted.Action)
+	assert.Equal(t, models.AuditCreate, created.Action)
+	assert.JSONEq(t, `"Latte"`, string(created.Changes["title"].After))
+
+	assert.Equal(t, models.AuditUpdate, updated.Action)
+	require.NotNil(t, updated.UserID)
+	assert.Equal(t, mila.ID, *updated.UserID)
+	assert.Equal(t, "mila", updated.Username)
+	assert.Equal(t, "192.0.2.1", updated.IP, "httptest requests come from 192.0.2.1")
+	assert.Equal(t, map[string]models.AuditChange{
+		"price": {Before: json.RawMessage(`250`), After: json.RawMessage(`270`)},
+	}, updated.Changes, "only changed fields are recorded")
+	assert.WithinDuration(t, time.Now(), updated.At, time.Minute)
+}
+
+func TestLoginsAreAudited(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "olga", "owner-pass", models.RoleOwner)
+	r := newTestRouter(t, h)
+
+	assert.Equal(t, http.StatusUnauthorized, login(t, h, "olga", "wrong").Code)
+	assert.Equal(t, http.StatusUnauthorized, login(t, h, "nobody", "wrong").Code)
+	owner := loginToken(t, h, "olga", "owner-pass")
+
+	entries := auditLog(t, r, owner, "?entity=session")
+	require.Len(t, entries, 3)
+	assert.Equal(t, models.AuditLogin, entries[0].Action)
+	assert.Equal(t, "olga", entries[0].Username)
+	assert.NotNil(t, entries[0].UserID)
+	assert.Equal(t, models.AuditLoginFailed, entries[1].Action)
+	assert.Equal(t, "nobody", entries[1].Username)
+	assert.Nil(t, entries[1].UserID, "unknown usernames belong to no user")
+	assert.Equal(t, models.AuditLoginFailed, entries[2].Action)
+	assert.Equal(t, entries[0].UserID, entries[2].UserID)
+
+	failed := auditLog(t, r, owner, "?action=login_failed&userId="+strconv.Itoa(*entries[0].UserID))
+	require.Len(t, failed, 1)
+	assert.Equal(t, entries[2].ID, failed[0].ID)
+	assert.Len(t, auditLog(t, r, owner, "?limit=1&before="+strconv.FormatInt(entries[0].ID, 10)), 1)
+}
+
+// cancellableAuditLog fails on a cancelled context, as a database write
+// does.
+type cancellableAuditLog struct {
+	*models.MemoryAuditRepository
+}
+
+func (l cancellableAuditLog) Record(ctx context.Context, entry models.AuditEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return l.MemoryAuditRepository.Record(ctx, entry)
+}
+
+func TestAuditSurvivesCancelledRequest(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "olga", "owner-pass", models.RoleOwner)
+	log := cancellableAuditLog{models.NewMemoryAuditRepository()}
+	h.Audit = log
+	menu := audit.NewMenuRepository(models.NewMemoryMenuRepository(), log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := menu.Create(ctx, models.MenuItem{Title: "Latte", Price: 250})
+	require.NoError(t, err)
+	body, _ := json.Marshal(handlers.Credentials{Username: "olga", Password: "owner-pass"})
+	rec := httptest.NewRecorder()
+	h.LoginHandler(rec, httptest.NewRequest(http.MethodPost, "/api/login", bytes.NewReader(body)).WithContext(ctx))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	entries, err := log.List(context.Background(), models.AuditQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, models.AuditLogin, entries[0].Action)
+	assert.Equal(t, models.AuditCreate, entries[1].Action)
+}
+
+func TestAuditLogDateRange(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "olga", "owner-pass", models.RoleOwner)
+	r := newTestRouter(t, h)
+	owner := loginToken(t, h, "olga", "owner-pass")
+
+	future := queryTime(time.Now().Add(time.Hour))
+	past := queryTime(time.Now().Add(-time.Hour))
+	assert.Len(t, auditLog(t, r, owner, "?from="+past+"&to="+future), 1)
+	assert.Empty(t, auditLog(t, r, owner, "?from="+future))
+	assert.Empty(t, auditLog(t, r, owner, "?to="+past))
+}
+
+func TestAuditLogRejectsInvalidQuery(t *testing.T) {
+	h := newTestHandler(t)
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/audit?entity=order&userId=x&from=yesterday&limit=1000", nil)
+	rec := httptest.NewRecorder()
+	h.GetAuditLogHandler(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	resp := decodeError[validate.Errors](t, rec)
+	assert.Equal(t, apierr.InvalidQuery, resp.Code)
+	fields := []string{}
+	for _, d := range resp.Details {
+		fields = append(fields, d.Field)
+	}
+	assert.ElementsMatch(t, []string{"entity", "userId", "from", "limit"}, fields)
+}
+
+func TestAuditLogIsForOwners(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "mila", "manager-pass", models.RoleMenuManager)
+	r := newTestRouter(t, h)
+	rec := staffRequest(t, r, loginToken(t, h, "mila", "manager-pass"), http.MethodGet, "/api/admin/audit", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+// queryTime formats t for a query string.
+func queryTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestUser(t *testing.T, h *handlers.Handler, username, password string, role models.Role) {
+	t.Helper()
+	hash, err := models.HashPassword(password)
+	require.NoError(t, err)
+	_, err = h.Users.Create(context.Background(), models.User{Username: username, PasswordHash: hash, Role: role})
+	require.NoError(t, err)
+}
+
+func login(t *testing.T, h *handlers.Handler, username, password string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(handlers.Credentials{Username: username, Password: password})
+	rec := httptest.NewRecorder()
+	h.LoginHandler(rec, httptest.NewRequest(http.MethodPost, "/api/login", bytes.NewReader(body)))
+	return rec
+}
+
+func loginToken(t *testing.T, h *handlers.Handler, username, password string) string {
+	t.Helper()
+	rec := login(t, h, username, password)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.NotEmpty(t, resp.Token)
+	return resp.Token
+}
+
+func TestLoginWithUsernameAndPassword(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "anna", "correct-horse", models.RoleEditor)
+
+	rec := login(t, h, "anna", "correct-horse")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "correct-horse")
+
+	assert.Equal(t, http.StatusUnauthorized, login(t, h, "anna", "wrong-password").Code)
+	assert.Equal(t, http.StatusUnauthorized, login(t, h, "nobody", "correct-horse").Code)
+}
+
+func TestRequireRole(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "barista", "barista-pass", models.RoleBarista)
+	createTestUser(t, h, "owner", "owner-pass1", models.RoleOwner)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	deleteRoute := h.JWTMiddleware(handlers.RequireRole(models.RoleMenuManager)(ok))
+	readRoute := h.JWTMiddleware(handlers.RequireRole(models.RoleMenuManager, models.RoleBarista)(ok))
+
+	call := func(route http.Handler, token string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/api/admin/menu/1", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		route.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	baristaToken := loginToken(t, h, "barista", "barista-pass")
+	ownerToken := loginToken(t, h, "owner", "owner-pass1")
+
+	assert.Equal(t, http.StatusUnauthorized, call(deleteRoute, ""))
+	assert.Equal(t, http.StatusForbidden, call(deleteRoute, baristaToken))
+	assert.Equal(t, http.StatusNoContent, call(readRoute, baristaToken))
+	assert.Equal(t, http.StatusNoContent, call(deleteRoute, ownerToken))
+}
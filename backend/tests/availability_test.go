@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staffRequest sends a request with a JSON body, signed in as token, through r.
+func staffRequest(t *testing.T, r *mux.Router, token, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func publicMenuTitles(t *testing.T, r *mux.Router) map[string]models.Availability {
+	t.Helper()
+	rec := staffRequest(t, r, "", http.MethodGet, "/api/menu", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page struct {
+		Items []models.MenuItem `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	titles := map[string]models.Availability{}
+	for _, item := range page.Items {
+		titles[item.Title] = item.Availability
+	}
+	return titles
+}
+
+func TestPublicMenuFollowsAvailability(t *testing.T) {
+	h := newTestHandler(t)
+	r := newTestRouter(t, h)
+	today := time.Now().UTC().Format(time.DateOnly)
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+
+	ctx := context.Background()
+	for _, item := range []models.MenuItem{
+		{Title: "Latte", Availability: models.Available},
+		{Title: "Croissant", Availability: models.SoldOut},
+		{Title: "Staff meal", Availability: models.Hidden},
+		{Title: "Pumpkin latte", Availability: models.Seasonal, SeasonStart: today},
+		{Title: "Summer lemonade", Availability: models.Seasonal, SeasonEnd: yesterday},
+		{Title: "Old tea"},
+	} {
+		item.Normalize()
+		_, err := h.Menu.Create(ctx, item)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, map[string]models.Availability{
+		"Latte":         models.Available,
+		"Croissant":     models.SoldOut,
+		"Pumpkin latte": models.Seasonal,
+		"Old tea":       models.Available,
+	}, publicMenuTitles(t, r))
+
+	rec := staffRequest(t, r, "", http.MethodGet, "/api/menu/3", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "hidden items are not served publicly")
+	rec = staffRequest(t, r, "", http.MethodGet, "/api/menu/5", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "out-of-season items are not served publicly")
+}
+
+func TestPublicMenuItemInHiddenCategory(t *testing.T) {
+	h := newTestHandler(t)
+	r := newTestRouter(t, h)
+	ctx := context.Background()
+	require.NoError(t, h.Categories.Create(ctx, models.Category{Slug: "winter", Name: "Winter", Visible: true}))
+	item := models.MenuItem{Title: "Mulled wine", Category: "winter"}
+	item.Normalize()
+	id, err := h.Menu.Create(ctx, item)
+	require.NoError(t, err)
+	target := "/api/menu/" + strconv.Itoa(id)
+
+	rec := staffRequest(t, r, "", http.MethodGet, target, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	require.NoError(t, h.Categories.Update(ctx, "winter", models.Category{Name: "Winter", Visible: false}))
+	assert.NotContains(t, publicMenuTitles(t, r), "Mulled wine")
+	rec = staffRequest(t, r, "", http.MethodGet, target, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "items of hidden categories are not served publicly")
+
+	require.NoError(t, h.Categories.Delete(ctx, "winter"))
+	rec = staffRequest(t, r, "", http.MethodGet, target, "")
+	assert.Equal(t, http.StatusOK, rec.Code, "items of deleted categories are listed like the public menu lists them")
+}
+
+func TestBaristaTogglesStopList(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "boris", "barista-pass", models.RoleBarista)
+	r := newTestRouter(t, h)
+	token := loginToken(t, h, "boris", "barista-pass")
+	id, err := h.Menu.Create(context.Background(), models.MenuItem{Title: "Croissant", Availability: models.Available})
+	require.NoError(t, err)
+	target := "/api/admin/menu/" + strconv.Itoa(id) + "/availability"
+
+	rec := staffRequest(t, r, token, http.MethodPut, target, `{"availability":"sold_out"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var item models.MenuItem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&item))
+	assert.Equal(t, models.SoldOut, item.Availability)
+	require.NotNil(t, item.SoldOutAt)
+	soldOutAt := *item.SoldOutAt
+
+	rec = staffRequest(t, r, token, http.MethodGet, "/api/admin/stop-list", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var stopList []models.MenuItem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&stopList))
+	require.Len(t, stopList, 1)
+	assert.Equal(t, "Croissant", stopList[0].Title)
+
+	// Marking it sold out again keeps the original time.
+	rec = staffRequest(t, r, token, http.MethodPut, target, `{"availability":"sold_out"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&item))
+	assert.True(t, soldOutAt.Equal(*item.SoldOutAt))
+
+	rec = staffRequest(t, r, token, http.MethodPut, target, `{"availability":"hidden"}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code, "hiding items is for menu managers")
+
+	rec = staffRequest(t, r, token, http.MethodPut, target, `{"availability":"available"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	item = models.MenuItem{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&item))
+	assert.Equal(t, models.Available, item.Availability)
+	assert.Nil(t, item.SoldOutAt)
+
+	hiddenID, err := h.Menu.Create(context.Background(), models.MenuItem{Title: "Secret latte", Availability: models.Hidden})
+	require.NoError(t, err)
+	hiddenTarget := "/api/admin/menu/" + strconv.Itoa(hiddenID) + "/availability"
+	for _, availability := range []string{"available", "sold_out"} {
+		rec = staffRequest(t, r, token, http.MethodPut, hiddenTarget, `{"availability":"`+availability+`"}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code, "publishing a hidden item is for menu managers")
+	}
+	hidden, err := h.Menu.GetByID(context.Background(), hiddenID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Hidden, hidden.Availability)
+}
+
+func TestAvailabilityValidation(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "mila", "manager-pass", models.RoleMenuManager)
+	r := newTestRouter(t, h)
+	token := loginToken(t, h, "mila", "manager-pass")
+	id, err := h.Menu.Create(context.Background(), models.MenuItem{Title: "Lemonade", Availability: models.Available})
+	require.NoError(t, err)
+	target := "/api/admin/menu/" + strconv.Itoa(id) + "/availability"
+
+	for body, field := range map[string]string{
+		`{}`:                          "availability",
+		`{"availability":"gone"}`:     "availability",
+		`{"availability":"seasonal"}`: "seasonStart",
+		`{"availability":"seasonal","seasonStart":"June"}`:                                "seasonStart",
+		`{"availability":"seasonal","seasonStart":"2025-08-31","seasonEnd":"2025-06-01"}`: "seasonEnd",
+	} {
+		rec := staffRequest(t, r, token, http.MethodPut, target, body)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code, body)
+		assert.Contains(t, rec.Body.String(), `"field":"`+field+`"`, body)
+	}
+
+	rec := staffRequest(t, r, token, http.MethodPut, target, `{"availability":"seasonal","seasonStart":"2025-06-01","seasonEnd":"2025-08-31"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var item models.MenuItem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&item))
+	assert.Equal(t, "2025-06-01", item.SeasonStart)
+	assert.Equal(t, "2025-08-31", item.SeasonEnd)
+	assert.True(t, item.ListedOn("2025-08-31"))
+	assert.False(t, item.ListedOn("2025-09-01"))
+}
+
+func TestResetSoldOutAtCafeMidnight(t *testing.T) {
+	h := newTestHandler(t)
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	h.Location = moscow
+
+	// 00:30 in Moscow is still the previous day in UTC.
+	now := time.Date(2025, 3, 2, 21, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 2, 21, 0, 0, 0, time.UTC), h.DayStart(now).UTC())
+
+	ctx := context.Background()
+	id, err := h.Menu.Create(ctx, models.MenuItem{Title: "Croissant", Availability: models.SoldOut})
+	require.NoError(t, err)
+	_, err = h.Menu.Create(ctx, models.MenuItem{Title: "Latte", Availability: models.Available})
+	require.NoError(t, err)
+
+	reset, err := h.Menu.ResetSoldOut(ctx, h.DayStart(time.Now()))
+	require.NoError(t, err)
+	assert.Zero(t, reset, "items sold out today stay on the stop-list")
+
+	reset, err = h.Menu.ResetSoldOut(ctx, time.Now().Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, reset)
+	item, err := h.Menu.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, models.Available, item.Availability)
+	assert.Nil(t, item.SoldOutAt)
+}
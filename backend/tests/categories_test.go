@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func categoryRequest(method, slug string, c any) *http.Request {
+	body, _ := json.Marshal(c)
+	req := httptest.NewRequest(method, "/api/admin/categories/"+slug, bytes.NewReader(body))
+	return mux.SetURLVars(req, map[string]string{"slug": slug})
+}
+
+func TestCategoryCRUD(t *testing.T) {
+	h := newTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	h.CreateCategoryHandler(rec, categoryRequest(http.MethodPost, "", map[string]any{"slug": "seasonal", "name": "Сезонное", "position": 5}))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var created models.Category
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	assert.True(t, created.Visible, "new categories are visible unless stated otherwise")
+
+	rec = httptest.NewRecorder()
+	h.CreateCategoryHandler(rec, categoryRequest(http.MethodPost, "", models.Category{Slug: "seasonal", Name: "Again"}))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	for _, bad := range []models.Category{{Slug: "Seasonal", Name: "x"}, {Slug: "winter"}} {
+		rec = httptest.NewRecorder()
+		h.CreateCategoryHandler(rec, categoryRequest(http.MethodPost, "", bad))
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, bad)
+	}
+
+	rec = httptest.NewRecorder()
+	h.UpdateCategoryHandler(rec, categoryRequest(http.MethodPut, "seasonal", models.Category{Name: "Зимнее меню", Position: 1}))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	updated, err := h.Categories.GetBySlug(context.Background(), "seasonal")
+	require.NoError(t, err)
+	assert.Equal(t, "Зимнее меню", updated.Name)
+	assert.False(t, updated.Visible)
+
+	rec = httptest.NewRecorder()
+	h.UpdateCategoryHandler(rec, categoryRequest(http.MethodPut, "seasonal", models.Category{Slug: "winter", Name: "x"}))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	_, err = h.Menu.Create(context.Background(), models.MenuItem{Title: "Glühwein", Price: 400, Category: "seasonal"})
+	require.NoError(t, err)
+	rec = httptest.NewRecorder()
+	h.DelCategoryHandler(rec, categoryRequest(http.MethodDelete, "seasonal", models.Category{}))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.DelCategoryHandler(rec, categoryRequest(http.MethodDelete, "missing", models.Category{}))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCategoryRenameKeepsVisibility(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	require.NoError(t, h.Categories.Create(ctx, models.Category{Slug: "coffee", Name: "Кофе", Position: 2, Visible: true}))
+	_, err := h.Menu.Create(ctx, models.MenuItem{Title: "Latte", Price: 250, Category: "coffee"})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.UpdateCategoryHandler(rec, categoryRequest(http.MethodPut, "coffee", map[string]any{"name": "Coffee"}))
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	updated, err := h.Categories.GetBySlug(ctx, "coffee")
+	require.NoError(t, err)
+	assert.Equal(t, "Coffee", updated.Name)
+	assert.Equal(t, 2, updated.Position)
+	assert.True(t, updated.Visible)
+	assert.Contains(t, publicMenuTitles(t, newTestRouter(t, h)), "Latte")
+
+	rec = httptest.NewRecorder()
+	h.UpdateCategoryHandler(rec, categoryRequest(http.MethodPut, "missing", map[string]any{"name": "Missing"}))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestMenuItemsNeedKnownCategory(t *testing.T) {
+	h := newTestHandler(t)
+	body, _ := json.Marshal(models.MenuItem{Title: "Latte", Price: 250, Category: "nonexistent"})
+	rec := httptest.NewRecorder()
+	h.CreateMenuItemHandler(rec, httptest.NewRequest(http.MethodPost, "/api/admin/menu", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, map[string]string{"category": "unknown"}, fieldCodes(t, rec))
+}
+
+func TestMenuGroupedByCategoryOrder(t *testing.T) {
+	h := newTestHandler(t)
+	seedMenu(t, h)
+	ctx := context.Background()
+	require.NoError(t, h.Categories.Update(ctx, "desserts", models.Category{Name: "Десерты", Position: -1, Visible: true}))
+	require.NoError(t, h.Categories.Update(ctx, "breakfast", models.Category{Name: "Завтрак", Position: 1}))
+
+	var seen []string
+	query := "limit=2"
+	for {
+		page, code := listMenu(t, h, query)
+		require.Equal(t, http.StatusOK, code)
+		seen = append(seen, titles(page.Items)...)
+		if page.NextCursor == "" {
+			break
+		}
+		query = "limit=2&cursor=" + page.NextCursor
+	}
+	assert.Equal(t, []string{"Cheesecake", "Tiramisu", "Espresso", "Cappuccino"}, seen, "desserts first, hidden breakfast left out")
+
+	rec := httptest.NewRecorder()
+	h.GetMenuHandler(rec, httptest.NewRequest(http.MethodGet, "/api/menu", nil))
+	var resp struct {
+		Categories []models.Category `json:"categories"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	var slugs []string
+	for _, c := range resp.Categories {
+		slugs = append(slugs, c.Slug)
+	}
+	assert.Equal(t, []string{"desserts", "drinks"}, slugs)
+}
+
+func TestStaffSeeHiddenCategories(t *testing.T) {
+	h := newTestHandler(t)
+	seedMenu(t, h)
+	require.NoError(t, h.Categories.Update(context.Background(), "breakfast", models.Category{Name: "Завтрак", Position: 1}))
+	createTestUser(t, h, "bob", "correct-horse", models.RoleBarista)
+	token := loginToken(t, h, "bob", "correct-horse")
+	r := newTestRouter(t, h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/menu", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var page models.MenuPage
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	assert.Contains(t, titles(page.Items), "Croissant")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/categories", nil))
+	var public []models.Category
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&public))
+	assert.Len(t, public, 2)
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conditionalGet serves a GET through r with the given request headers.
+func conditionalGet(r *mux.Router, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMenuListConditionalGet(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	require.NoError(t, h.Categories.Create(ctx, models.Category{Slug: "coffee", Name: "Кофе", Visible: true}))
+	id, err := h.Menu.Create(ctx, models.MenuItem{Title: "Latte", Price: 250, Category: "coffee", ImageURLs: []string{}})
+	require.NoError(t, err)
+	r := newTestRouter(t, h)
+
+	rec := conditionalGet(r, "/api/menu", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	tag := rec.Header().Get("ETag")
+	require.Regexp(t, `^"[0-9a-f]+"$`, tag)
+	assert.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+	lastModified := rec.Header().Get("Last-Modified")
+	require.NotEmpty(t, lastModified)
+
+	rec = conditionalGet(r, "/api/menu", map[string]string{"If-None-Match": tag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, tag, rec.Header().Get("ETag"))
+
+	rec = conditionalGet(r, "/api/menu", map[string]string{"If-None-Match": `"other", W/` + tag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = conditionalGet(r, "/api/menu", map[string]string{"If-Modified-Since": lastModified})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	// If-None-Match wins over If-Modified-Since.
+	rec = conditionalGet(r, "/api/menu", map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": lastModified})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Each page is its own resource.
+	rec = conditionalGet(r, "/api/menu?limit=1", map[string]string{"If-None-Match": tag})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, tag, rec.Header().Get("ETag"))
+
+	require.NoError(t, h.Menu.Update(ctx, id, models.MenuItem{Title: "Latte", Price: 260, Category: "coffee", ImageURLs: []string{}}))
+	rec = conditionalGet(r, "/api/menu", map[string]string{"If-None-Match": tag})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, tag, rec.Header().Get("ETag"))
+	tag = rec.Header().Get("ETag")
+
+	// Hiding a category changes what the public sees.
+	require.NoError(t, h.Categories.Update(ctx, "coffee", models.Category{Name: "Кофе", Visible: false}))
+	rec = conditionalGet(r, "/api/menu", map[string]string{"If-None-Match": tag})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	require.NoError(t, h.Menu.Delete(ctx, id))
+	rec = conditionalGet(r, "/api/menu", map[string]string{"If-None-Match": rec.Header().Get("ETag")})
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestNewsListConditionalGet(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	_, err := h.News.Create(ctx, models.News{Title: "Open", ImageURLs: []string{}, Status: models.NewsPublished, PostedAt: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	_, err = h.News.Create(ctx, models.News{Title: "Soon", ImageURLs: []string{}, Status: models.NewsScheduled, PostedAt: now.Add(150 * time.Millisecond)})
+	require.NoError(t, err)
+	r := newTestRouter(t, h)
+
+	rec := conditionalGet(r, "/api/news", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	tag := rec.Header().Get("ETag")
+	require.NotEmpty(t, tag)
+
+	rec = conditionalGet(r, "/api/news", map[string]string{"If-None-Match": tag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	// A scheduled post going live is a change even though nothing was saved.
+	time.Sleep(200 * time.Millisecond)
+	rec = conditionalGet(r, "/api/news", map[string]string{"If-None-Match": tag})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, tag, rec.Header().Get("ETag"))
+}
+
+func TestItemConditionalGet(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	id, err := h.Menu.Create(ctx, models.MenuItem{Title: "Tea", Price: 100, ImageURLs: []string{}})
+	require.NoError(t, err)
+	r := newTestRouter(t, h)
+
+	rec := conditionalGet(r, "/api/menu/1", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	tag := rec.Header().Get("ETag")
+
+	rec = conditionalGet(r, "/api/menu/1", map[string]string{"If-None-Match": tag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	require.NoError(t, h.Menu.Update(ctx, id, models.MenuItem{Title: "Tea", Price: 120, ImageURLs: []string{}}))
+	rec = conditionalGet(r, "/api/menu/1", map[string]string{"If-None-Match": tag})
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCacheControlIsConfigurableAndPrivateForStaff(t *testing.T) {
+	h := newTestHandler(t)
+	h.CacheControl = "public, max-age=300, stale-while-revalidate=600"
+	createTestUser(t, h, "barista", "barista-pass", models.RoleBarista)
+	r := newTestRouter(t, h)
+
+	rec := conditionalGet(r, "/api/menu", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "public, max-age=300, stale-while-revalidate=600", rec.Header().Get("Cache-Control"))
+
+	rec = conditionalGet(r, "/api/admin/menu", map[string]string{"Authorization": "Bearer " + loginToken(t, h, "barista", "barista-pass")})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "private, no-cache", rec.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// env is a fake environment for config.Load.
+type env map[string]string
+
+func (e env) get(key string) string { return e[key] }
+
+func writeConfigFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestConfigRefusesEmptyJWTSecret(t *testing.T) {
+	_, err := config.Load(nil, env{"POSTGRES_DSN": "postgres://db", "JWT_SECRET": "   "}.get)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "JWT_SECRET: required")
+}
+
+func TestConfigReportsEveryProblem(t *testing.T) {
+	_, err := config.Load([]string{"-read-cache-ttl", "soon"}, env{"CAFE_TIMEZONE": "Mars/Olympus"}.get)
+	require.Error(t, err)
+	for _, key := range []string{"POSTGRES_DSN", "JWT_SECRET", "READ_CACHE_TTL", "CAFE_TIMEZONE"} {
+		assert.Contains(t, err.Error(), key+":")
+	}
+}
+
+func TestConfigLayersFileEnvironmentAndFlags(t *testing.T) {
+	dir := t.TempDir()
+	file := writeConfigFile(t, dir, "cafe.env", "POSTGRES_DSN=postgres://file\nJWT_SECRET=from-file\nPORT=7000\nREAD_CACHE_TTL=1m\nVITE_API_URL=ignored\n")
+
+	cfg, err := config.Load(
+		[]string{"-config", file, "-port", "9000", "migrate", "up"},
+		env{"PORT": "8000", "READ_CACHE_TTL": "0", "CAFE_TIMEZONE": "Asia/Yekaterinburg"}.get,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, config.Development, cfg.Profile)
+	assert.Equal(t, []string{file}, cfg.Files)
+	assert.Equal(t, "postgres://file", cfg.Database.DSN)
+	assert.Equal(t, []byte("from-file"), cfg.Auth.JWTSecret)
+	assert.Equal(t, "9000", cfg.Server.Port, "flags win over the environment")
+	assert.Equal(t, time.Duration(0), cfg.Cache.ReadTTL, "the environment wins over the file")
+	assert.Equal(t, "Asia/Yekaterinburg", cfg.Cafe.Timezone.String())
+	assert.Equal(t, "http://localhost:9000", cfg.Storage.PublicURL)
+	assert.Equal(t, []string{"migrate", "up"}, cfg.Args)
+}
+
+func TestConfigProductionProfile(t *testing.T) {
+	dir := t.TempDir()
+	file := writeConfigFile(t, dir, ".env", "POSTGRES_DSN=postgres://db\nJWT_SECRET=short\n")
+
+	_, err := config.Load([]string{"-config", file}, env{"CAFE_ENV": "production"}.get)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "JWT_SECRET: must be at least 32 bytes")
+	assert.Contains(t, err.Error(), "PUBLIC_URL: required")
+
+	writeConfigFile(t, dir, ".env.production", "JWT_SECRET=0123456789abcdef0123456789abcdef\nPUBLIC_URL=https://cafe.example\nCORS_ORIGINS=https://cafe.example, https://admin.cafe.example\n")
+	cfg, err := config.Load([]string{"-config", file}, env{"CAFE_ENV": "production"}.get)
+	require.NoError(t, err)
+	assert.Equal(t, config.Production, cfg.Profile)
+	assert.Equal(t, []string{file, file + ".production"}, cfg.Files)
+	assert.Equal(t, []string{"https://cafe.example", "https://admin.cafe.example"}, cfg.Server.CORSOrigins)
+
+	cfg, err = config.Load([]string{"-config", file, "-jwt-secret", "0123456789abcdef0123456789abcdef"}, env{}.get)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://localhost:5173"}, cfg.Server.CORSOrigins, "development allows the Vite dev server")
+
+	_, err = config.Load([]string{"-profile", "staging"}, env{}.get)
+	assert.ErrorContains(t, err, `unknown profile "staging"`)
+}
+
+func TestConfigRequiresNamedFileToExist(t *testing.T) {
+	_, err := config.Load([]string{"-config", filepath.Join(t.TempDir(), "missing.env")}, env{}.get)
+	assert.ErrorContains(t, err, "config file")
+}
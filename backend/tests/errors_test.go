@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/internal/validate"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errorEnvelope[D any] struct {
+	Code      apierr.Code `json:"code"`
+	Message   string      `json:"message"`
+	RequestID string      `json:"requestId"`
+	Details   D           `json:"details"`
+}
+
+// decodeError decodes an error response body, checking the parts every
+// error shares.
+func decodeError[D any](t *testing.T, rec *httptest.ResponseRecorder) errorEnvelope[D] {
+	t.Helper()
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var body struct {
+		Error errorEnvelope[D] `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.NotEmpty(t, body.Error.Message)
+	assert.NotEmpty(t, body.Error.RequestID)
+	assert.Equal(t, rec.Header().Get("X-Request-ID"), body.Error.RequestID)
+	return body.Error
+}
+
+func TestSentinelErrorsMapToCodes(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/menu/42", nil), map[string]string{"id": "42"})
+	rec := httptest.NewRecorder()
+	h.GetMenuItemHandler(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, apierr.MenuItemNotFound, decodeError[any](t, rec).Code)
+
+	req = mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/api/admin/news/7", nil), map[string]string{"id": "7"})
+	rec = httptest.NewRecorder()
+	h.DelNewsHandler(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, apierr.NewsNotFound, decodeError[any](t, rec).Code)
+
+	req = mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/news/abc", nil), map[string]string{"id": "abc"})
+	rec = httptest.NewRecorder()
+	h.GetNewsByIdHandler(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, apierr.InvalidID, decodeError[any](t, rec).Code)
+
+	rec = httptest.NewRecorder()
+	h.CreateMenuItemHandler(rec, httptest.NewRequest(http.MethodPost, "/api/admin/menu", bytes.NewReader([]byte(`{"title":`))))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, apierr.InvalidJSON, decodeError[any](t, rec).Code)
+}
+
+func TestErrorMessagesFollowAcceptLanguage(t *testing.T) {
+	h := newTestHandler(t)
+	entry := apierr.Lookup(apierr.MenuItemNotFound)
+
+	for lang, want := range map[string]string{
+		"":                      entry.Messages["ru"],
+		"en-US,en;q=0.9":        entry.Messages["en"],
+		"de-DE, ru;q=0.8":       entry.Messages["ru"],
+		"fr;q=0.9, en-GB;q=0.8": entry.Messages["en"],
+	} {
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/menu/1", nil), map[string]string{"id": "1"})
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		h.GetMenuItemHandler(rec, req)
+		assert.Equal(t, want, decodeError[any](t, rec).Message, lang)
+	}
+}
+
+func TestRequestIDIsEchoedInErrors(t *testing.T) {
+	h := newTestHandler(t)
+	r := newTestRouter(t, h)
+	r.Use(handlers.RequestID)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/menu/404", nil)
+	req.Header.Set("X-Request-ID", "client-req-1")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "client-req-1", decodeError[any](t, rec).RequestID)
+
+	// Malformed IDs are replaced rather than echoed into logs and headers.
+	req = httptest.NewRequest(http.MethodGet, "/api/menu/404", nil)
+	req.Header.Set("X-Request-ID", "bad id\r\n")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.NotEqual(t, "bad id\r\n", decodeError[any](t, rec).RequestID)
+}
+
+func TestRouterErrorsUseEnvelope(t *testing.T) {
+	r := newTestRouter(t, newTestHandler(t))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/nope", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, apierr.RouteNotFound, decodeError[any](t, rec).Code)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/menu/1", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, apierr.MethodNotAllowed, decodeError[any](t, rec).Code)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/admin/menu", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, apierr.MissingToken, decodeError[any](t, rec).Code)
+}
+
+func TestInvalidQueryListsParameters(t *testing.T) {
+	h := newTestHandler(t)
+	rec := httptest.NewRecorder()
+	h.GetMenuHandler(rec, httptest.NewRequest(http.MethodGet, "/api/menu?sort=colour&limit=0&minPrice=x", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	resp := decodeError[validate.Errors](t, rec)
+	assert.Equal(t, apierr.InvalidQuery, resp.Code)
+	var fields []string
+	for _, fe := range resp.Details {
+		fields = append(fields, fe.Field)
+	}
+	assert.ElementsMatch(t, []string{"sort", "limit", "minPrice"}, fields)
+}
+
+func TestCatalogueIsComplete(t *testing.T) {
+	seen := map[apierr.Code]bool{}
+	for _, e := range apierr.Catalogue {
+		assert.False(t, seen[e.Code], "duplicate code %s", e.Code)
+		seen[e.Code] = true
+		assert.GreaterOrEqual(t, e.Status, 400, e.Code)
+		for _, lang := range apierr.Languages {
+			assert.NotEmpty(t, e.Messages[lang], "%s has no %s message", e.Code, lang)
+		}
+	}
+}
+
+// The frontend's error types are generated from the catalogue; run
+// go generate ./internal/apierr after changing it.
+func TestGeneratedTypeScriptIsCurrent(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, apierr.WriteTypeScript(&buf))
+	current, err := os.ReadFile("../../frontend/src/errorCodes.ts")
+	require.NoError(t, err)
+	assert.Equal(t, buf.String(), string(current), "frontend/src/errorCodes.ts is out of date")
+}
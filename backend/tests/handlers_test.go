@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/audit"
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/internal/storage"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUploadsURL = "http://cafe.test/uploads"
+
+func newTestHandler(t *testing.T) *handlers.Handler {
+	t.Helper()
+	store, err := storage.NewLocal(t.TempDir(), testUploadsURL)
+	require.NoError(t, err)
+	auditLog := models.NewMemoryAuditRepository()
+	h := handlers.New(
+		audit.NewMenuRepository(models.NewMemoryMenuRepository(), auditLog),
+		models.NewMemoryCategoryRepository(),
+		audit.NewNewsRepository(models.NewMemoryNewsRepository(), auditLog),
+		models.NewMemoryUserRepository(),
+		models.NewMemoryTokenRepository(),
+		auditLog,
+		store,
+	)
+	h.JWTSecret = []byte("test-secret")
+	return h
+}
+
+func TestCreateAndGetMenuItemHandler(t *testing.T) {
+	h := newTestHandler(t)
+	require.NoError(t, h.Categories.Create(context.Background(), models.Category{Slug: "coffee", Name: "Кофе", Visible: true}))
+
+	body, _ := json.Marshal(models.MenuItem{Title: "Latte", Price: 250, ImageURLs: []string{"http://example.com/latte.jpg"}, Category: "coffee"})
+	rec := httptest.NewRecorder()
+	h.CreateMenuItemHandler(rec, httptest.NewRequest(http.MethodPost, "/api/admin/menu", bytes.NewReader(body)))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created models.MenuItem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	assert.Equal(t, "Latte", created.Title)
+	assert.NotZero(t, created.ID)
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/menu/1", nil), map[string]string{"id": "1"})
+	rec = httptest.NewRecorder()
+	h.GetMenuItemHandler(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var fetched models.MenuItem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&fetched))
+	assert.Equal(t, created.ID, fetched.ID)
+	assert.Equal(t, 250, fetched.Price)
+}
+
+func TestMenuItemHandlerNotFound(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/menu/42", nil), map[string]string{"id": "42"})
+	rec := httptest.NewRecorder()
+	h.GetMenuItemHandler(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	req = mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/api/admin/menu/42", nil), map[string]string{"id": "42"})
+	rec = httptest.NewRecorder()
+	h.DelMenuItemHandler(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	req = mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/menu/abc", nil), map[string]string{"id": "abc"})
+	rec = httptest.NewRecorder()
+	h.GetMenuItemHandler(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestUpdateAndDeleteMenuItemHandler(t *testing.T) {
+	h := newTestHandler(t)
+	id, err := h.Menu.Create(context.Background(), models.MenuItem{Title: "Tea", Price: 100, ImageURLs: []string{}})
+	require.NoError(t, err)
+
+	body, _ := json.Marshal(models.MenuItem{Title: "Green Tea", Price: 120, ImageURLs: []string{}})
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPut, "/api/admin/menu/1", bytes.NewReader(body)), map[string]string{"id": "1"})
+	rec := httptest.NewRecorder()
+	h.UpdateMenuHandler(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	item, err := h.Menu.GetByID(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "Green Tea", item.Title)
+	assert.Equal(t, 120, item.Price)
+
+	req = mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/api/admin/menu/1", nil), map[string]string{"id": "1"})
+	rec = httptest.NewRecorder()
+	h.DelMenuItemHandler(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	_, err = h.Menu.GetByID(context.Background(), id)
+	assert.Equal(t, models.ErrMenuItemNotFound, err)
+}
+
+func TestGetNewsHandlerOrdersByPostedAt(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	_, err := h.News.Create(ctx, models.News{Title: "Older", ImageURLs: []string{}, Status: models.NewsPublished, PostedAt: now.Add(-48 * time.Hour)})
+	require.NoError(t, err)
+	_, err = h.News.Create(ctx, models.News{Title: "Newer", ImageURLs: []string{}, Status: models.NewsPublished, PostedAt: now.Add(-time.Hour)})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.GetNewsHandler(rec, httptest.NewRequest(http.MethodGet, "/api/news", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var news []models.News
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&news))
+	require.Len(t, news, 2)
+	assert.Equal(t, "Newer", news[0].Title)
+	assert.Equal(t, "Older", news[1].Title)
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type healthBody struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func probe(t *testing.T, handler http.HandlerFunc, path string) (int, healthBody) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var body healthBody
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	return rec.Code, body
+}
+
+func TestHealthzAlwaysAnswers(t *testing.T) {
+	h := newTestHandler(t)
+	h.ReadinessChecks = []handlers.ReadinessCheck{
+		{Name: "database", Check: func(context.Context) error { return errors.New("down") }},
+	}
+	code, body := probe(t, h.HealthzHandler, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body.Status)
+}
+
+func TestReadyzRunsChecks(t *testing.T) {
+	h := newTestHandler(t)
+	var migrationsErr error
+	h.ReadinessChecks = []handlers.ReadinessCheck{
+		{Name: "database", Check: func(ctx context.Context) error {
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline, "checks must be bounded")
+			return nil
+		}},
+		{Name: "migrations", Check: func(context.Context) error { return migrationsErr }},
+	}
+
+	code, body := probe(t, h.ReadyzHandler, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, healthBody{Status: "ok", Checks: map[string]string{"database": "ok", "migrations": "ok"}}, body)
+
+	migrationsErr = errors.New("pending migrations: [9]")
+	code, body = probe(t, h.ReadyzHandler, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, healthBody{Status: "unavailable", Checks: map[string]string{"database": "ok", "migrations": "failing"}}, body)
+}
+
+func TestReadyzFailsWhileDraining(t *testing.T) {
+	h := newTestHandler(t)
+	h.Drain()
+	code, body := probe(t, h.ReadyzHandler, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "shutting_down", body.Status)
+
+	code, _ = probe(t, h.HealthzHandler, "/healthz")
+	assert.Equal(t, http.StatusOK, code, "liveness is unaffected by draining")
+}
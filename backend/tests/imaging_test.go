@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/andrey-918/cafe-between/internal/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jpegBytes(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+// withOrientation inserts an EXIF APP1 segment carrying the given
+// orientation right after the JPEG SOI marker.
+func withOrientation(data []byte, orientation byte) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // big-endian header, IFD0 at offset 8
+		0, 1, // one entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, // orientation, SHORT, count 1
+		0, 0, 0, 0, // no next IFD
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	size := len(payload) + 2
+	segment := append([]byte{0xFF, 0xE1, byte(size >> 8), byte(size)}, payload...)
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func decodeSize(t *testing.T, data []byte) (int, int) {
+	t.Helper()
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	return cfg.Width, cfg.Height
+}
+
+func TestProcessRendersAllVariants(t *testing.T) {
+	renditions, full, err := imaging.Process("abc123", jpegBytes(t, 800, 400))
+	require.NoError(t, err)
+	assert.Equal(t, "images/abc123_800/full.jpg", full)
+	require.Len(t, renditions, len(imaging.Variants)*2)
+
+	byKey := map[string]imaging.Rendition{}
+	for _, r := range renditions {
+		byKey[r.Key] = r
+	}
+	assert.ElementsMatch(t, imaging.Keys(full), keys(renditions))
+
+	w, h := decodeSize(t, byKey["images/abc123_800/thumb.jpg"].Data)
+	assert.Equal(t, 160, w)
+	assert.Equal(t, 80, h)
+	w, _ = decodeSize(t, byKey["images/abc123_800/card.webp"].Data)
+	assert.Equal(t, 480, w)
+	// Smaller than the full-size target, so it is not upscaled.
+	w, _ = decodeSize(t, byKey["images/abc123_800/full.jpg"].Data)
+	assert.Equal(t, 800, w)
+	assert.Equal(t, "image/webp", byKey["images/abc123_800/full.webp"].ContentType)
+}
+
+func keys(renditions []imaging.Rendition) []string {
+	var out []string
+	for _, r := range renditions {
+		out = append(out, r.Key)
+	}
+	return out
+}
+
+func TestProcessAppliesExifOrientation(t *testing.T) {
+	renditions, full, err := imaging.Process("rot", withOrientation(jpegBytes(t, 300, 100), 6))
+	require.NoError(t, err)
+	assert.Equal(t, "images/rot_100/full.jpg", full)
+	for _, r := range renditions {
+		if r.Key == full {
+			w, h := decodeSize(t, r.Data)
+			assert.Equal(t, 100, w)
+			assert.Equal(t, 300, h)
+		}
+	}
+}
+
+func TestProcessRejectsGarbage(t *testing.T) {
+	_, _, err := imaging.Process("bad", []byte("\xff\xd8\xffnot really a jpeg"))
+	assert.Error(t, err)
+}
+
+func TestResponsiveImageSet(t *testing.T) {
+	base := "http://cafe.test/uploads/"
+	keyFor := func(url string) (string, bool) {
+		if len(url) > len(base) && url[:len(base)] == base {
+			return url[len(base):], true
+		}
+		return "", false
+	}
+	urlFor := func(key string) string { return base + key }
+
+	set := imaging.Responsive(base+"images/abc_300/full.jpg", keyFor, urlFor)
+	require.Len(t, set.Variants, 3)
+	assert.Equal(t, 160, set.Variants[0].Width)
+	assert.Equal(t, 300, set.Variants[1].Width, "card is capped at the source width")
+	assert.Equal(t, base+"images/abc_300/card.webp", set.Variants[1].WebP)
+	assert.Equal(t, base+"images/abc_300/thumb.webp 160w, "+base+"images/abc_300/card.webp 300w, "+base+"images/abc_300/full.webp 300w", set.SrcSet["webp"])
+
+	plain := imaging.Responsive("http://elsewhere.test/a.jpg", keyFor, urlFor)
+	assert.Equal(t, "http://elsewhere.test/a.jpg", plain.Src)
+	assert.Empty(t, plain.Variants)
+	assert.Equal(t, []string{"images/legacy.png"}, imaging.Keys("images/legacy.png"))
+}
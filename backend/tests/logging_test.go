@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/internal/logging"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs routes the default logger into a buffer for the test and
+// returns a function decoding the JSON records written so far.
+func captureLogs(t *testing.T) func() []map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return func() []map[string]any {
+		var records []map[string]any
+		scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+		for scanner.Scan() {
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), scanner.Text())
+			records = append(records, record)
+		}
+		return records
+	}
+}
+
+func accessRecords(records []map[string]any) []map[string]any {
+	var access []map[string]any
+	for _, r := range records {
+		if r["msg"] == "request" {
+			access = append(access, r)
+		}
+	}
+	return access
+}
+
+func TestAccessLogRecordsRequestAndUser(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "editor", "editor-pass", models.RoleEditor)
+	token := loginToken(t, h, "editor", "editor-pass")
+	server := handlers.RequestID(handlers.AccessLog(newTestRouter(t, h)))
+	logs := captureLogs(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/news?status=draft", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Request-ID", "req-42")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/nowhere", nil))
+
+	access := accessRecords(logs())
+	require.Len(t, access, 2)
+	first := access[0]
+	assert.Equal(t, "INFO", first["level"])
+	assert.Equal(t, "req-42", first["request_id"])
+	assert.Equal(t, "GET", first["method"])
+	assert.Equal(t, "/api/admin/news", first["route"])
+	assert.Equal(t, "/api/admin/news", first["path"])
+	assert.Equal(t, float64(http.StatusOK), first["status"])
+	assert.Equal(t, float64(rec.Body.Len()), first["bytes"])
+	assert.Equal(t, "editor", first["user"])
+	assert.Contains(t, first, "duration_ms")
+
+	unmatched := access[1]
+	assert.Equal(t, "", unmatched["route"])
+	assert.Equal(t, float64(http.StatusNotFound), unmatched["status"])
+	assert.NotEmpty(t, unmatched["request_id"])
+	assert.NotContains(t, unmatched, "user")
+}
+
+func TestProbesLogAtDebugLevel(t *testing.T) {
+	h := newTestHandler(t)
+	server := handlers.RequestID(handlers.AccessLog(newTestRouter(t, h)))
+	logs := captureLogs(t)
+
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	access := accessRecords(logs())
+	require.Len(t, access, 1)
+	assert.Equal(t, "DEBUG", access[0]["level"])
+}
+
+func TestInternalErrorsAreLoggedWithRequestID(t *testing.T) {
+	h := newTestHandler(t)
+	h.Menu = failingMenuRepository{h.Menu}
+	server := handlers.RequestID(handlers.AccessLog(newTestRouter(t, h)))
+	logs := captureLogs(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/menu/1", nil)
+	req.Header.Set("X-Request-ID", "req-500")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	var failure, access map[string]any
+	for _, r := range logs() {
+		switch r["msg"] {
+		case "request failed":
+			failure = r
+		case "request":
+			access = r
+		}
+	}
+	require.NotNil(t, failure)
+	assert.Equal(t, "ERROR", failure["level"])
+	assert.Equal(t, "req-500", failure["request_id"])
+	assert.Equal(t, "connection reset", failure["err"])
+	require.NotNil(t, access)
+	assert.Equal(t, "ERROR", access["level"])
+}
+
+func TestLogFieldsAreSharedAlongTheRequest(t *testing.T) {
+	ctx := logging.With(context.Background(), slog.String("request_id", "r1"))
+	logging.With(ctx, slog.String("user", "barista"))
+	assert.Equal(t, []slog.Attr{slog.String("request_id", "r1"), slog.String("user", "barista")}, logging.Attrs(ctx))
+	assert.Empty(t, logging.Attrs(context.Background()))
+}
+
+var errConnectionReset = errors.New("connection reset")
+
+// failingMenuRepository fails every lookup as a broken connection would.
+type failingMenuRepository struct {
+	models.MenuRepository
+}
+
+func (failingMenuRepository) GetByID(context.Context, int) (models.MenuItem, error) {
+	return models.MenuItem{}, errConnectionReset
+}
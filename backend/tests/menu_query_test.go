@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedMenu(t *testing.T, h *handlers.Handler) {
+	t.Helper()
+	for i, slug := range []string{"drinks", "breakfast", "desserts"} {
+		require.NoError(t, h.Categories.Create(context.Background(), models.Category{Slug: slug, Name: slug, Position: i, Visible: true}))
+	}
+	items := []models.MenuItem{
+		{Title: "Espresso", Price: 150, Calories: 5, Category: "drinks", Description: "Short and strong"},
+		{Title: "Cappuccino", Price: 220, Calories: 120, Category: "drinks", Description: "Espresso with milk foam"},
+		{Title: "Cheesecake", Price: 350, Calories: 420, Category: "desserts"},
+		{Title: "Croissant", Price: 180, Calories: 260, Category: "breakfast", Description: "Butter 100%"},
+		{Title: "Tiramisu", Price: 380, Calories: 450, Category: "desserts", Description: "With espresso"},
+	}
+	for _, item := range items {
+		_, err := h.Menu.Create(context.Background(), item)
+		require.NoError(t, err)
+	}
+}
+
+func listMenu(t *testing.T, h *handlers.Handler, query string) (models.MenuPage, int) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.GetMenuHandler(rec, httptest.NewRequest(http.MethodGet, "/api/menu?"+query, nil))
+	var page models.MenuPage
+	if rec.Code == http.StatusOK {
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	}
+	return page, rec.Code
+}
+
+func titles(items []models.MenuItem) []string {
+	out := []string{}
+	for _, item := range items {
+		out = append(out, item.Title)
+	}
+	return out
+}
+
+func TestMenuFilters(t *testing.T) {
+	h := newTestHandler(t)
+	seedMenu(t, h)
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"Espresso", "Cappuccino", "Croissant", "Cheesecake", "Tiramisu"}},
+		{"category=desserts", []string{"Cheesecake", "Tiramisu"}},
+		{"minPrice=180&maxPrice=350", []string{"Cappuccino", "Croissant", "Cheesecake"}},
+		{"maxCalories=260", []string{"Espresso", "Cappuccino", "Croissant"}},
+		{"q=ESPRESSO", []string{"Espresso", "Cappuccino", "Tiramisu"}},
+		{"q=100%25", []string{"Croissant"}},
+		{"category=drinks&q=milk", []string{"Cappuccino"}},
+		{"sort=price", []string{"Espresso", "Croissant", "Cappuccino", "Cheesecake", "Tiramisu"}},
+		{"sort=-price", []string{"Tiramisu", "Cheesecake", "Cappuccino", "Croissant", "Espresso"}},
+		{"sort=title", []string{"Cappuccino", "Cheesecake", "Croissant", "Espresso", "Tiramisu"}},
+		{"sort=-created", []string{"Tiramisu", "Croissant", "Cheesecake", "Cappuccino", "Espresso"}},
+	}
+	for _, tc := range cases {
+		page, code := listMenu(t, h, tc.query)
+		require.Equal(t, http.StatusOK, code, tc.query)
+		assert.Equal(t, tc.want, titles(page.Items), tc.query)
+		assert.Empty(t, page.NextCursor, tc.query)
+	}
+}
+
+func TestMenuCursorPagination(t *testing.T) {
+	h := newTestHandler(t)
+	seedMenu(t, h)
+
+	var seen []string
+	query := "sort=price&limit=2"
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "pagination should terminate")
+		page, code := listMenu(t, h, query)
+		require.Equal(t, http.StatusOK, code)
+		assert.LessOrEqual(t, len(page.Items), 2)
+		seen = append(seen, titles(page.Items)...)
+		if page.NextCursor == "" {
+			break
+		}
+		query = "sort=price&limit=2&cursor=" + page.NextCursor
+	}
+	assert.Equal(t, []string{"Espresso", "Croissant", "Cappuccino", "Cheesecake", "Tiramisu"}, seen)
+}
+
+func TestMenuQueryValidation(t *testing.T) {
+	h := newTestHandler(t)
+	seedMenu(t, h)
+
+	page, _ := listMenu(t, h, "sort=price&limit=1")
+	require.NotEmpty(t, page.NextCursor)
+
+	for _, query := range []string{
+		"sort=calories",
+		"limit=0",
+		"limit=1000",
+		"minPrice=abc",
+		"maxCalories=-1",
+		"minPrice=300&maxPrice=100",
+		"cursor=not-a-cursor",
+		"sort=title&cursor=" + page.NextCursor,
+	} {
+		_, code := listMenu(t, h, query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/andrey-918/cafe-between/internal/database"
 	"github.com/andrey-918/cafe-between/models"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
@@ -30,10 +29,8 @@ func TestCreateMenuItem(t *testing.T) {
 	pool := setupTestDB(t)
 	defer pool.Close()
 
-	// Override the global pool for testing
-	originalPool := database.Pool
-	database.Pool = pool
-	defer func() { database.Pool = originalPool }()
+	repo := models.NewPostgresMenuRepository(pool)
+	ctx := context.Background()
 
 	item := models.MenuItem{
 		Title:       "Test Dish",
@@ -43,12 +40,12 @@ func TestCreateMenuItem(t *testing.T) {
 		Description: "A test dish",
 	}
 
-	id, err := models.CreateMenuItem(item)
+	id, err := repo.Create(ctx, item)
 	assert.NoError(t, err)
 	assert.Greater(t, id, 0)
 
 	// Verify the item was created
-	retrieved, err := models.GetMenuItemByID(id)
+	retrieved, err := repo.GetByID(ctx, id)
 	assert.NoError(t, err)
 	assert.Equal(t, item.Title, retrieved.Title)
 	assert.Equal(t, item.Price, retrieved.Price)
@@ -60,9 +57,8 @@ func TestGetMenuItemByID(t *testing.T) {
 	pool := setupTestDB(t)
 	defer pool.Close()
 
-	originalPool := database.Pool
-	database.Pool = pool
-	defer func() { database.Pool = originalPool }()
+	repo := models.NewPostgresMenuRepository(pool)
+	ctx := context.Background()
 
 	// Create a test item first
 	item := models.MenuItem{
@@ -73,16 +69,16 @@ func TestGetMenuItemByID(t *testing.T) {
 		Description: "A test dish",
 	}
 
-	id, err := models.CreateMenuItem(item)
+	id, err := repo.Create(ctx, item)
 	require.NoError(t, err)
 
-	retrieved, err := models.GetMenuItemByID(id)
+	retrieved, err := repo.GetByID(ctx, id)
 	assert.NoError(t, err)
 	assert.Equal(t, id, retrieved.ID)
 	assert.Equal(t, item.Title, retrieved.Title)
 
 	// Test non-existent item
-	_, err = models.GetMenuItemByID(99999)
+	_, err = repo.GetByID(ctx, 99999)
 	assert.Equal(t, models.ErrMenuItemNotFound, err)
 }
 
@@ -90,12 +86,11 @@ func TestGetMenu(t *testing.T) {
 	pool := setupTestDB(t)
 	defer pool.Close()
 
-	originalPool := database.Pool
-	database.Pool = pool
-	defer func() { database.Pool = originalPool }()
+	repo := models.NewPostgresMenuRepository(pool)
+	ctx := context.Background()
 
 	// Clear existing items
-	_, _ = pool.Exec(context.Background(), "DELETE FROM menu")
+	_, _ = pool.Exec(ctx, "DELETE FROM menu")
 
 	// Create test items
 	items := []models.MenuItem{
@@ -104,24 +99,43 @@ func TestGetMenu(t *testing.T) {
 	}
 
 	for _, item := range items {
-		_, err := models.CreateMenuItem(item)
+		_, err := repo.Create(ctx, item)
 		require.NoError(t, err)
 	}
 
-	menu, err := models.GetMenu()
+	page, err := repo.List(ctx, models.MenuQuery{})
 	assert.NoError(t, err)
-	assert.Len(t, menu, 2)
-	assert.Equal(t, "Dish 1", menu[0].Title)
-	assert.Equal(t, "Dish 2", menu[1].Title)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "Dish 1", page.Items[0].Title)
+	assert.Equal(t, "Dish 2", page.Items[1].Title)
+
+	maxPrice := 150
+	page, err = repo.List(ctx, models.MenuQuery{MaxPrice: &maxPrice, Search: "dish"})
+	assert.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "Dish 1", page.Items[0].Title)
+
+	page, err = repo.List(ctx, models.MenuQuery{Sort: models.MenuSortPriceDesc, Limit: 1})
+	assert.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "Dish 2", page.Items[0].Title)
+	require.NotEmpty(t, page.NextCursor)
+
+	cursor, err := models.DecodeMenuCursor(page.NextCursor, models.MenuSortPriceDesc)
+	require.NoError(t, err)
+	page, err = repo.List(ctx, models.MenuQuery{Sort: models.MenuSortPriceDesc, Limit: 1, Cursor: cursor})
+	assert.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "Dish 1", page.Items[0].Title)
+	assert.Empty(t, page.NextCursor)
 }
 
 func TestUpdateMenuItem(t *testing.T) {
 	pool := setupTestDB(t)
 	defer pool.Close()
 
-	originalPool := database.Pool
-	database.Pool = pool
-	defer func() { database.Pool = originalPool }()
+	repo := models.NewPostgresMenuRepository(pool)
+	ctx := context.Background()
 
 	// Create a test item
 	item := models.MenuItem{
@@ -132,11 +146,11 @@ func TestUpdateMenuItem(t *testing.T) {
 		Description: "Original description",
 	}
 
-	id, err := models.CreateMenuItem(item)
+	id, err := repo.Create(ctx, item)
 	require.NoError(t, err)
 
 	// Store original createdAt and updatedAt
-	original, err := models.GetMenuItemByID(id)
+	original, err := repo.GetByID(ctx, id)
 	require.NoError(t, err)
 	originalCreatedAt := original.CreatedAt
 	originalUpdatedAt := original.UpdatedAt
@@ -153,11 +167,11 @@ func TestUpdateMenuItem(t *testing.T) {
 		Description: "Updated description",
 	}
 
-	err = models.UpdateMenuItem(id, updatedItem)
+	err = repo.Update(ctx, id, updatedItem)
 	assert.NoError(t, err)
 
 	// Verify the update
-	retrieved, err := models.GetMenuItemByID(id)
+	retrieved, err := repo.GetByID(ctx, id)
 	assert.NoError(t, err)
 	assert.Equal(t, "Updated Dish", retrieved.Title)
 	assert.Equal(t, 150, retrieved.Price)
@@ -171,9 +185,8 @@ func TestDelMenuItem(t *testing.T) {
 	pool := setupTestDB(t)
 	defer pool.Close()
 
-	originalPool := database.Pool
-	database.Pool = pool
-	defer func() { database.Pool = originalPool }()
+	repo := models.NewPostgresMenuRepository(pool)
+	ctx := context.Background()
 
 	// Create a test item
 	item := models.MenuItem{
@@ -184,18 +197,18 @@ func TestDelMenuItem(t *testing.T) {
 		Description: "Will be deleted",
 	}
 
-	id, err := models.CreateMenuItem(item)
+	id, err := repo.Create(ctx, item)
 	require.NoError(t, err)
 
 	// Delete the item
-	err = models.DelMenuItem(id)
+	err = repo.Delete(ctx, id)
 	assert.NoError(t, err)
 
 	// Verify it's deleted
-	_, err = models.GetMenuItemByID(id)
+	_, err = repo.GetByID(ctx, id)
 	assert.Equal(t, models.ErrMenuItemNotFound, err)
 
 	// Test deleting non-existent item
-	err = models.DelMenuItem(99999)
+	err = repo.Delete(ctx, 99999)
 	assert.Equal(t, models.ErrMenuItemNotFound, err)
 }
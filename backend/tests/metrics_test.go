@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/metrics"
+	"github.com/andrey-918/cafe-between/internal/readcache"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const metricsToken = "scrape-secret"
+
+func scrape(t *testing.T, r http.Handler) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer "+metricsToken)
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetricsLabelRequestsByRouteTemplate(t *testing.T) {
+	h := newTestHandler(t)
+	m := metrics.New()
+	h.Metrics = m.Handler()
+	h.MetricsToken = metricsToken
+	r := newTestRouter(t, h)
+	srv := m.Instrument(r, r)
+
+	ctx := t.Context()
+	id, err := h.Menu.Create(ctx, models.MenuItem{Title: "Latte", Price: 250})
+	require.NoError(t, err)
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/api/menu/" + strconv.Itoa(id)},
+		{http.MethodGet, "/api/menu/" + strconv.Itoa(id)},
+		{http.MethodGet, "/api/menu/999"},
+		{http.MethodGet, "/api/no-such-route/1"},
+		{http.MethodPatch, "/api/menu"},
+	} {
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+
+	body := scrape(t, srv)
+	assert.Contains(t, body, `cafe_http_requests_total{code="200",method="GET",route="/api/menu/{id}"} 2`)
+	assert.Contains(t, body, `cafe_http_requests_total{code="404",method="GET",route="/api/menu/{id}"} 1`)
+	assert.Contains(t, body, `cafe_http_request_duration_seconds_count{method="GET",route="/api/menu/{id}"} 3`)
+	assert.Contains(t, body, `cafe_http_requests_total{code="404",method="GET",route="unmatched"} 1`)
+	assert.Contains(t, body, `cafe_http_requests_total{code="405",method="PATCH",route="unmatched"} 1`)
+	assert.NotContains(t, body, `route="/api/menu/999"`)
+	assert.NotContains(t, body, `route="/api/no-such-route/1"`)
+}
+
+func TestMetricsNeedTheToken(t *testing.T) {
+	h := newTestHandler(t)
+	h.Metrics = metrics.New().Handler()
+	h.MetricsToken = metricsToken
+	r := newTestRouter(t, h)
+
+	for _, header := range []string{"", "Bearer wrong", metricsToken} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code, header)
+	}
+	scrape(t, r)
+
+	h.MetricsToken = ""
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCatalogueMetrics(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := t.Context()
+	for _, item := range []models.MenuItem{
+		{Title: "Latte", Price: 250, Category: "coffee"},
+		{Title: "Flat white", Price: 270, Category: "coffee"},
+		{Title: "Croissant", Price: 150},
+	} {
+		_, err := h.Menu.Create(ctx, item)
+		require.NoError(t, err)
+	}
+	_, err := h.News.Create(ctx, models.News{Title: "Soon", Status: models.NewsScheduled, PostedAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	cachedMenu := readcache.NewMenuRepository(h.Menu, time.Minute)
+	_, err = cachedMenu.GetByID(ctx, 1)
+	require.NoError(t, err)
+	_, err = cachedMenu.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	m := metrics.New()
+	m.Register(metrics.NewCatalogueCollector(h.Menu, h.News), metrics.NewCacheCollector(cachedMenu))
+	h.Metrics = m.Handler()
+	h.MetricsToken = metricsToken
+
+	body := scrape(t, newTestRouter(t, h))
+	assert.Contains(t, body, `cafe_menu_items{category="coffee"} 2`)
+	assert.Contains(t, body, `cafe_menu_items{category=""} 1`)
+	assert.Contains(t, body, `cafe_news_posts{status="scheduled"} 1`)
+	assert.Contains(t, body, `cafe_news_posts{status="archived"} 0`)
+	assert.Contains(t, body, `cafe_read_cache_hits_total{cache="menu_item"} 1`)
+	assert.Contains(t, body, `cafe_read_cache_misses_total{cache="menu_item"} 1`)
+}
+
+func TestMetricsRouteIsOffWithoutRegistry(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestRouter(t, newTestHandler(t)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
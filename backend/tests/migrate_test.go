@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/andrey-918/cafe-between/internal/migrate"
+	"github.com/andrey-918/cafe-between/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrationsOrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_second.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"0002_second.down.sql": {Data: []byte("DROP TABLE b;")},
+		"0001_first.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+		"0001_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+		"README.md":            {Data: []byte("ignored")},
+	}
+
+	loaded, err := migrate.Load(fsys)
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	assert.Equal(t, int64(1), loaded[0].Version)
+	assert.Equal(t, "first", loaded[0].Name)
+	assert.Equal(t, "CREATE TABLE a (id INT);", loaded[0].Up)
+	assert.Equal(t, "DROP TABLE a;", loaded[0].Down)
+	assert.Equal(t, int64(2), loaded[1].Version)
+}
+
+func TestLoadMigrationsRequiresUpFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_first.down.sql": {Data: []byte("DROP TABLE a;")},
+	}
+
+	_, err := migrate.Load(fsys)
+	assert.Error(t, err)
+}
+
+func TestLoadMigrationsRejectsConflictingNames(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_first.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+		"0001_other.down.sql": {Data: []byte("DROP TABLE a;")},
+	}
+
+	_, err := migrate.Load(fsys)
+	assert.Error(t, err)
+}
+
+func TestEmbeddedMigrationsArePaired(t *testing.T) {
+	loaded, err := migrate.Load(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+	for i, m := range loaded {
+		assert.Equal(t, int64(i+1), m.Version, "versions should be contiguous")
+		assert.NotEmpty(t, m.Down, "migration %d_%s has no down file", m.Version, m.Name)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/andrey-918/cafe-between/internal/database"
 	"github.com/andrey-918/cafe-between/models"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
@@ -30,9 +29,8 @@ func TestCreateNews(t *testing.T) {
 	pool := setupTestDBNews(t)
 	defer pool.Close()
 
-	originalPool := database.Pool
-	database.Pool = pool
-	defer func() { database.Pool = originalPool }()
+	repo := models.NewPostgresNewsRepository(pool)
+	ctx := context.Background()
 
 	postedAt := time.Now().Add(24 * time.Hour).UTC() // Future date
 	item := models.News{
@@ -40,14 +38,15 @@ func TestCreateNews(t *testing.T) {
 		Description: "A test news item",
 		ImageURLs:   []string{"http://example.com/news.jpg"},
 		PostedAt:    postedAt,
+		Status:      models.NewsPublished,
 	}
 
-	id, err := models.CreateNews(item)
+	id, err := repo.Create(ctx, item)
 	assert.NoError(t, err)
 	assert.Greater(t, id, 0)
 
 	// Verify the item was created
-	retrieved, err := models.GetNewsByID(id)
+	retrieved, err := repo.GetByID(ctx, id)
 	assert.NoError(t, err)
 	assert.Equal(t, item.Title, retrieved.Title)
 	assert.Equal(t, item.Description, retrieved.Description)
@@ -60,9 +59,8 @@ func TestGetNewsByID(t *testing.T) {
 	pool := setupTestDBNews(t)
 	defer pool.Close()
 
-	originalPool := database.Pool
-	database.Pool = pool
-	defer func() { database.Pool = originalPool }()
+	repo := models.NewPostgresNewsRepository(pool)
+	ctx := context.Background()
 
 	// Create a test item first
 	postedAt := time.Now().Add(24 * time.Hour)
@@ -71,18 +69,19 @@ func TestGetNewsByID(t *testing.T) {
 		Description: "A test news item",
 		ImageURLs:   []string{"http://example.com/news.jpg"},
 		PostedAt:    postedAt,
+		Status:      models.NewsPublished,
 	}
 
-	id, err := models.CreateNews(item)
+	id, err := repo.Create(ctx, item)
 	require.NoError(t, err)
 
-	retrieved, err := models.GetNewsByID(id)
+	retrieved, err := repo.GetByID(ctx, id)
 	assert.NoError(t, err)
 	assert.Equal(t, id, retrieved.ID)
 	assert.Equal(t, item.Title, retrieved.Title)
 
 	// Test non-existent item
-	_, err = models.GetNewsByID(99999)
+	_, err = repo.GetByID(ctx, 99999)
 	assert.Equal(t, models.ErrNewsNotFound, err)
 }
 
@@ -90,27 +89,26 @@ func TestGetNews(t *testing.T) {
 	pool := setupTestDBNews(t)
 	defer pool.Close()
 
-	originalPool := database.Pool
-	database.Pool = pool
-	defer func() { database.Pool = originalPool }()
+	repo := models.NewPostgresNewsRepository(pool)
+	ctx := context.Background()
 
 	// Clear existing items
-	_, _ = pool.Exec(context.Background(), "DELETE FROM news")
+	_, _ = pool.Exec(ctx, "DELETE FROM news")
 
 	// Create test items
 	postedAt1 := time.Now().Add(24 * time.Hour).UTC()
 	postedAt2 := time.Now().Add(48 * time.Hour).UTC()
 	items := []models.News{
-		{Title: "news 1", Description: "Desc 1", ImageURLs: []string{"url1"}, PostedAt: postedAt1},
-		{Title: "news 2", Description: "Desc 2", ImageURLs: []string{"url2"}, PostedAt: postedAt2},
+		{Title: "news 1", Description: "Desc 1", ImageURLs: []string{"url1"}, PostedAt: postedAt1, Status: models.NewsPublished},
+		{Title: "news 2", Description: "Desc 2", ImageURLs: []string{"url2"}, PostedAt: postedAt2, Status: models.NewsPublished},
 	}
 
 	for _, item := range items {
-		_, err := models.CreateNews(item)
+		_, err := repo.Create(ctx, item)
 		require.NoError(t, err)
 	}
 
-	news, err := models.GetNews()
+	news, err := repo.List(ctx)
 	assert.NoError(t, err)
 	assert.Len(t, news, 2)
 	assert.Equal(t, "news 1", news[0].Title)
@@ -121,9 +119,8 @@ func TestUpdateNews(t *testing.T) {
 	pool := setupTestDBNews(t)
 	defer pool.Close()
 
-	originalPool := database.Pool
-	database.Pool = pool
-	defer func() { database.Pool = originalPool }()
+	repo := models.NewPostgresNewsRepository(pool)
+	ctx := context.Background()
 
 	// Create a test item
 	postedAt := time.Now().Add(24 * time.Hour).UTC()
@@ -132,13 +129,14 @@ func TestUpdateNews(t *testing.T) {
 		Description: "Original description",
 		ImageURLs:   []string{"http://example.com/news.jpg"},
 		PostedAt:    postedAt,
+		Status:      models.NewsPublished,
 	}
 
-	id, err := models.CreateNews(item)
+	id, err := repo.Create(ctx, item)
 	require.NoError(t, err)
 
 	// Store original createdAt and updatedAt
-	original, err := models.GetNewsByID(id)
+	original, err := repo.GetByID(ctx, id)
 	require.NoError(t, err)
 	originalCreatedAt := original.CreatedAt
 	originalUpdatedAt := original.UpdatedAt
@@ -155,11 +153,11 @@ func TestUpdateNews(t *testing.T) {
 		PostedAt:    newPostedAt,
 	}
 
-	err = models.UpdateNews(id, updatedItem)
+	err = repo.Update(ctx, id, updatedItem)
 	assert.NoError(t, err)
 
 	// Verify the update
-	retrieved, err := models.GetNewsByID(id)
+	retrieved, err := repo.GetByID(ctx, id)
 	assert.NoError(t, err)
 	assert.Equal(t, "Updated News", retrieved.Title)
 	assert.Equal(t, "Updated description", retrieved.Description)
@@ -172,9 +170,8 @@ func TestDelNews(t *testing.T) {
 	pool := setupTestDBNews(t)
 	defer pool.Close()
 
-	originalPool := database.Pool
-	database.Pool = pool
-	defer func() { database.Pool = originalPool }()
+	repo := models.NewPostgresNewsRepository(pool)
+	ctx := context.Background()
 
 	// Create a test item
 	postedAt := time.Now().Add(24 * time.Hour).UTC()
@@ -183,20 +180,21 @@ func TestDelNews(t *testing.T) {
 		Description: "Will be deleted",
 		ImageURLs:   []string{"http://example.com/models.News.jpg"},
 		PostedAt:    postedAt,
+		Status:      models.NewsPublished,
 	}
 
-	id, err := models.CreateNews(item)
+	id, err := repo.Create(ctx, item)
 	require.NoError(t, err)
 
 	// Delete the item
-	err = models.DelNews(id)
+	err = repo.Delete(ctx, id)
 	assert.NoError(t, err)
 
 	// Verify it's deleted
-	_, err = models.GetNewsByID(id)
+	_, err = repo.GetByID(ctx, id)
 	assert.Equal(t, models.ErrNewsNotFound, err)
 
 	// Test deleting non-existent item
-	err = models.DelNews(99999)
+	err = repo.Delete(ctx, 99999)
 	assert.Equal(t, models.ErrNewsNotFound, err)
 }
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/scheduler"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewsSchedule(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	item := models.News{PostedAt: now.Add(time.Hour)}
+	item.Schedule(now, nil)
+	assert.Equal(t, models.NewsScheduled, item.Status, "future posts are scheduled")
+	assert.Nil(t, item.PublishedAt)
+
+	item = models.News{}
+	item.Schedule(now, nil)
+	assert.Equal(t, models.NewsPublished, item.Status)
+	assert.Equal(t, now, item.PostedAt)
+	require.NotNil(t, item.PublishedAt)
+	assert.Equal(t, now, *item.PublishedAt)
+
+	previous := item
+	later := now.Add(time.Hour)
+	item = models.News{PostedAt: now}
+	item.Schedule(later, &previous)
+	assert.Equal(t, models.NewsPublished, item.Status, "status is kept when omitted")
+	assert.Equal(t, now, *item.PublishedAt, "first publication time is kept")
+
+	item = models.News{Status: models.NewsArchived, PostedAt: now}
+	item.Schedule(later, &previous)
+	assert.Equal(t, now, *item.PublishedAt)
+
+	item = models.News{Status: models.NewsDraft, PostedAt: now}
+	item.Schedule(later, &previous)
+	assert.Nil(t, item.PublishedAt)
+	assert.False(t, item.VisibleAt(later))
+}
+
+func createNews(t *testing.T, h http.HandlerFunc, item map[string]any) models.News {
+	t.Helper()
+	body, _ := json.Marshal(item)
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodPost, "/api/admin/news", bytes.NewReader(body)))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created models.News
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	return created
+}
+
+func TestPublicNewsHidesUnpublishedPosts(t *testing.T) {
+	h := newTestHandler(t)
+	now := time.Now().UTC()
+	live := createNews(t, h.CreateNewsHandler, map[string]any{"title": "Live", "imageURLs": []string{}, "postedAt": now.Add(-time.Hour)})
+	future := createNews(t, h.CreateNewsHandler, map[string]any{"title": "Future", "imageURLs": []string{}, "postedAt": now.Add(time.Hour)})
+	draft := createNews(t, h.CreateNewsHandler, map[string]any{"title": "Draft", "imageURLs": []string{}, "status": "draft"})
+	createNews(t, h.CreateNewsHandler, map[string]any{"title": "Old", "imageURLs": []string{}, "status": "archived", "postedAt": now.Add(-48 * time.Hour)})
+	assert.Equal(t, models.NewsPublished, live.Status)
+	assert.NotNil(t, live.PublishedAt)
+	assert.Equal(t, models.NewsScheduled, future.Status)
+	assert.Equal(t, models.NewsDraft, draft.Status)
+
+	rec := httptest.NewRecorder()
+	h.GetNewsHandler(rec, httptest.NewRequest(http.MethodGet, "/api/news", nil))
+	var news []models.News
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&news))
+	require.Len(t, news, 1)
+	assert.Equal(t, "Live", news[0].Title)
+
+	for _, id := range []int{future.ID, draft.ID} {
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/news/"+strconv.Itoa(id), nil), map[string]string{"id": strconv.Itoa(id)})
+		rec = httptest.NewRecorder()
+		h.GetNewsByIdHandler(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+
+	createTestUser(t, h, "anna", "correct-horse", models.RoleEditor)
+	token := loginToken(t, h, "anna", "correct-horse")
+	r := newTestRouter(t, h)
+	for query, want := range map[string]int{"": 4, "?status=scheduled": 1, "?status=draft": 1} {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/news"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&news))
+		assert.Len(t, news, want, query)
+	}
+}
+
+func TestPublishDueRecordsPublicationTime(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	future := createNews(t, h.CreateNewsHandler, map[string]any{"title": "Future", "imageURLs": []string{}, "postedAt": now.Add(time.Hour)})
+
+	published, err := h.News.PublishDue(ctx, now)
+	require.NoError(t, err)
+	assert.Zero(t, published)
+
+	later := now.Add(2 * time.Hour)
+	published, err = h.News.PublishDue(ctx, later)
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	item, err := h.News.GetByID(ctx, future.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.NewsPublished, item.Status)
+	require.NotNil(t, item.PublishedAt)
+	assert.Equal(t, later, *item.PublishedAt)
+}
+
+func TestSchedulerRunsJobsUntilCancelled(t *testing.T) {
+	var runs atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx, scheduler.Job{Name: "count", Interval: time.Millisecond, Run: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		}})
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after cancel")
+	}
+}
@@ -0,0 +1,326 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/internal/openapi"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	h := newTestHandler(t)
+	doc, err := h.OpenAPI()
+	require.NoError(t, err)
+
+	documented := 0
+	for _, item := range doc.Paths {
+		documented += len(*item)
+	}
+	assert.Equal(t, len(h.Routes()), documented)
+
+	for _, route := range h.Routes() {
+		op := (*doc.Paths[route.Path])[strings.ToLower(route.Method)]
+		require.NotNil(t, op, "%s %s", route.Method, route.Path)
+		assert.Equal(t, route.Auth, len(op.Security) > 0, "%s %s security", route.Method, route.Path)
+		if route.Auth {
+			assert.Contains(t, op.Responses, "401", "%s %s", route.Method, route.Path)
+		}
+		if len(route.Roles) > 0 {
+			assert.Equal(t, []string{"forbidden"}, op.Responses["403"].ErrorCodes, "%s %s", route.Method, route.Path)
+		}
+	}
+}
+
+func TestOpenAPISchemasFollowModels(t *testing.T) {
+	doc, err := newTestHandler(t).OpenAPI()
+	require.NoError(t, err)
+
+	item := doc.Components.Schemas["MenuItem"]
+	require.NotNil(t, item)
+	assert.Equal(t, "integer", item.Properties["price"].Type)
+	assert.Equal(t, "date-time", item.Properties["updatedAt"].Format)
+	assert.True(t, item.Properties["id"].ReadOnly)
+	assert.Contains(t, item.Required, "title")
+	assert.NotContains(t, item.Required, "calories", "omitempty fields are optional")
+
+	news := doc.Components.Schemas["News"]
+	require.NotNil(t, news)
+	assert.Equal(t, []string{"draft", "scheduled", "published", "archived"}, news.Properties["status"].Enum)
+	assert.True(t, news.Properties["publishedAt"].Nullable)
+	assert.NotContains(t, doc.Components.Schemas["User"].Properties, "PasswordHash")
+}
+
+func TestOpenAPIAndDocsAreServed(t *testing.T) {
+	h := newTestHandler(t)
+	r := newTestRouter(t, h)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	assert.Contains(t, doc.Paths, "/api/menu/{id}")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, rec.Body.String(), `fetch("openapi.json")`)
+}
+
+// contract sends requests through the full router and checks every
+// response against the document, recording which operations were reached.
+type contract struct {
+	t       *testing.T
+	doc     *openapi.Document
+	router  *mux.Router
+	token   string
+	reached map[string]bool
+}
+
+func newContract(t *testing.T, h *handlers.Handler) *contract {
+	doc, err := h.OpenAPI()
+	require.NoError(t, err)
+	return &contract{t: t, doc: doc, router: newTestRouter(t, h), reached: map[string]bool{}}
+}
+
+func (c *contract) do(req *http.Request) *httptest.ResponseRecorder {
+	c.t.Helper()
+	if c.token != "" && req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	var match mux.RouteMatch
+	require.True(c.t, c.router.Match(req, &match), "%s %s matches no route", req.Method, req.URL)
+	path, err := match.Route.GetPathTemplate()
+	require.NoError(c.t, err)
+
+	rec := httptest.NewRecorder()
+	c.router.ServeHTTP(rec, req)
+	assert.NoError(c.t, c.doc.ValidateResponse(req.Method, path, rec.Code, rec.Header().Get("Content-Type"), rec.Body.Bytes()))
+	c.reached[req.Method+" "+path] = true
+	return rec
+}
+
+func (c *contract) json(method, target, body string, status int) *httptest.ResponseRecorder {
+	c.t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := c.do(req)
+	require.Equal(c.t, status, rec.Code, "%s %s: %s", method, target, rec.Body.String())
+	return rec
+}
+
+func (c *contract) get(target string, status int) *httptest.ResponseRecorder {
+	c.t.Helper()
+	rec := c.do(httptest.NewRequest(http.MethodGet, target, nil))
+	require.Equal(c.t, status, rec.Code, "GET %s: %s", target, rec.Body.String())
+	return rec
+}
+
+func TestResponsesMatchOpenAPI(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "olga", "owner-password", models.RoleOwner)
+	createTestUser(t, h, "boris", "barista-password", models.RoleBarista)
+	c := newContract(t, h)
+
+	for _, path := range []string{"/healthz", "/readyz", "/api/openapi.json", "/api/docs", "/api/config"} {
+		c.get(path, http.StatusOK)
+	}
+	c.get("/metrics", http.StatusNotFound)
+
+	c.json(http.MethodPost, "/api/login", `{"username":"olga","password":"wrong"}`, http.StatusUnauthorized)
+	c.json(http.MethodPost, "/api/login", `{`, http.StatusBadRequest)
+	var tokens struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refreshToken"`
+	}
+	rec := c.json(http.MethodPost, "/api/login", `{"username":"olga","password":"owner-password"}`, http.StatusOK)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+	c.get("/api/admin/users", http.StatusUnauthorized)
+	c.token = tokens.Token
+
+	// Categories and menu.
+	c.json(http.MethodPost, "/api/admin/categories", `{"slug":"coffee","name":"Coffee","visible":true}`, http.StatusCreated)
+	c.json(http.MethodPost, "/api/admin/categories", `{"slug":"coffee","name":"Coffee"}`, http.StatusConflict)
+	c.json(http.MethodPost, "/api/admin/categories", `{"slug":"tea"}`, http.StatusUnprocessableEntity)
+	c.json(http.MethodPut, "/api/admin/categories/coffee", `{"name":"Coffee","position":1,"visible":true}`, http.StatusNoContent)
+	c.json(http.MethodPut, "/api/admin/categories/cocoa", `{"name":"Cocoa"}`, http.StatusNotFound)
+	c.get("/api/categories", http.StatusOK)
+	c.get("/api/admin/categories", http.StatusOK)
+
+	var item models.MenuItem
+	rec = c.json(http.MethodPost, "/api/admin/menu", `{"title":"Latte","price":250,"category":"coffee"}`, http.StatusCreated)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &item))
+	itemPath := "/api/admin/menu/" + strconv.Itoa(item.ID)
+	c.json(http.MethodPost, "/api/admin/menu", `{"price":-1}`, http.StatusUnprocessableEntity)
+	c.json(http.MethodPut, itemPath, `{"title":"Latte","price":260,"category":"coffee","calories":120}`, http.StatusNoContent)
+	c.json(http.MethodPut, "/api/admin/menu/999", `{"title":"Latte"}`, http.StatusNotFound)
+
+	c.get("/api/menu", http.StatusOK)
+	c.get("/api/menu?sort=price&limit=1", http.StatusOK)
+	c.get("/api/menu?limit=0", http.StatusBadRequest)
+	c.get("/api/admin/menu?category=coffee", http.StatusOK)
+	rec = c.get("/api/menu/"+strconv.Itoa(item.ID), http.StatusOK)
+	req := httptest.NewRequest(http.MethodGet, "/api/menu/"+strconv.Itoa(item.ID), nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, c.do(req).Code)
+	c.get("/api/menu/999", http.StatusNotFound)
+	c.get("/api/menu/latte", http.StatusBadRequest)
+
+	c.json(http.MethodPatch, itemPath, `{"price":270}`, http.StatusPreconditionRequired)
+	c.json(http.MethodPatch, itemPath, `{"price":270,"updatedAt":"2000-01-01T00:00:00Z"}`, http.StatusPreconditionFailed)
+	rec = c.get("/api/menu/"+strconv.Itoa(item.ID), http.StatusOK)
+	req = httptest.NewRequest(http.MethodPatch, itemPath, strings.NewReader(`{"calories":null}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", rec.Header().Get("ETag"))
+	assert.Equal(t, http.StatusOK, c.do(req).Code)
+
+	c.json(http.MethodPut, itemPath+"/availability", `{"availability":"sold_out"}`, http.StatusOK)
+	c.json(http.MethodPut, itemPath+"/availability", `{"availability":"seasonal"}`, http.StatusUnprocessableEntity)
+	c.json(http.MethodPut, "/api/admin/menu/999/availability", `{"availability":"available"}`, http.StatusNotFound)
+	c.get("/api/admin/stop-list", http.StatusOK)
+
+	var itemRevisions []models.MenuRevision
+	rec = c.get(itemPath+"/revisions", http.StatusOK)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &itemRevisions))
+	require.NotEmpty(t, itemRevisions)
+	itemRevision := strconv.FormatInt(itemRevisions[0].ID, 10)
+	c.get("/api/admin/menu/999/revisions", http.StatusNotFound)
+	c.get(itemPath+"/revisions/diff?from="+itemRevision, http.StatusOK)
+	c.get(itemPath+"/revisions/diff", http.StatusBadRequest)
+	c.get(itemPath+"/revisions/diff?from=999", http.StatusNotFound)
+	c.json(http.MethodPost, itemPath+"/revisions/"+itemRevision+"/restore", ``, http.StatusOK)
+	c.json(http.MethodPost, itemPath+"/revisions/999/restore", ``, http.StatusNotFound)
+
+	// News.
+	var post models.News
+	rec = c.json(http.MethodPost, "/api/admin/news", `{"title":"Open late","preview":"Till midnight","status":"published"}`, http.StatusCreated)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &post))
+	postPath := "/api/admin/news/" + strconv.Itoa(post.ID)
+	c.json(http.MethodPost, "/api/admin/news", `{"status":"gone"}`, http.StatusUnprocessableEntity)
+	c.json(http.MethodPut, postPath, `{"title":"Open late","preview":"Till 1am","status":"published"}`, http.StatusNoContent)
+	c.json(http.MethodPatch, postPath, `{"preview":"Till 2am","updatedAt":"`+c.updatedAt("/api/news/"+strconv.Itoa(post.ID))+`"}`, http.StatusOK)
+	c.get("/api/news", http.StatusOK)
+	c.get("/api/news/"+strconv.Itoa(post.ID), http.StatusOK)
+	c.get("/api/news/999", http.StatusNotFound)
+	c.get("/api/admin/news?status=published", http.StatusOK)
+	c.get("/api/admin/news?status=gone", http.StatusBadRequest)
+
+	var postRevisions []models.NewsRevision
+	rec = c.get(postPath+"/revisions", http.StatusOK)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &postRevisions))
+	require.Len(t, postRevisions, 2)
+	from, to := strconv.FormatInt(postRevisions[1].ID, 10), strconv.FormatInt(postRevisions[0].ID, 10)
+	c.get(postPath+"/revisions/diff?from="+from+"&to="+to, http.StatusOK)
+	c.get(postPath+"/revisions/diff?from=x", http.StatusBadRequest)
+	c.json(http.MethodPost, postPath+"/revisions/"+from+"/restore", ``, http.StatusOK)
+	c.json(http.MethodPost, postPath+"/revisions/999/restore", ``, http.StatusNotFound)
+
+	// Uploads.
+	req = uploadRequest(t, "file", pngBytes(t))
+	assert.Equal(t, http.StatusCreated, c.do(req).Code)
+	req = uploadRequest(t, "photo", pngBytes(t))
+	assert.Equal(t, http.StatusBadRequest, c.do(req).Code)
+
+	// Users and sessions.
+	c.get("/api/admin/users", http.StatusOK)
+	var user models.User
+	rec = c.json(http.MethodPost, "/api/admin/users", `{"username":"vera","password":"editor-password","role":"editor"}`, http.StatusCreated)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &user))
+	userPath := "/api/admin/users/" + strconv.Itoa(user.ID)
+	c.json(http.MethodPost, "/api/admin/users", `{"username":"vera","password":"editor-password","role":"editor"}`, http.StatusConflict)
+	c.json(http.MethodPut, userPath, `{"username":"vera","role":"menu_manager"}`, http.StatusNoContent)
+	c.json(http.MethodPost, userPath+"/sessions/revoke", ``, http.StatusNoContent)
+	c.json(http.MethodDelete, userPath, ``, http.StatusNoContent)
+	c.json(http.MethodDelete, userPath, ``, http.StatusNotFound)
+
+	barista := loginToken(t, h, "boris", "barista-password")
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/news", nil)
+	req.Header.Set("Authorization", "Bearer "+barista)
+	assert.Equal(t, http.StatusForbidden, c.do(req).Code)
+
+	// Deletes and the trash.
+	c.json(http.MethodDelete, "/api/admin/categories/coffee", ``, http.StatusConflict)
+	c.json(http.MethodDelete, itemPath, ``, http.StatusNoContent)
+	c.json(http.MethodDelete, itemPath, ``, http.StatusNotFound)
+	c.json(http.MethodDelete, "/api/admin/categories/coffee", ``, http.StatusConflict)
+	c.get("/api/admin/trash/menu", http.StatusOK)
+	c.json(http.MethodPost, "/api/admin/trash/menu/"+strconv.Itoa(item.ID)+"/restore", ``, http.StatusOK)
+	c.json(http.MethodPost, "/api/admin/trash/menu/"+strconv.Itoa(item.ID)+"/restore", ``, http.StatusNotFound)
+	c.json(http.MethodPost, "/api/admin/categories", `{"slug":"tea","name":"Tea"}`, http.StatusCreated)
+	c.json(http.MethodDelete, "/api/admin/categories/tea", ``, http.StatusNoContent)
+	c.json(http.MethodDelete, postPath, ``, http.StatusNoContent)
+	c.get("/api/admin/trash/news", http.StatusOK)
+	c.json(http.MethodPost, "/api/admin/trash/news/"+strconv.Itoa(post.ID)+"/restore", ``, http.StatusOK)
+	c.json(http.MethodPost, "/api/admin/trash/news/999/restore", ``, http.StatusNotFound)
+
+	// The audit log, which by now has an entry of every kind.
+	c.get("/api/admin/audit", http.StatusOK)
+	c.get("/api/admin/audit?entity=menu_item&entityId="+strconv.Itoa(item.ID), http.StatusOK)
+	c.get("/api/admin/audit?action=rename", http.StatusBadRequest)
+
+	// Token lifecycle last, as it ends the session the requests above use.
+	c.json(http.MethodPost, "/api/auth/refresh", `{"refreshToken":"not-a-token"}`, http.StatusUnauthorized)
+	rec = c.json(http.MethodPost, "/api/auth/refresh", `{"refreshToken":"`+tokens.RefreshToken+`"}`, http.StatusOK)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+	c.token = tokens.Token
+	c.json(http.MethodPut, "/api/admin/password", `{"currentPassword":"wrong","newPassword":"new-owner-password"}`, http.StatusUnauthorized)
+	rec = c.json(http.MethodPut, "/api/admin/password", `{"currentPassword":"owner-password","newPassword":"new-owner-password"}`, http.StatusOK)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+	c.token = tokens.Token
+	c.json(http.MethodPost, "/api/logout", `{"refreshToken":"`+tokens.RefreshToken+`"}`, http.StatusOK)
+	c.json(http.MethodPost, "/api/logout", ``, http.StatusUnauthorized)
+
+	c.token = loginToken(t, h, "olga", "new-owner-password")
+	c.json(http.MethodPost, "/api/admin/sessions/revoke", ``, http.StatusNoContent)
+
+	for _, route := range h.Routes() {
+		assert.True(t, c.reached[route.Method+" "+route.Path], "%s %s is not exercised by the contract test", route.Method, route.Path)
+	}
+}
+
+// updatedAt reads the current updatedAt of a resource, for a PATCH that
+// names its version in the body.
+func (c *contract) updatedAt(target string) string {
+	c.t.Helper()
+	var v struct {
+		UpdatedAt string `json:"updatedAt"`
+	}
+	require.NoError(c.t, json.Unmarshal(c.get(target, http.StatusOK).Body.Bytes(), &v))
+	return v.UpdatedAt
+}
+
+func TestContractDetectsDrift(t *testing.T) {
+	doc, err := newTestHandler(t).OpenAPI()
+	require.NoError(t, err)
+	item := `{"id":1,"title":"Latte","price":250,"imageURLs":[],"category":"coffee","createdAt":"2024-01-01T09:00:00Z","updatedAt":"2024-01-01T09:00:00Z","availability":"available"}`
+	require.NoError(t, doc.ValidateResponse(http.MethodGet, "/api/menu/{id}", http.StatusOK, "application/json", []byte(item)))
+
+	for name, tc := range map[string]struct {
+		status int
+		body   string
+	}{
+		"renamed field":     {http.StatusOK, strings.Replace(item, `"price"`, `"cost"`, 1)},
+		"wrong type":        {http.StatusOK, strings.Replace(item, `250`, `"250"`, 1)},
+		"missing required":  {http.StatusOK, strings.Replace(item, `"title":"Latte",`, ``, 1)},
+		"bad timestamp":     {http.StatusOK, strings.Replace(item, `"2024-01-01T09:00:00Z"`, `"yesterday"`, 1)},
+		"undocumented code": {http.StatusNotFound, `{"error":{"code":"news_not_found","message":"","requestId":""}}`},
+		"unknown status":    {http.StatusTeapot, `{}`},
+		"body on 304":       {http.StatusNotModified, item},
+	} {
+		err := doc.ValidateResponse(http.MethodGet, "/api/menu/{id}", tc.status, "application/json", []byte(tc.body))
+		assert.Error(t, err, name)
+	}
+	assert.Error(t, doc.ValidateResponse(http.MethodGet, "/api/menu/{id}", http.StatusNoContent, "", nil))
+}
@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/internal/mergepatch"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatchRFCExamples(t *testing.T) {
+	// Appendix A of RFC 7396.
+	for _, tc := range []struct{ target, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	} {
+		got, err := mergepatch.Apply([]byte(tc.target), []byte(tc.patch))
+		require.NoError(t, err)
+		assert.JSONEq(t, tc.want, string(got), "%s + %s", tc.target, tc.patch)
+	}
+
+	_, err := mergepatch.Apply([]byte(`{}`), []byte(`{"a":`))
+	assert.ErrorIs(t, err, mergepatch.ErrInvalidPatch)
+}
+
+// patchRequest builds a merge patch request for the item or post at id.
+func patchRequest(path string, id int, body string, header map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodPatch, path+"/"+strconv.Itoa(id), bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", mergepatch.ContentType)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	return mux.SetURLVars(req, map[string]string{"id": strconv.Itoa(id)})
+}
+
+func menuItemETag(t *testing.T, h *handlers.Handler, id int) string {
+	t.Helper()
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/menu/"+strconv.Itoa(id), nil), map[string]string{"id": strconv.Itoa(id)})
+	rec := httptest.NewRecorder()
+	h.GetMenuItemHandler(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	tag := rec.Header().Get("ETag")
+	require.NotEmpty(t, tag)
+	return tag
+}
+
+func TestPatchMenuItemChangesOnlyGivenFields(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	require.NoError(t, h.Categories.Create(ctx, models.Category{Slug: "coffee", Name: "Кофе", Visible: true}))
+	id, err := h.Menu.Create(ctx, models.MenuItem{Title: "Latte", Price: 250, Calories: 180, Description: "Milky", Category: "coffee", ImageURLs: []string{"http://example.com/latte.jpg"}})
+	require.NoError(t, err)
+	tag := menuItemETag(t, h, id)
+
+	rec := httptest.NewRecorder()
+	h.PatchMenuItemHandler(rec, patchRequest("/api/admin/menu", id, `{"price": 270}`, map[string]string{"If-Match": tag}))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotEqual(t, tag, rec.Header().Get("ETag"))
+
+	item, err := h.Menu.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 270, item.Price)
+	assert.Equal(t, "Latte", item.Title)
+	assert.Equal(t, "Milky", item.Description)
+	assert.Equal(t, 180, item.Calories)
+	assert.Equal(t, "coffee", item.Category)
+	assert.Equal(t, []string{"http://example.com/latte.jpg"}, item.ImageURLs)
+
+	// null removes a member; updatedAt in the body works instead of If-Match.
+	version, _ := json.Marshal(item.UpdatedAt)
+	rec = httptest.NewRecorder()
+	h.PatchMenuItemHandler(rec, patchRequest("/api/admin/menu", id, `{"category": null, "updatedAt": `+string(version)+`}`, nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	item, err = h.Menu.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Empty(t, item.Category)
+	assert.Equal(t, 270, item.Price)
+}
+
+func TestPatchMenuItemEnforcesVersion(t *testing.T) {
+	h := newTestHandler(t)
+	id, err := h.Menu.Create(context.Background(), models.MenuItem{Title: "Tea", Price: 100, ImageURLs: []string{}})
+	require.NoError(t, err)
+	stale := menuItemETag(t, h, id)
+
+	rec := httptest.NewRecorder()
+	h.PatchMenuItemHandler(rec, patchRequest("/api/admin/menu", id, `{"price": 110}`, nil))
+	assert.Equal(t, http.StatusPreconditionRequired, rec.Code)
+	assert.Equal(t, apierr.PreconditionNeeded, decodeError[any](t, rec).Code)
+
+	// The first admin saves; the second, still holding the old version, is
+	// refused instead of silently overwriting the change.
+	rec = httptest.NewRecorder()
+	h.PatchMenuItemHandler(rec, patchRequest("/api/admin/menu", id, `{"price": 110}`, map[string]string{"If-Match": stale}))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.PatchMenuItemHandler(rec, patchRequest("/api/admin/menu", id, `{"title": "Green Tea"}`, map[string]string{"If-Match": stale}))
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	assert.Equal(t, apierr.EditConflict, decodeError[any](t, rec).Code)
+
+	rec = httptest.NewRecorder()
+	h.PatchMenuItemHandler(rec, patchRequest("/api/admin/menu", id, `{"title": "Green Tea", "updatedAt": "2020-01-01T00:00:00Z"}`, nil))
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	item, err := h.Menu.GetByID(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "Tea", item.Title)
+	assert.Equal(t, 110, item.Price)
+
+	// PUT checks If-Match too when it is sent.
+	body, _ := json.Marshal(models.MenuItem{Title: "Black Tea", Price: 90, ImageURLs: []string{}})
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPut, "/api/admin/menu/1", bytes.NewReader(body)), map[string]string{"id": strconv.Itoa(id)})
+	req.Header.Set("If-Match", stale)
+	rec = httptest.NewRecorder()
+	h.UpdateMenuHandler(rec, req)
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+}
+
+func TestPatchMenuItemRejectsBadBodies(t *testing.T) {
+	h := newTestHandler(t)
+	id, err := h.Menu.Create(context.Background(), models.MenuItem{Title: "Tea", Price: 100, ImageURLs: []string{}})
+	require.NoError(t, err)
+	tag := menuItemETag(t, h, id)
+	match := map[string]string{"If-Match": tag}
+
+	rec := httptest.NewRecorder()
+	h.PatchMenuItemHandler(rec, patchRequest("/api/admin/menu", id, `{"title": null}`, match))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, map[string]string{"title": "required"}, fieldCodes(t, rec))
+
+	rec = httptest.NewRecorder()
+	h.PatchMenuItemHandler(rec, patchRequest("/api/admin/menu", id, `["price"]`, match))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.PatchMenuItemHandler(rec, patchRequest("/api/admin/menu", id, `{"price": "cheap"}`, match))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	req := patchRequest("/api/admin/menu", id, `price=1`, match)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.PatchMenuItemHandler(rec, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.PatchMenuItemHandler(rec, patchRequest("/api/admin/menu", 99, `{"price": 1}`, match))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPatchNewsKeepsPostedAt(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	postedAt := time.Now().UTC().Add(-72 * time.Hour).Truncate(time.Second)
+	id, err := h.News.Create(ctx, models.News{Title: "Opening", Preview: "Soon", ImageURLs: []string{}, Status: models.NewsPublished, PostedAt: postedAt})
+	require.NoError(t, err)
+	before, err := h.News.GetByID(ctx, id)
+	require.NoError(t, err)
+	version, _ := json.Marshal(before.UpdatedAt)
+
+	rec := httptest.NewRecorder()
+	h.PatchNewsHandler(rec, patchRequest("/api/admin/news", id, `{"title": "Grand opening", "updatedAt": `+string(version)+`}`, nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var patched models.News
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&patched))
+	assert.Equal(t, "Grand opening", patched.Title)
+	assert.Equal(t, "Soon", patched.Preview)
+	assert.True(t, postedAt.Equal(patched.PostedAt), "postedAt changed to %s", patched.PostedAt)
+	assert.Equal(t, models.NewsPublished, patched.Status)
+
+	// Moving postedAt into the future schedules the post.
+	future := time.Now().UTC().Add(24 * time.Hour).Format(time.RFC3339)
+	tag := rec.Header().Get("ETag")
+	rec = httptest.NewRecorder()
+	h.PatchNewsHandler(rec, patchRequest("/api/admin/news", id, `{"postedAt": "`+future+`"}`, map[string]string{"If-Match": tag}))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&patched))
+	assert.Equal(t, models.NewsScheduled, patched.Status)
+}
+
+func TestUpdateNewsWithoutPostedAtKeepsIt(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	postedAt := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Second)
+	id, err := h.News.Create(ctx, models.News{Title: "Menu update", ImageURLs: []string{}, Status: models.NewsPublished, PostedAt: postedAt})
+	require.NoError(t, err)
+
+	body := []byte(`{"title": "Menu update!", "imageURLs": []}`)
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPut, "/api/admin/news/1", bytes.NewReader(body)), map[string]string{"id": strconv.Itoa(id)})
+	rec := httptest.NewRecorder()
+	h.UpdateNewsHandler(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	item, err := h.News.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, postedAt.Equal(item.PostedAt), "postedAt changed to %s", item.PostedAt)
+}
+
+func TestUpdateIfUnmodifiedDetectsConflicts(t *testing.T) {
+	ctx := context.Background()
+	repo := models.NewMemoryMenuRepository()
+	id, err := repo.Create(ctx, models.MenuItem{Title: "Tea", Price: 100})
+	require.NoError(t, err)
+	item, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.UpdateIfUnmodified(ctx, id, models.MenuItem{Title: "Tea", Price: 110}, item.UpdatedAt))
+	assert.ErrorIs(t, repo.UpdateIfUnmodified(ctx, id, models.MenuItem{Title: "Tea", Price: 120}, item.UpdatedAt), models.ErrEditConflict)
+	assert.ErrorIs(t, repo.UpdateIfUnmodified(ctx, id+1, models.MenuItem{Title: "Tea"}, item.UpdatedAt), models.ErrMenuItemNotFound)
+}
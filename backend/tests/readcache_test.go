@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/readcache"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedMenuRepository counts GetByID calls and holds each one until the
+// gate is closed, so tests can pile up concurrent misses.
+type gatedMenuRepository struct {
+	*models.MemoryMenuRepository
+	gate  chan struct{}
+	calls atomic.Int32
+}
+
+func (r *gatedMenuRepository) GetByID(ctx context.Context, id int) (models.MenuItem, error) {
+	r.calls.Add(1)
+	<-r.gate
+	return r.MemoryMenuRepository.GetByID(ctx, id)
+}
+
+func TestReadCacheCountsHitsAndInvalidatesOnWrite(t *testing.T) {
+	ctx := context.Background()
+	repo := readcache.NewMenuRepository(models.NewMemoryMenuRepository(), time.Hour)
+	id, err := repo.Create(ctx, models.MenuItem{Title: "Tea", Price: 100, ImageURLs: []string{"http://example.com/tea.jpg"}})
+	require.NoError(t, err)
+
+	item, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	item.ImageURLs[0] = "changed by caller"
+	item, err = repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "http://example.com/tea.jpg", item.ImageURLs[0], "callers must get copies")
+
+	stats := repo.CacheStats()["menu_item"]
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Hits)
+
+	require.NoError(t, repo.Update(ctx, id, models.MenuItem{Title: "Tea", Price: 120, ImageURLs: []string{}}))
+	item, err = repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 120, item.Price)
+
+	page, err := repo.List(ctx, models.MenuQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.NoError(t, repo.Delete(ctx, id))
+	page, err = repo.List(ctx, models.MenuQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+
+	_, err = repo.GetByID(ctx, id)
+	assert.ErrorIs(t, err, models.ErrMenuItemNotFound)
+}
+
+func TestReadCacheExpiresAfterTTL(t *testing.T) {
+	ctx := context.Background()
+	inner := models.NewMemoryMenuRepository()
+	repo := readcache.NewMenuRepository(inner, 50*time.Millisecond)
+	id, err := repo.Create(ctx, models.MenuItem{Title: "Tea", Price: 100, ImageURLs: []string{}})
+	require.NoError(t, err)
+	_, err = repo.GetByID(ctx, id)
+	require.NoError(t, err)
+
+	// Another instance writes straight to the database.
+	require.NoError(t, inner.Update(ctx, id, models.MenuItem{Title: "Tea", Price: 150, ImageURLs: []string{}}))
+	item, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 100, item.Price)
+
+	time.Sleep(60 * time.Millisecond)
+	item, err = repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 150, item.Price)
+}
+
+func TestReadCacheCollapsesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	inner := &gatedMenuRepository{MemoryMenuRepository: models.NewMemoryMenuRepository(), gate: make(chan struct{})}
+	id, err := inner.Create(ctx, models.MenuItem{Title: "Tea", Price: 100, ImageURLs: []string{}})
+	require.NoError(t, err)
+	repo := readcache.NewMenuRepository(inner, time.Hour)
+
+	const readers = 10
+	var wg sync.WaitGroup
+	for range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			item, err := repo.GetByID(ctx, id)
+			assert.NoError(t, err)
+			assert.Equal(t, "Tea", item.Title)
+		}()
+	}
+	require.Eventually(t, func() bool {
+		s := repo.CacheStats()["menu_item"]
+		return s.Misses+s.Shared == readers
+	}, time.Second, time.Millisecond)
+	close(inner.gate)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), inner.calls.Load())
+	stats := repo.CacheStats()["menu_item"]
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(readers-1), stats.Shared)
+}
+
+func TestReadCacheDropsLoadsOverlappingWrites(t *testing.T) {
+	ctx := context.Background()
+	inner := &gatedMenuRepository{MemoryMenuRepository: models.NewMemoryMenuRepository(), gate: make(chan struct{})}
+	id, err := inner.Create(ctx, models.MenuItem{Title: "Tea", Price: 100, ImageURLs: []string{}})
+	require.NoError(t, err)
+	repo := readcache.NewMenuRepository(inner, time.Hour)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = repo.GetByID(ctx, id)
+	}()
+	require.Eventually(t, func() bool { return inner.calls.Load() == 1 }, time.Second, time.Millisecond)
+	// The read above may have seen the old row; it must not be cached.
+	repo.Invalidate()
+	close(inner.gate)
+	<-done
+
+	_, err = repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), inner.calls.Load())
+}
+
+func TestNewsReadCacheShowsScheduledPostsOnTime(t *testing.T) {
+	ctx := context.Background()
+	repo := readcache.NewNewsRepository(models.NewMemoryNewsRepository(), time.Hour)
+	now := time.Now().UTC()
+	_, err := repo.Create(ctx, models.News{Title: "Live", ImageURLs: []string{}, Status: models.NewsPublished, PostedAt: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, models.News{Title: "Later", ImageURLs: []string{}, Status: models.NewsScheduled, PostedAt: now.Add(time.Minute)})
+	require.NoError(t, err)
+
+	visible, err := repo.ListVisible(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, visible, 1)
+	before, err := repo.VisibleStats(ctx, now)
+	require.NoError(t, err)
+
+	// No write happens, and the TTL is an hour away.
+	later := now.Add(2 * time.Minute)
+	visible, err = repo.ListVisible(ctx, later)
+	require.NoError(t, err)
+	require.Len(t, visible, 2)
+	assert.Equal(t, "Later", visible[0].Title)
+	after, err := repo.VisibleStats(ctx, later)
+	require.NoError(t, err)
+	assert.Equal(t, 2, after.Count)
+	assert.NotEqual(t, before, after)
+
+	stats := repo.CacheStats()["news_list"]
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(3), stats.Hits)
+}
+
+func TestNewsReadCacheStatsMatchAfterDelete(t *testing.T) {
+	ctx := context.Background()
+	inner := models.NewMemoryNewsRepository()
+	repo := readcache.NewNewsRepository(inner, time.Hour)
+	now := time.Now().UTC()
+	var ids []int
+	for _, title := range []string{"Old", "New"} {
+		id, err := repo.Create(ctx, models.News{Title: title, ImageURLs: []string{}, Status: models.NewsPublished, PostedAt: now.Add(-time.Hour)})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	_, err := repo.VisibleStats(ctx, now)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Delete(ctx, ids[1]))
+	want, err := inner.VisibleStats(ctx, now)
+	require.NoError(t, err)
+	for range 2 {
+		got, err := repo.VisibleStats(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	assert.Equal(t, 1, want.Count)
+}
+
+func TestHandlersWorkOverReadCache(t *testing.T) {
+	h := newTestHandler(t)
+	h.Menu = readcache.NewMenuRepository(h.Menu, time.Hour)
+	h.News = readcache.NewNewsRepository(h.News, time.Hour)
+	r := newTestRouter(t, h)
+	ctx := context.Background()
+
+	rec := conditionalGet(r, "/api/menu", nil)
+	require.Equal(t, 200, rec.Code)
+	tag := rec.Header().Get("ETag")
+
+	_, err := h.Menu.Create(ctx, models.MenuItem{Title: "Tea", Price: 100, ImageURLs: []string{}})
+	require.NoError(t, err)
+	rec = conditionalGet(r, "/api/menu", map[string]string{"If-None-Match": tag})
+	require.Equal(t, 200, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Tea")
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewsWordingCanBeRolledBack(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "vera", "editor-pass", models.RoleEditor)
+	r := newTestRouter(t, h)
+	token := loginToken(t, h, "vera", "editor-pass")
+	id, err := h.News.Create(context.Background(), models.News{Title: "Open late", Preview: "Till midnight", Status: models.NewsPublished, PostedAt: time.Now().UTC().Add(-time.Hour)})
+	require.NoError(t, err)
+	postPath := "/api/admin/news/" + strconv.Itoa(id)
+
+	rec := staffRequest(t, r, token, http.MethodPut, postPath, `{"title":"Open later","preview":"Till 2am","status":"draft"}`)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = staffRequest(t, r, token, http.MethodGet, postPath+"/revisions", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var revisions []models.NewsRevision
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&revisions))
+	require.Len(t, revisions, 1)
+	assert.Equal(t, "Till midnight", revisions[0].Item.Preview)
+	revision := strconv.FormatInt(revisions[0].ID, 10)
+
+	rec = staffRequest(t, r, token, http.MethodGet, postPath+"/revisions/diff?from="+revision, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var diff struct {
+		Changes map[string]models.AuditChange `json:"changes"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&diff))
+	assert.ElementsMatch(t, []string{"title", "preview", "status"}, slices.Collect(maps.Keys(diff.Changes)))
+	assert.JSONEq(t, `"Till midnight"`, string(diff.Changes["preview"].Before))
+	assert.JSONEq(t, `"Till 2am"`, string(diff.Changes["preview"].After))
+
+	rec = staffRequest(t, r, token, http.MethodPost, postPath+"/revisions/"+revision+"/restore", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var restored models.News
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&restored))
+	assert.Equal(t, "Open late", restored.Title)
+	assert.Equal(t, "Till midnight", restored.Preview)
+	assert.Equal(t, models.NewsDraft, restored.Status, "a restore brings back the wording, not the status")
+
+	revisions, err = h.News.ListRevisions(context.Background(), id)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "Till 2am", revisions[0].Item.Preview, "the version a restore replaces is kept too")
+}
+
+func TestMenuRevisionRestoreKeepsAvailability(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "mila", "manager-pass", models.RoleMenuManager)
+	r := newTestRouter(t, h)
+	token := loginToken(t, h, "mila", "manager-pass")
+	ctx := context.Background()
+	id, err := h.Menu.Create(ctx, models.MenuItem{Title: "Latte", Price: 250, Availability: models.Available})
+	require.NoError(t, err)
+	require.NoError(t, h.Menu.Update(ctx, id, models.MenuItem{Title: "Latte", Price: 270, Availability: models.Available}))
+	require.NoError(t, h.Menu.SetAvailability(ctx, id, models.AvailabilityChange{Availability: models.SoldOut}))
+
+	revisions, err := h.Menu.ListRevisions(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1, "availability changes leave no revision")
+	itemPath := "/api/admin/menu/" + strconv.Itoa(id)
+
+	rec := staffRequest(t, r, token, http.MethodPost, itemPath+"/revisions/"+strconv.FormatInt(revisions[0].ID, 10)+"/restore", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var item models.MenuItem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&item))
+	assert.Equal(t, 250, item.Price)
+	assert.Equal(t, models.SoldOut, item.Availability)
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+
+	rec = staffRequest(t, r, token, http.MethodPost, itemPath+"/revisions/abc/restore", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestMenuRevisionWithDeletedCategoryIsNotRestored(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "mila", "manager-pass", models.RoleMenuManager)
+	r := newTestRouter(t, h)
+	token := loginToken(t, h, "mila", "manager-pass")
+	ctx := context.Background()
+	require.NoError(t, h.Categories.Create(ctx, models.Category{Slug: "tea", Name: "Чай", Visible: true}))
+	id, err := h.Menu.Create(ctx, models.MenuItem{Title: "Sencha", Category: "tea"})
+	require.NoError(t, err)
+	require.NoError(t, h.Menu.Update(ctx, id, models.MenuItem{Title: "Sencha"}))
+	require.NoError(t, h.Categories.Delete(ctx, "tea"))
+	revisions, err := h.Menu.ListRevisions(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+
+	rec := staffRequest(t, r, token, http.MethodPost, "/api/admin/menu/"+strconv.Itoa(id)+"/revisions/"+strconv.FormatInt(revisions[0].ID, 10)+"/restore", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "unknown", fieldCodes(t, rec)["category"])
+}
+
+func TestPurgeRemovesRevisions(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	id, err := h.Menu.Create(ctx, models.MenuItem{Title: "Croissant"})
+	require.NoError(t, err)
+	require.NoError(t, h.Menu.Update(ctx, id, models.MenuItem{Title: "Butter croissant"}))
+	require.NoError(t, h.Menu.Delete(ctx, id))
+
+	_, err = h.PurgeTrash(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	revisions, err := h.Menu.ListRevisions(ctx, id)
+	require.NoError(t, err)
+	assert.Empty(t, revisions)
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(t *testing.T, h *handlers.Handler) *mux.Router {
+	t.Helper()
+	r := mux.NewRouter()
+	require.NoError(t, handlers.Register(r, h.Routes(), h.JWTMiddleware))
+	require.NoError(t, handlers.VerifyRoutes(r))
+	return r
+}
+
+func TestRouteTablePassesSelfCheck(t *testing.T) {
+	h := newTestHandler(t)
+	for _, route := range h.Routes() {
+		if route.Method != http.MethodGet && !route.Anonymous {
+			assert.True(t, route.Auth, "%s %s must require authentication", route.Method, route.Path)
+		}
+	}
+	newTestRouter(t, h)
+}
+
+func TestRegisterRejectsUnauthenticatedWrite(t *testing.T) {
+	r := mux.NewRouter()
+	err := handlers.Register(r, []handlers.Route{
+		{Method: http.MethodDelete, Path: "/api/menu/{id}", Handler: func(w http.ResponseWriter, r *http.Request) {}},
+	}, newTestHandler(t).JWTMiddleware)
+	assert.Error(t, err)
+}
+
+func TestVerifyRoutesDetectsDirectRegistration(t *testing.T) {
+	h := newTestHandler(t)
+	r := newTestRouter(t, h)
+	r.HandleFunc("/api/menu", h.CreateMenuItemHandler).Methods(http.MethodPost)
+	assert.Error(t, handlers.VerifyRoutes(r))
+}
+
+func TestPublicMenuRoutesAreReadOnly(t *testing.T) {
+	h := newTestHandler(t)
+	r := newTestRouter(t, h)
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodPost, "/api/menu"},
+		{http.MethodPut, "/api/menu/1"},
+		{http.MethodDelete, "/api/menu/1"},
+		{http.MethodPost, "/api/news"},
+		{http.MethodPut, "/api/news/1"},
+		{http.MethodDelete, "/api/news/1"},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, bytes.NewReader([]byte(`{"title":"x"}`))))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, "%s %s", tc.method, tc.path)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/admin/menu", bytes.NewReader([]byte(`{"title":"x"}`))))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAdminRoutesEnforceRoles(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "editor", "editor-pass", models.RoleEditor)
+	r := newTestRouter(t, h)
+	token := loginToken(t, h, "editor", "editor-pass")
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/admin/menu/1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/admin/news/1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
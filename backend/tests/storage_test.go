@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/andrey-918/cafe-between/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStoragePutAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewLocal(dir, "http://cafe.test/uploads/")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "images/a.jpg", strings.NewReader("jpeg"), 4, "image/jpeg"))
+	data, err := os.ReadFile(filepath.Join(dir, "images", "a.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", string(data))
+
+	url := store.URL("images/a.jpg")
+	assert.Equal(t, "http://cafe.test/uploads/images/a.jpg", url)
+	key, ok := store.KeyFromURL(url)
+	assert.True(t, ok)
+	assert.Equal(t, "images/a.jpg", key)
+
+	_, ok = store.KeyFromURL("http://elsewhere.test/images/a.jpg")
+	assert.False(t, ok)
+	_, ok = store.KeyFromURL("http://cafe.test/uploads/../secret")
+	assert.False(t, ok)
+	assert.Error(t, store.Put(ctx, "../escape.jpg", strings.NewReader("x"), 1, "image/jpeg"))
+
+	rec := httptest.NewRecorder()
+	http.StripPrefix("/uploads/", store.Handler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/uploads/images/a.jpg", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "jpeg", rec.Body.String())
+
+	require.NoError(t, store.Delete(ctx, "images/a.jpg"))
+	assert.ErrorIs(t, store.Delete(ctx, "images/a.jpg"), storage.ErrNotFound)
+}
+
+// fakeS3 is a minimal stand-in for an S3-compatible server that keeps
+// objects in memory and insists on signed requests.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=test-key/") ||
+		!strings.Contains(auth, "/eu-test/s3/aws4_request") ||
+		!strings.Contains(auth, "Signature=") ||
+		r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3StorageAgainstStandIn(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := storage.NewS3(storage.S3Config{
+		Endpoint:        server.URL,
+		Region:          "eu-test",
+		Bucket:          "photos",
+		AccessKeyID:     "test-key",
+		SecretAccessKey: "test-secret",
+		PublicURL:       "https://cdn.cafe.test",
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "images/b.png", strings.NewReader("png-bytes"), 9, "image/png"))
+	assert.Equal(t, "png-bytes", string(fake.objects["/photos/images/b.png"]))
+	assert.Equal(t, "image/png", fake.types["/photos/images/b.png"])
+
+	url := store.URL("images/b.png")
+	assert.Equal(t, "https://cdn.cafe.test/images/b.png", url)
+	key, ok := store.KeyFromURL(url)
+	assert.True(t, ok)
+	assert.Equal(t, "images/b.png", key)
+
+	require.NoError(t, store.Delete(ctx, "images/b.png"))
+	assert.NotContains(t, fake.objects, "/photos/images/b.png")
+
+	_, err = storage.NewS3(storage.S3Config{Endpoint: server.URL, Bucket: "photos"})
+	assert.Error(t, err)
+}
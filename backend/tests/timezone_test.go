@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigPublishesCafeTimezone(t *testing.T) {
+	h := newTestHandler(t)
+	loc, err := time.LoadLocation("Asia/Yekaterinburg")
+	require.NoError(t, err)
+	h.Location = loc
+
+	rec := httptest.NewRecorder()
+	h.GetConfigHandler(rec, httptest.NewRequest(http.MethodGet, "/api/config", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var cfg struct {
+		Timezone string `json:"timezone"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&cfg))
+	assert.Equal(t, "Asia/Yekaterinburg", cfg.Timezone)
+}
+
+func TestNewsTimestampsAreServedInUTC(t *testing.T) {
+	h := newTestHandler(t)
+	// A day ahead in Moscow time, so the post stays scheduled.
+	postedAt := time.Now().Add(24 * time.Hour).Truncate(time.Second).In(time.FixedZone("MSK", 3*60*60))
+	body, _ := json.Marshal(models.News{Title: "Открытие", PostedAt: postedAt})
+	rec := httptest.NewRecorder()
+	h.CreateNewsHandler(rec, httptest.NewRequest(http.MethodPost, "/api/admin/news", bytes.NewReader(body)))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var raw map[string]any
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&raw))
+	for _, field := range []string{"createdAt", "updatedAt", "postedAt"} {
+		value, _ := raw[field].(string)
+		assert.True(t, strings.HasSuffix(value, "Z"), "%s should be UTC, got %q", field, value)
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		require.NoError(t, err, field)
+		if field == "postedAt" {
+			assert.True(t, parsed.Equal(postedAt), "postedAt should keep the instant")
+		}
+	}
+	assert.Equal(t, string(models.NewsScheduled), raw["status"])
+}
@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"`
+}
+
+func loginPair(t *testing.T, h *handlers.Handler, username, password string) tokenPair {
+	t.Helper()
+	rec := login(t, h, username, password)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var pair tokenPair
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&pair))
+	require.NotEmpty(t, pair.RefreshToken)
+	return pair
+}
+
+func refresh(t *testing.T, h *handlers.Handler, refreshToken string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"refreshToken": refreshToken})
+	rec := httptest.NewRecorder()
+	h.RefreshHandler(rec, httptest.NewRequest(http.MethodPost, "/api/auth/refresh", bytes.NewReader(body)))
+	return rec
+}
+
+func authorized(t *testing.T, r http.Handler, method, path, token string, body []byte) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestLoginIssuesShortLivedAccessToken(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "anna", "correct-horse", models.RoleEditor)
+
+	pair := loginPair(t, h, "anna", "correct-horse")
+	assert.Equal(t, 15*60, pair.ExpiresIn)
+	assert.NotEmpty(t, pair.Token)
+}
+
+func TestRefreshRotatesAndDetectsReuse(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "anna", "correct-horse", models.RoleEditor)
+	r := newTestRouter(t, h)
+	first := loginPair(t, h, "anna", "correct-horse")
+
+	rec := refresh(t, h, first.RefreshToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var second tokenPair
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&second))
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.Equal(t, http.StatusOK, authorized(t, r, http.MethodGet, "/api/admin/news", second.Token, nil))
+
+	// Replaying the rotated token revokes the whole session family.
+	assert.Equal(t, http.StatusUnauthorized, refresh(t, h, first.RefreshToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, refresh(t, h, second.RefreshToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, authorized(t, r, http.MethodGet, "/api/admin/news", second.Token, nil))
+
+	assert.Equal(t, http.StatusUnauthorized, refresh(t, h, "not-a-token").Code)
+}
+
+func TestLogoutRevokesTokens(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "anna", "correct-horse", models.RoleEditor)
+	r := newTestRouter(t, h)
+	pair := loginPair(t, h, "anna", "correct-horse")
+
+	body, _ := json.Marshal(map[string]string{"refreshToken": pair.RefreshToken})
+	require.Equal(t, http.StatusOK, authorized(t, r, http.MethodPost, "/api/logout", pair.Token, body))
+
+	assert.Equal(t, http.StatusUnauthorized, authorized(t, r, http.MethodGet, "/api/admin/news", pair.Token, nil))
+	assert.Equal(t, http.StatusUnauthorized, refresh(t, h, pair.RefreshToken).Code)
+}
+
+func TestPasswordChangeRevokesOtherSessions(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "anna", "correct-horse", models.RoleEditor)
+	r := newTestRouter(t, h)
+	laptop := loginPair(t, h, "anna", "correct-horse")
+	phone := loginPair(t, h, "anna", "correct-horse")
+
+	body, _ := json.Marshal(map[string]string{"currentPassword": "correct-horse", "newPassword": "battery-staple"})
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/password", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+laptop.Token)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var fresh tokenPair
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&fresh))
+
+	assert.Equal(t, http.StatusUnauthorized, authorized(t, r, http.MethodGet, "/api/admin/news", laptop.Token, nil))
+	assert.Equal(t, http.StatusUnauthorized, authorized(t, r, http.MethodGet, "/api/admin/news", phone.Token, nil))
+	assert.Equal(t, http.StatusUnauthorized, refresh(t, h, phone.RefreshToken).Code)
+	assert.Equal(t, http.StatusOK, authorized(t, r, http.MethodGet, "/api/admin/news", fresh.Token, nil))
+}
+
+func TestOwnerCanSignOutAllSessionsOfUser(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "owner", "owner-pass1", models.RoleOwner)
+	createTestUser(t, h, "anna", "correct-horse", models.RoleEditor)
+	r := newTestRouter(t, h)
+	owner := loginPair(t, h, "owner", "owner-pass1")
+	anna := loginPair(t, h, "anna", "correct-horse")
+
+	assert.Equal(t, http.StatusForbidden, authorized(t, r, http.MethodPost, "/api/admin/users/1/sessions/revoke", anna.Token, nil))
+	require.Equal(t, http.StatusNoContent, authorized(t, r, http.MethodPost, "/api/admin/users/2/sessions/revoke", owner.Token, nil))
+
+	assert.Equal(t, http.StatusUnauthorized, authorized(t, r, http.MethodGet, "/api/admin/news", anna.Token, nil))
+	assert.Equal(t, http.StatusOK, authorized(t, r, http.MethodGet, "/api/admin/news", owner.Token, nil))
+}
+
+func TestDeletedUserIsSignedOut(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "owner", "owner-pass1", models.RoleOwner)
+	createTestUser(t, h, "anna", "correct-horse", models.RoleEditor)
+	r := newTestRouter(t, h)
+	owner := loginPair(t, h, "owner", "owner-pass1")
+	anna := loginPair(t, h, "anna", "correct-horse")
+	require.Equal(t, http.StatusOK, authorized(t, r, http.MethodGet, "/api/admin/news", anna.Token, nil))
+
+	require.Equal(t, http.StatusNoContent, authorized(t, r, http.MethodDelete, "/api/admin/users/2", owner.Token, nil))
+
+	assert.Equal(t, http.StatusUnauthorized, authorized(t, r, http.MethodGet, "/api/admin/news", anna.Token, nil))
+	assert.Equal(t, http.StatusUnauthorized, refresh(t, h, anna.RefreshToken).Code)
+}
+
+func TestLastOwnerIsKept(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "olga", "owner-pass1", models.RoleOwner)
+	createTestUser(t, h, "anna", "correct-horse", models.RoleEditor)
+	r := newTestRouter(t, h)
+	olga := loginPair(t, h, "olga", "owner-pass1")
+
+	rec := staffRequest(t, r, olga.Token, http.MethodPut, "/api/admin/users/1", `{"role":"editor"}`)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"last_owner"`)
+	rec = staffRequest(t, r, olga.Token, http.MethodPut, "/api/admin/users/1", `{"username":"olga.k","role":"owner"}`)
+	require.Equal(t, http.StatusNoContent, rec.Code, "keeping the role is not a demotion")
+	rec = httptest.NewRecorder()
+	h.DelUserHandler(rec, mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/api/admin/users/1", nil), map[string]string{"id": "1"}))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	olga = loginPair(t, h, "olga.k", "owner-pass1")
+	require.Equal(t, http.StatusNoContent, authorized(t, r, http.MethodPut, "/api/admin/users/2", olga.Token, []byte(`{"role":"owner"}`)))
+	require.Equal(t, http.StatusNoContent, authorized(t, r, http.MethodPut, "/api/admin/users/1", olga.Token, []byte(`{"role":"editor"}`)))
+	user, err := h.Users.GetByID(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleEditor, user.Role)
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletedMenuItemsCanBeRestored(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "mila", "manager-pass", models.RoleMenuManager)
+	r := newTestRouter(t, h)
+	token := loginToken(t, h, "mila", "manager-pass")
+	ctx := context.Background()
+	id, err := h.Menu.Create(ctx, models.MenuItem{Title: "Croissant", Price: 150, Availability: models.Available})
+	require.NoError(t, err)
+	_, err = h.Menu.Create(ctx, models.MenuItem{Title: "Latte", Price: 250, Availability: models.Available})
+	require.NoError(t, err)
+	itemPath := "/api/admin/menu/" + strconv.Itoa(id)
+	restorePath := "/api/admin/trash/menu/" + strconv.Itoa(id) + "/restore"
+
+	rec := staffRequest(t, r, token, http.MethodDelete, itemPath, "")
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	assert.NotContains(t, publicMenuTitles(t, r), "Croissant")
+	rec = staffRequest(t, r, "", http.MethodGet, "/api/menu/"+strconv.Itoa(id), "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = staffRequest(t, r, token, http.MethodPut, itemPath, `{"title":"Croissant","price":160}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "trashed items cannot be edited")
+	rec = staffRequest(t, r, token, http.MethodDelete, itemPath, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = staffRequest(t, r, token, http.MethodGet, "/api/admin/trash/menu", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var trash []models.MenuItem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&trash))
+	require.Len(t, trash, 1)
+	assert.Equal(t, "Croissant", trash[0].Title)
+	assert.NotNil(t, trash[0].DeletedAt)
+
+	rec = staffRequest(t, r, token, http.MethodPost, restorePath, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var item models.MenuItem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&item))
+	assert.Equal(t, "Croissant", item.Title)
+	assert.Nil(t, item.DeletedAt)
+	assert.Contains(t, publicMenuTitles(t, r), "Croissant")
+
+	rec = staffRequest(t, r, token, http.MethodPost, restorePath, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "only trashed items can be restored")
+}
+
+func TestDeletedNewsLeavesPublicListing(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "vera", "editor-pass", models.RoleEditor)
+	r := newTestRouter(t, h)
+	token := loginToken(t, h, "vera", "editor-pass")
+	ctx := context.Background()
+	id, err := h.News.Create(ctx, models.News{Title: "Open late", Status: models.NewsPublished, PostedAt: time.Now().UTC().Add(-time.Hour)})
+	require.NoError(t, err)
+
+	publicTitles := func() []string {
+		rec := staffRequest(t, r, "", http.MethodGet, "/api/news", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var news []models.News
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&news))
+		titles := []string{}
+		for _, item := range news {
+			titles = append(titles, item.Title)
+		}
+		return titles
+	}
+	require.Equal(t, []string{"Open late"}, publicTitles())
+
+	rec := staffRequest(t, r, token, http.MethodDelete, "/api/admin/news/"+strconv.Itoa(id), "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, publicTitles())
+	rec = staffRequest(t, r, "", http.MethodGet, "/api/news/"+strconv.Itoa(id), "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = staffRequest(t, r, token, http.MethodPost, "/api/admin/trash/news/"+strconv.Itoa(id)+"/restore", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, []string{"Open late"}, publicTitles(), "a restored post keeps its status")
+}
+
+func TestPurgeTrashAfterRetention(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	itemID, err := h.Menu.Create(ctx, models.MenuItem{Title: "Croissant"})
+	require.NoError(t, err)
+	postID, err := h.News.Create(ctx, models.News{Title: "Open late"})
+	require.NoError(t, err)
+	require.NoError(t, h.Menu.Delete(ctx, itemID))
+	require.NoError(t, h.News.Delete(ctx, postID))
+
+	purged, err := h.PurgeTrash(ctx, time.Now().Add(-h.TrashRetention))
+	require.NoError(t, err)
+	assert.Zero(t, purged, "entries deleted within the retention period stay restorable")
+
+	purged, err = h.PurgeTrash(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	trash, err := h.Menu.ListDeleted(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+	assert.ErrorIs(t, h.Menu.Restore(ctx, itemID), models.ErrMenuItemNotFound)
+	assert.ErrorIs(t, h.News.Restore(ctx, postID), models.ErrNewsNotFound)
+}
+
+func TestCategoryWithTrashedItemsCannotBeDeleted(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	require.NoError(t, h.Categories.Create(ctx, models.Category{Slug: "pastry", Name: "Выпечка", Visible: true}))
+	id, err := h.Menu.Create(ctx, models.MenuItem{Title: "Croissant", Category: "pastry"})
+	require.NoError(t, err)
+	require.NoError(t, h.Menu.Delete(ctx, id))
+
+	rec := httptest.NewRecorder()
+	h.DelCategoryHandler(rec, categoryRequest(http.MethodDelete, "pastry", models.Category{}))
+	assert.Equal(t, http.StatusConflict, rec.Code, "restoring the item must find its category")
+}
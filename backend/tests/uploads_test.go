@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrey-918/cafe-between/internal/handlers"
+	"github.com/andrey-918/cafe-between/internal/imaging"
+	"github.com/andrey-918/cafe-between/internal/storage"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pngBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+	return buf.Bytes()
+}
+
+func uploadRequest(t *testing.T, field string, data []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile(field, "photo.png")
+	require.NoError(t, err)
+	_, err = fw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/uploads", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func upload(t *testing.T, h *handlers.Handler, data []byte) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.UploadHandler(rec, uploadRequest(t, "file", data))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var resp struct {
+		URL         string          `json:"url"`
+		ContentType string          `json:"contentType"`
+		Image       models.ImageSet `json:"image"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, "image/jpeg", resp.ContentType)
+	assert.Len(t, resp.Image.Variants, 3)
+	return resp.URL
+}
+
+func TestUploadStoresVariants(t *testing.T) {
+	h := newTestHandler(t)
+	url := upload(t, h, pngBytes(t))
+	assert.Contains(t, url, testUploadsURL+"/images/")
+	assert.True(t, strings.HasSuffix(url, "/full.jpg"))
+
+	key, ok := h.Storage.KeyFromURL(url)
+	require.True(t, ok)
+	for _, k := range imaging.Keys(key) {
+		assert.NoError(t, h.Storage.Delete(context.Background(), k), k)
+	}
+}
+
+func TestMenuResponsesIncludeImageSets(t *testing.T) {
+	h := newTestHandler(t)
+	uploaded := upload(t, h, pngBytes(t))
+	_, err := h.Menu.Create(context.Background(), models.MenuItem{Title: "Cake", Price: 300, ImageURLs: []string{uploaded, "http://elsewhere.test/cake.jpg"}})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.GetMenuHandler(rec, httptest.NewRequest(http.MethodGet, "/api/menu", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var page models.MenuPage
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	menu := page.Items
+	require.Len(t, menu, 1)
+	require.Len(t, menu[0].Images, 2)
+	assert.Equal(t, uploaded, menu[0].Images[0].Src)
+	assert.Contains(t, menu[0].Images[0].SrcSet["webp"], "thumb.webp 4w")
+	assert.Equal(t, "http://elsewhere.test/cake.jpg", menu[0].Images[1].Src)
+	assert.Empty(t, menu[0].Images[1].Variants)
+}
+
+func TestUploadValidation(t *testing.T) {
+	h := newTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	h.UploadHandler(rec, uploadRequest(t, "file", []byte("#!/bin/sh\necho not an image\n")))
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.UploadHandler(rec, uploadRequest(t, "other", pngBytes(t)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	h.MaxUploadSize = 16
+	rec = httptest.NewRecorder()
+	h.UploadHandler(rec, uploadRequest(t, "file", pngBytes(t)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.UploadHandler(rec, httptest.NewRequest(http.MethodPost, "/api/admin/uploads", bytes.NewReader([]byte(`{}`))))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestPurgingMenuItemRemovesOrphanedUploads(t *testing.T) {
+	h := newTestHandler(t)
+	dir := t.TempDir()
+	store, err := storage.NewLocal(dir, testUploadsURL)
+	require.NoError(t, err)
+	h.Storage = store
+	ctx := context.Background()
+	shared := upload(t, h, pngBytes(t))
+	own := upload(t, h, pngBytes(t))
+
+	id, err := h.Menu.Create(ctx, models.MenuItem{Title: "Cake", Price: 300, ImageURLs: []string{shared, own, "http://elsewhere.test/cake.jpg"}})
+	require.NoError(t, err)
+	_, err = h.News.Create(ctx, models.News{Title: "New cake", ImageURLs: []string{shared}})
+	require.NoError(t, err)
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/api/admin/menu/1", nil), map[string]string{"id": "1"})
+	rec := httptest.NewRecorder()
+	h.DelMenuItemHandler(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	_, err = h.Menu.GetByID(ctx, id)
+	require.ErrorIs(t, err, models.ErrMenuItemNotFound)
+
+	ownKey, _ := h.Storage.KeyFromURL(own)
+	sharedKey, _ := h.Storage.KeyFromURL(shared)
+	for _, k := range imaging.Keys(ownKey) {
+		assert.FileExists(t, filepath.Join(dir, k), "rendition of a trashed item must be kept for a restore")
+	}
+
+	purged, err := h.PurgeTrash(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	for _, k := range imaging.Keys(ownKey) {
+		assert.Error(t, h.Storage.Delete(ctx, k), "orphaned rendition %s should already be gone", k)
+	}
+	for _, k := range imaging.Keys(sharedKey) {
+		assert.NoError(t, h.Storage.Delete(ctx, k), "rendition %s still used by news must be kept", k)
+	}
+}
+
+func TestPurgeConsidersImagesOfRevisions(t *testing.T) {
+	h := newTestHandler(t)
+	dir := t.TempDir()
+	store, err := storage.NewLocal(dir, testUploadsURL)
+	require.NoError(t, err)
+	h.Storage = store
+	ctx := context.Background()
+	replaced := upload(t, h, pngBytes(t))
+	current := upload(t, h, pngBytes(t))
+	restorable := upload(t, h, pngBytes(t))
+
+	purgedID, err := h.Menu.Create(ctx, models.MenuItem{Title: "Cake", ImageURLs: []string{replaced, restorable}})
+	require.NoError(t, err)
+	require.NoError(t, h.Menu.Update(ctx, purgedID, models.MenuItem{Title: "Cake", ImageURLs: []string{current}}))
+	require.NoError(t, h.Menu.Delete(ctx, purgedID))
+	keptID, err := h.News.Create(ctx, models.News{Title: "Cake day", ImageURLs: []string{restorable}})
+	require.NoError(t, err)
+	require.NoError(t, h.News.Update(ctx, keptID, models.News{Title: "Cake day", ImageURLs: []string{}}))
+
+	purged, err := h.PurgeTrash(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	for _, url := range []string{replaced, current} {
+		key, _ := h.Storage.KeyFromURL(url)
+		for _, k := range imaging.Keys(key) {
+			assert.NoFileExists(t, filepath.Join(dir, k), "image of the purged item or its revisions")
+		}
+	}
+	key, _ := h.Storage.KeyFromURL(restorable)
+	for _, k := range imaging.Keys(key) {
+		assert.FileExists(t, filepath.Join(dir, k), "image a live post's revision can restore must be kept")
+	}
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andrey-918/cafe-between/internal/apierr"
+	"github.com/andrey-918/cafe-between/internal/validate"
+	"github.com/andrey-918/cafe-between/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fieldCodes decodes a 422 body into field -> code.
+func fieldCodes(t *testing.T, rec *httptest.ResponseRecorder) map[string]string {
+	t.Helper()
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	resp := decodeError[validate.Errors](t, rec)
+	assert.Equal(t, apierr.ValidationFailed, resp.Code)
+	codes := map[string]string{}
+	for _, fe := range resp.Details {
+		assert.NotEmpty(t, fe.Message, fe.Field)
+		codes[fe.Field] = fe.Code
+	}
+	return codes
+}
+
+func TestMenuItemValidationReportsEveryField(t *testing.T) {
+	h := newTestHandler(t)
+	body, _ := json.Marshal(map[string]any{
+		"title":     "   ",
+		"price":     -10,
+		"calories":  -1,
+		"imageURLs": []string{"https://cafe.test/a.jpg", "javascript:alert(1)", "/relative.png"},
+	})
+	rec := httptest.NewRecorder()
+	h.CreateMenuItemHandler(rec, httptest.NewRequest(http.MethodPost, "/api/admin/menu", bytes.NewReader(body)))
+
+	assert.Equal(t, map[string]string{
+		"title":        validate.CodeRequired,
+		"price":        validate.CodeTooSmall,
+		"calories":     validate.CodeTooSmall,
+		"imageURLs[1]": validate.CodeInvalidURL,
+		"imageURLs[2]": validate.CodeInvalidURL,
+	}, fieldCodes(t, rec))
+
+	page, err := h.Menu.List(t.Context(), models.MenuQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items, "invalid items must not be stored")
+}
+
+func TestMenuItemTitleLengthCountsCharacters(t *testing.T) {
+	h := newTestHandler(t)
+	// 255 Cyrillic letters are 510 bytes but still fit VARCHAR(255).
+	body, _ := json.Marshal(models.MenuItem{Title: strings.Repeat("ж", 255), Price: 100})
+	rec := httptest.NewRecorder()
+	h.CreateMenuItemHandler(rec, httptest.NewRequest(http.MethodPost, "/api/admin/menu", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	body, _ = json.Marshal(models.MenuItem{Title: strings.Repeat("ж", 256), Price: 100})
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPut, "/api/admin/menu/1", bytes.NewReader(body)), map[string]string{"id": "1"})
+	rec = httptest.NewRecorder()
+	h.UpdateMenuHandler(rec, req)
+	assert.Equal(t, map[string]string{"title": validate.CodeTooLong}, fieldCodes(t, rec))
+}
+
+func TestNewsPayloadValidation(t *testing.T) {
+	h := newTestHandler(t)
+	body, _ := json.Marshal(map[string]any{
+		"title":   strings.Repeat("x", 256),
+		"preview": strings.Repeat("x", models.MaxPreviewLength+1),
+		"status":  "live",
+	})
+	rec := httptest.NewRecorder()
+	h.CreateNewsHandler(rec, httptest.NewRequest(http.MethodPost, "/api/admin/news", bytes.NewReader(body)))
+	assert.Equal(t, map[string]string{
+		"title":   validate.CodeTooLong,
+		"preview": validate.CodeTooLong,
+		"status":  validate.CodeInvalid,
+	}, fieldCodes(t, rec))
+}
+
+func TestValidatorCollectsErrors(t *testing.T) {
+	var v validate.Validator
+	require.NoError(t, v.Err())
+
+	v.Range("price", 5, 10, 20)
+	v.ImageURLs("imageURLs", []string{"a", "b", "c"}, 2)
+	assert.True(t, v.Has("price"))
+	assert.False(t, v.Has("title"))
+
+	err := v.Err()
+	var errs validate.Errors
+	require.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 5)
+	assert.Contains(t, err.Error(), "price: must be at least 10")
+}
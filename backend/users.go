@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/andrey-918/cafe-between/internal/config"
+	"github.com/andrey-918/cafe-between/models"
+)
+
+// bootstrapOwner creates the first owner account from ADMIN_USERNAME and
+// ADMIN_PASSWORD when the users table is empty, so existing deployments
+// keep a way in after upgrading from the shared admin password.
+func bootstrapOwner(ctx context.Context, users models.UserRepository, cfg config.Bootstrap) error {
+	count, err := users.Count(ctx)
+	if err != nil || count > 0 {
+		return err
+	}
+	password := cfg.AdminPassword
+	if password == "" {
+		slog.Warn("no staff accounts exist; create one with `user add <username> owner`")
+		return nil
+	}
+	username := cfg.AdminUsername
+	hash, err := models.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	id, err := users.Create(ctx, models.User{Username: username, PasswordHash: hash, Role: models.RoleOwner})
+	if err != nil {
+		return err
+	}
+	slog.Warn("created owner account from ADMIN_PASSWORD; change its password and unset ADMIN_PASSWORD",
+		"username", username, "id", id, "role", models.RoleOwner)
+	return nil
+}
+
+// runUser implements the "user add <username> <role>" and "user list"
+// subcommands. The password is read from USER_PASSWORD or, if unset, from
+// the first line of stdin.
+func runUser(users models.UserRepository, args []string) {
+	ctx := context.Background()
+	if len(args) == 0 {
+		fatal("missing user command", errors.New("usage: user add <username> <role> | list"))
+	}
+	switch args[0] {
+	case "add":
+		if len(args) != 3 {
+			fatal("invalid user command", errors.New("usage: user add <username> <role>"))
+		}
+		role := models.Role(args[2])
+		if !role.Valid() {
+			fatal("invalid user command", fmt.Errorf("unknown role %q, expected one of %v", args[2], models.Roles))
+		}
+		password := os.Getenv("USER_PASSWORD")
+		if password == "" {
+			fmt.Fprint(os.Stderr, "Password: ")
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				fatal("failed to read password", err)
+			}
+			password = strings.TrimRight(line, "\r\n")
+		}
+		if password == "" {
+			fatal("invalid user command", errors.New("password must not be empty"))
+		}
+		hash, err := models.HashPassword(password)
+		if err != nil {
+			fatal("failed to hash password", err)
+		}
+		id, err := users.Create(ctx, models.User{Username: args[1], PasswordHash: hash, Role: role})
+		if err != nil {
+			fatal("failed to create user", err)
+		}
+		slog.Info("created user", "username", args[1], "id", id, "role", role)
+	case "list":
+		list, err := users.List(ctx)
+		if err != nil {
+			fatal("failed to list users", err)
+		}
+		for _, u := range list {
+			fmt.Printf("%4d  %-24s %s\n", u.ID, u.Username, u.Role)
+		}
+	default:
+		fatal("invalid user command", fmt.Errorf("unknown user command %q", args[0]))
+	}
+}
@@ -1,4 +1,5 @@
-import type { MenuItem, NewsItem } from './types';
+import type { ErrorCode } from './errorCodes';
+import type { AvailabilityChange, Category, MenuItem, MenuPage, MenuQuery, NewsItem, Revision, RevisionDiff } from './types';
 
 const API_BASE_URL = 'http://localhost:8080/api';
 
@@ -11,63 +12,273 @@ const getAuthHeaders = (): Record<string, string> => {
   return headers;
 };
 
-export const fetchMenu = async (): Promise<MenuItem[]> => {
-  const response = await fetch(`${API_BASE_URL}/menu`);
+export const storeTokens = (data: { token: string; refreshToken: string }) => {
+  localStorage.setItem('token', data.token);
+  localStorage.setItem('refreshToken', data.refreshToken);
+};
+
+export const clearTokens = () => {
+  localStorage.removeItem('token');
+  localStorage.removeItem('refreshToken');
+};
+
+export interface FieldError {
+  field: string;
+  code: string;
+  message: string;
+}
+
+// ApiError is the client side of the server's error envelope. code is one
+// of the catalogue codes in errorCodes.ts; fields lists per-field problems
+// for validation_failed and invalid_query errors so forms can point at the
+// offending inputs. requestId is worth quoting when reporting a problem.
+export class ApiError extends Error {
+  status: number;
+  code: ErrorCode;
+  requestId?: string;
+  fields: FieldError[];
+
+  constructor(message: string, status: number, code: ErrorCode = 'internal', fields: FieldError[] = [], requestId?: string) {
+    super(message);
+    this.status = status;
+    this.code = code;
+    this.fields = fields;
+    this.requestId = requestId;
+  }
+}
+
+const apiError = async (response: Response, fallback: string): Promise<ApiError> => {
+  const body = await response.json().catch(() => null);
+  const error = body?.error;
+  if (!error || typeof error.code !== 'string') {
+    return new ApiError(fallback, response.status);
+  }
+  const fields = Array.isArray(error.details) ? (error.details as FieldError[]) : [];
+  return new ApiError(error.message || fallback, response.status, error.code as ErrorCode, fields, error.requestId);
+};
+
+// Access tokens live for minutes; trade the refresh token for a new pair.
+const refreshTokens = async (): Promise<boolean> => {
+  const refreshToken = localStorage.getItem('refreshToken');
+  if (!refreshToken) {
+    return false;
+  }
+  const response = await fetch(`${API_BASE_URL}/auth/refresh`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ refreshToken }),
+  });
+  if (!response.ok) {
+    clearTokens();
+    return false;
+  }
+  storeTokens(await response.json());
+  return true;
+};
+
+// authFetch sends the access token and retries once after refreshing it
+// when the server answers 401.
+export const authFetch = async (url: string, init: RequestInit = {}): Promise<Response> => {
+  const send = () => fetch(url, { ...init, headers: { ...(init.headers as Record<string, string>), ...getAuthHeaders() } });
+  const response = await send();
+  if (response.status !== 401 || !(await refreshTokens())) {
+    return response;
+  }
+  return send();
+};
+
+// fetchMenu loads one page of the menu. Staff pages pass admin to also see
+// hidden categories.
+export const fetchMenu = async (query: MenuQuery = {}, admin = false): Promise<MenuPage> => {
+  const params = new URLSearchParams();
+  Object.entries(query).forEach(([key, value]) => {
+    if (value !== undefined && value !== '') {
+      params.set(key, String(value));
+    }
+  });
+  const response = admin
+    ? await authFetch(`${API_BASE_URL}/admin/menu?${params}`)
+    : await fetch(`${API_BASE_URL}/menu?${params}`);
   if (!response.ok) {
-    throw new Error('Failed to fetch menu');
+    throw await apiError(response, 'Failed to fetch menu');
   }
   return response.json();
 };
 
+// fetchAllMenu follows nextCursor until every matching item is loaded.
+export const fetchAllMenu = async (query: MenuQuery = {}, admin = false): Promise<MenuPage> => {
+  const items: MenuItem[] = [];
+  let categories: Category[] = [];
+  let cursor: string | undefined;
+  do {
+    const page = await fetchMenu({ ...query, limit: 100, cursor }, admin);
+    items.push(...page.items);
+    categories = page.categories;
+    cursor = page.nextCursor;
+  } while (cursor);
+  return { items, categories };
+};
+
 export const fetchMenuItem = async (id: number): Promise<MenuItem> => {
   const response = await fetch(`${API_BASE_URL}/menu/${id}`);
   if (!response.ok) {
-    throw new Error('Failed to fetch menu item');
+    throw await apiError(response, 'Failed to fetch menu item');
   }
   return response.json();
 };
 
 export const createMenuItem = async (item: Omit<MenuItem, 'id' | 'createdAt' | 'updatedAt'>): Promise<MenuItem> => {
-  const headers = getAuthHeaders();
-  headers['Content-Type'] = 'application/json';
-  const response = await fetch(`${API_BASE_URL}/admin/menu`, {
+  const headers = { 'Content-Type': 'application/json' };
+  const response = await authFetch(`${API_BASE_URL}/admin/menu`, {
     method: 'POST',
     headers,
     body: JSON.stringify(item),
   });
   if (!response.ok) {
-    throw new Error('Failed to create menu item');
+    throw await apiError(response, 'Failed to create menu item');
   }
   return response.json();
 };
 
-export const updateMenuItem = async (id: number, item: Omit<MenuItem, 'id' | 'createdAt' | 'updatedAt'>): Promise<void> => {
-  const headers = getAuthHeaders();
-  headers['Content-Type'] = 'application/json';
-  const response = await fetch(`${API_BASE_URL}/admin/menu/${id}`, {
-    method: 'PUT',
+// updateMenuItem sends the form as a JSON Merge Patch. updatedAt is the version
+// the form was filled from; if someone saved the item since, the server
+// answers with an edit_conflict error instead of overwriting their change.
+export const updateMenuItem = async (id: number, item: Omit<MenuItem, 'id' | 'createdAt' | 'updatedAt'>, updatedAt: string): Promise<void> => {
+  const headers = { 'Content-Type': 'application/merge-patch+json' };
+  const response = await authFetch(`${API_BASE_URL}/admin/menu/${id}`, {
+    method: 'PATCH',
     headers,
-    body: JSON.stringify(item),
+    body: JSON.stringify({ ...item, updatedAt }),
   });
   if (!response.ok) {
-    throw new Error('Failed to update menu item');
+    throw await apiError(response, 'Failed to update menu item');
   }
 };
 
 export const deleteMenuItem = async (id: number): Promise<void> => {
-  const response = await fetch(`${API_BASE_URL}/admin/menu/${id}`, {
+  const response = await authFetch(`${API_BASE_URL}/admin/menu/${id}`, {
+    method: 'DELETE',
+  });
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to delete menu item');
+  }
+};
+
+// setMenuItemAvailability moves an item on or off the stop-list. Baristas may
+// only switch between available and sold_out.
+export const setMenuItemAvailability = async (id: number, change: AvailabilityChange): Promise<MenuItem> => {
+  const headers = { 'Content-Type': 'application/json' };
+  const response = await authFetch(`${API_BASE_URL}/admin/menu/${id}/availability`, {
+    method: 'PUT',
+    headers,
+    body: JSON.stringify(change),
+  });
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to update availability');
+  }
+  return response.json();
+};
+
+// fetchStopList loads the items currently sold out for the day.
+export const fetchStopList = async (): Promise<MenuItem[]> => {
+  const response = await authFetch(`${API_BASE_URL}/admin/stop-list`);
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to fetch stop-list');
+  }
+  return response.json();
+};
+
+// fetchMenuTrash lists deleted menu items that can still be restored.
+export const fetchMenuTrash = async (): Promise<MenuItem[]> => {
+  const response = await authFetch(`${API_BASE_URL}/admin/trash/menu`);
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to fetch deleted menu items');
+  }
+  return response.json();
+};
+
+export const restoreMenuItem = async (id: number): Promise<MenuItem> => {
+  const response = await authFetch(`${API_BASE_URL}/admin/trash/menu/${id}/restore`, {
+    method: 'POST',
+  });
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to restore menu item');
+  }
+  return response.json();
+};
+
+export const fetchCategories = async (admin = false): Promise<Category[]> => {
+  const response = admin
+    ? await authFetch(`${API_BASE_URL}/admin/categories`)
+    : await fetch(`${API_BASE_URL}/categories`);
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to fetch categories');
+  }
+  return response.json();
+};
+
+export const createCategory = async (category: Omit<Category, 'createdAt' | 'updatedAt'>): Promise<Category> => {
+  const headers = { 'Content-Type': 'application/json' };
+  const response = await authFetch(`${API_BASE_URL}/admin/categories`, {
+    method: 'POST',
+    headers,
+    body: JSON.stringify(category),
+  });
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to create category');
+  }
+  return response.json();
+};
+
+export const updateCategory = async (slug: string, category: Omit<Category, 'slug' | 'createdAt' | 'updatedAt'>): Promise<void> => {
+  const headers = { 'Content-Type': 'application/json' };
+  const response = await authFetch(`${API_BASE_URL}/admin/categories/${slug}`, {
+    method: 'PUT',
+    headers,
+    body: JSON.stringify(category),
+  });
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to update category');
+  }
+};
+
+export const deleteCategory = async (slug: string): Promise<void> => {
+  const response = await authFetch(`${API_BASE_URL}/admin/categories/${slug}`, {
     method: 'DELETE',
-    headers: getAuthHeaders(),
   });
   if (!response.ok) {
-    throw new Error('Failed to delete menu item');
+    throw await apiError(response, 'Failed to delete category');
+  }
+};
+
+// ClientConfig is the deployment configuration published at /api/config.
+export interface ClientConfig {
+  // timezone is the cafe's IANA timezone, e.g. "Europe/Moscow".
+  timezone: string;
+}
+
+export const fetchConfig = async (): Promise<ClientConfig> => {
+  const response = await fetch(`${API_BASE_URL}/config`);
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to fetch config');
   }
+  return response.json();
 };
 
 export const fetchNews = async (): Promise<NewsItem[]> => {
   const response = await fetch(`${API_BASE_URL}/news`);
   if (!response.ok) {
-    throw new Error('Failed to fetch news');
+    throw await apiError(response, 'Failed to fetch news');
+  }
+  return response.json();
+};
+
+// fetchAdminNews returns every post whatever its status; the public
+// endpoint only lists live ones.
+export const fetchAdminNews = async (): Promise<NewsItem[]> => {
+  const response = await authFetch(`${API_BASE_URL}/admin/news`);
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to fetch news');
   }
   return response.json();
 };
@@ -75,44 +286,112 @@ export const fetchNews = async (): Promise<NewsItem[]> => {
 export const fetchNewsItem = async (id: number): Promise<NewsItem> => {
   const response = await fetch(`${API_BASE_URL}/news/${id}`);
   if (!response.ok) {
-    throw new Error('Failed to fetch news item');
+    throw await apiError(response, 'Failed to fetch news item');
   }
   return response.json();
 };
 
 export const createNewsItem = async (item: Omit<NewsItem, 'id' | 'createdAt' | 'updatedAt'>): Promise<NewsItem> => {
-  const headers = getAuthHeaders();
-  headers['Content-Type'] = 'application/json';
-  const response = await fetch(`${API_BASE_URL}/admin/news`, {
+  const headers = { 'Content-Type': 'application/json' };
+  const response = await authFetch(`${API_BASE_URL}/admin/news`, {
     method: 'POST',
     headers,
     body: JSON.stringify(item),
   });
   if (!response.ok) {
-    throw new Error('Failed to create news item');
+    throw await apiError(response, 'Failed to create news item');
   }
   return response.json();
 };
 
-export const updateNewsItem = async (id: number, item: Omit<NewsItem, 'id' | 'createdAt' | 'updatedAt'>): Promise<void> => {
-  const headers = getAuthHeaders();
-  headers['Content-Type'] = 'application/json';
-  const response = await fetch(`${API_BASE_URL}/admin/news/${id}`, {
-    method: 'PUT',
+// updateNewsItem sends the form as a JSON Merge Patch. updatedAt is the version
+// the form was filled from; if someone saved the post since, the server
+// answers with an edit_conflict error instead of overwriting their change.
+export const updateNewsItem = async (id: number, item: Omit<NewsItem, 'id' | 'createdAt' | 'updatedAt'>, updatedAt: string): Promise<void> => {
+  const headers = { 'Content-Type': 'application/merge-patch+json' };
+  const response = await authFetch(`${API_BASE_URL}/admin/news/${id}`, {
+    method: 'PATCH',
     headers,
-    body: JSON.stringify(item),
+    body: JSON.stringify({ ...item, updatedAt }),
   });
   if (!response.ok) {
-    throw new Error('Failed to update news item');
+    throw await apiError(response, 'Failed to update news item');
   }
 };
 
 export const deleteNewsItem = async (id: number): Promise<void> => {
-  const response = await fetch(`${API_BASE_URL}/admin/news/${id}`, {
+  const response = await authFetch(`${API_BASE_URL}/admin/news/${id}`, {
     method: 'DELETE',
-    headers: getAuthHeaders(),
   });
   if (!response.ok) {
-    throw new Error('Failed to delete news item');
+    throw await apiError(response, 'Failed to delete news item');
+  }
+};
+
+// fetchNewsTrash lists deleted news posts that can still be restored.
+export const fetchNewsTrash = async (): Promise<NewsItem[]> => {
+  const response = await authFetch(`${API_BASE_URL}/admin/trash/news`);
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to fetch deleted news');
+  }
+  return response.json();
+};
+
+export const restoreNewsItem = async (id: number): Promise<NewsItem> => {
+  const response = await authFetch(`${API_BASE_URL}/admin/trash/news/${id}/restore`, {
+    method: 'POST',
+  });
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to restore news item');
+  }
+  return response.json();
+};
+
+// fetchNewsRevisions lists the earlier versions of a post, newest first.
+export const fetchNewsRevisions = async (id: number): Promise<Revision<NewsItem>[]> => {
+  const response = await authFetch(`${API_BASE_URL}/admin/news/${id}/revisions`);
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to fetch revisions');
+  }
+  return response.json();
+};
+
+// diffNewsRevisions compares revision from with revision to or, without
+// one, with the post as it is now.
+export const diffNewsRevisions = async (id: number, from: number, to?: number): Promise<RevisionDiff> => {
+  const params = new URLSearchParams({ from: String(from) });
+  if (to !== undefined) {
+    params.set('to', String(to));
+  }
+  const response = await authFetch(`${API_BASE_URL}/admin/news/${id}/revisions/diff?${params}`);
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to compare revisions');
+  }
+  return response.json();
+};
+
+// restoreNewsRevision brings back the wording of an earlier version; the
+// post keeps its status.
+export const restoreNewsRevision = async (id: number, revision: number): Promise<NewsItem> => {
+  const response = await authFetch(`${API_BASE_URL}/admin/news/${id}/revisions/${revision}/restore`, {
+    method: 'POST',
+  });
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to restore revision');
+  }
+  return response.json();
+};
+
+export const uploadImage = async (file: File): Promise<string> => {
+  const body = new FormData();
+  body.append('file', file);
+  const response = await authFetch(`${API_BASE_URL}/admin/uploads`, {
+    method: 'POST',
+    body,
+  });
+  if (!response.ok) {
+    throw await apiError(response, 'Failed to upload image');
   }
+  const data = await response.json();
+  return data.url;
 };
@@ -0,0 +1,24 @@
+import type { FieldError } from '../api';
+
+interface FieldErrorsProps {
+  errors: FieldError[];
+  field: string;
+}
+
+// FieldErrors lists the server's complaints about one input, including
+// those about its list entries such as imageURLs[1].
+export function FieldErrors({ errors, field }: FieldErrorsProps) {
+  const matching = errors.filter((e) => e.field === field || e.field.startsWith(`${field}[`));
+  if (matching.length === 0) {
+    return null;
+  }
+  return (
+    <div className="field-errors">
+      {matching.map((e) => (
+        <small key={`${e.field}-${e.code}`} className="field-error">
+          {e.field !== field ? `${e.field}: ` : ''}{e.message}
+        </small>
+      ))}
+    </div>
+  );
+}
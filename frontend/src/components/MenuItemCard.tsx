@@ -1,5 +1,10 @@
 import { Link } from 'react-router-dom';
 import { ImageWithFallback } from './ImageWithFallback';
+import type { ImageSet } from '../types';
+
+// Cards are at most a third of the viewport on desktop, so the browser
+// never needs the full-size rendition here.
+const CARD_IMAGE_SIZES = '(max-width: 640px) 100vw, (max-width: 1024px) 50vw, 33vw';
 
 interface MenuItemCardProps {
   id: number;
@@ -8,8 +13,10 @@ interface MenuItemCardProps {
   price: string;
   calories?: number;
   image?: string;
+  imageSet?: ImageSet;
   variants?: string[];
   popular?: boolean;
+  soldOut?: boolean;
 }
 
 export function MenuItemCard({
@@ -19,19 +26,34 @@ export function MenuItemCard({
   price,
   calories,
   image,
+  imageSet,
   variants,
-  popular
+  popular,
+  soldOut
 }: MenuItemCardProps) {
+  const small = imageSet?.variants?.filter((v) => v.name !== 'full');
+  const srcSet = (format: 'webp' | 'jpeg') =>
+    small?.map((v) => `${v[format]} ${v.width}w`).join(', ');
+  const src = small?.length ? small[small.length - 1].jpeg : image;
+
   return (
     <Link to={`/menu/${id}`} className="menu-item-card-link">
-      <article className="menu-item-card">
-        {image && (
+      <article className={soldOut ? 'menu-item-card menu-item-card-sold-out' : 'menu-item-card'}>
+        {src && (
           <div className="menu-item-card-image">
-            <ImageWithFallback
-              src={image}
-              alt={name}
-              className="menu-item-card-image-img"
-            />
+            <picture>
+              {small?.length ? (
+                <source type="image/webp" srcSet={srcSet('webp')} sizes={CARD_IMAGE_SIZES} />
+              ) : null}
+              <ImageWithFallback
+                src={src}
+                srcSet={small?.length ? srcSet('jpeg') : undefined}
+                sizes={small?.length ? CARD_IMAGE_SIZES : undefined}
+                alt={name}
+                loading="lazy"
+                className="menu-item-card-image-img"
+              />
+            </picture>
           </div>
         )}
 
@@ -43,7 +65,11 @@ export function MenuItemCard({
                 <span className="menu-item-card-popular">★</span>
               )}
             </div>
-            <span className="menu-item-card-price">{price} ₽</span>
+            {soldOut ? (
+              <span className="menu-item-card-sold-out-badge">Закончилось</span>
+            ) : (
+              <span className="menu-item-card-price">{price} ₽</span>
+            )}
           </div>
 
           <p className="menu-item-card-description">
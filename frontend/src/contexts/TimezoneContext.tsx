@@ -0,0 +1,105 @@
+import { createContext, useContext, useEffect, useState } from 'react';
+import { fetchConfig } from '../api';
+
+// The API stores and serves every timestamp in UTC. Dates are shown, and
+// the admin forms are filled in, in the cafe's timezone, whatever the
+// browser's own zone is.
+
+const FALLBACK_TIMEZONE = 'Europe/Moscow';
+
+interface TimezoneContextType {
+  timezone: string;
+  // formatDate renders an API timestamp as a date in the cafe's timezone.
+  formatDate: (iso: string) => string;
+  // formatDateTime renders an API timestamp with the time of day.
+  formatDateTime: (iso: string) => string;
+  // toInputValue turns an API timestamp into a datetime-local value.
+  toInputValue: (iso: string | Date) => string;
+  // fromInputValue reads a datetime-local value as cafe time and returns
+  // the RFC 3339 instant the API expects.
+  fromInputValue: (value: string) => string;
+}
+
+const TimezoneContext = createContext<TimezoneContextType | undefined>(undefined);
+
+export const useTimezone = () => {
+  const context = useContext(TimezoneContext);
+  if (!context) {
+    throw new Error('useTimezone must be used within a TimezoneProvider');
+  }
+  return context;
+};
+
+// wallClock returns the calendar fields of date as seen in timeZone.
+const wallClock = (date: Date, timeZone: string) => {
+  const parts = new Intl.DateTimeFormat('en-US', {
+    timeZone,
+    hourCycle: 'h23',
+    year: 'numeric',
+    month: '2-digit',
+    day: '2-digit',
+    hour: '2-digit',
+    minute: '2-digit',
+    second: '2-digit',
+  }).formatToParts(date);
+  const field = (type: string) => Number(parts.find((p) => p.type === type)?.value);
+  return {
+    year: field('year'),
+    month: field('month'),
+    day: field('day'),
+    hour: field('hour'),
+    minute: field('minute'),
+    second: field('second'),
+  };
+};
+
+// offsetAt is timeZone's UTC offset, in milliseconds, at the given instant.
+const offsetAt = (ms: number, timeZone: string) => {
+  const c = wallClock(new Date(ms), timeZone);
+  const asUTC = Date.UTC(c.year, c.month - 1, c.day, c.hour, c.minute, c.second);
+  return asUTC - Math.floor(ms / 1000) * 1000;
+};
+
+const pad = (n: number) => String(n).padStart(2, '0');
+
+export const toInputValue = (value: string | Date, timeZone: string) => {
+  const c = wallClock(new Date(value), timeZone);
+  return `${c.year}-${pad(c.month)}-${pad(c.day)}T${pad(c.hour)}:${pad(c.minute)}`;
+};
+
+export const fromInputValue = (value: string, timeZone: string) => {
+  const [date, time = '00:00'] = value.split('T');
+  const [year, month, day] = date.split('-').map(Number);
+  const [hour, minute] = time.split(':').map(Number);
+  const wall = Date.UTC(year, month - 1, day, hour, minute);
+  // The offset depends on the instant, so settle it against the first guess.
+  let ms = wall - offsetAt(wall, timeZone);
+  ms = wall - offsetAt(ms, timeZone);
+  return new Date(ms).toISOString();
+};
+
+export const TimezoneProvider: React.FC<{ children: React.ReactNode }> = ({ children }) => {
+  const [timezone, setTimezone] = useState(FALLBACK_TIMEZONE);
+
+  useEffect(() => {
+    fetchConfig()
+      .then((config) => setTimezone(config.timezone))
+      .catch(() => {
+        // Keep the fallback; dates are still correct instants.
+      });
+  }, []);
+
+  const value: TimezoneContextType = {
+    timezone,
+    formatDate: (iso) => new Date(iso).toLocaleDateString('ru-RU', { timeZone: timezone }),
+    formatDateTime: (iso) => new Date(iso).toLocaleString('ru-RU', { timeZone: timezone }),
+    toInputValue: (iso) => toInputValue(iso, timezone),
+    fromInputValue: (v) => fromInputValue(v, timezone),
+  };
+
+  return (
+    <TimezoneContext.Provider value={value}>
+      {children}
+    </TimezoneContext.Provider>
+  );
+};
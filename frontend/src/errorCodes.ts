@@ -0,0 +1,272 @@
+// Code generated by backend/cmd/gen-errors from the apierr catalogue. DO NOT EDIT.
+
+export type ErrorCode =
+  | "internal"
+  | "invalid_json"
+  | "invalid_id"
+  | "invalid_query"
+  | "validation_failed"
+  | "route_not_found"
+  | "method_not_allowed"
+  | "missing_token"
+  | "invalid_token"
+  | "token_revoked"
+  | "invalid_credentials"
+  | "invalid_refresh_token"
+  | "refresh_token_expired"
+  | "wrong_password"
+  | "forbidden"
+  | "menu_item_not_found"
+  | "news_not_found"
+  | "revision_not_found"
+  | "category_not_found"
+  | "category_exists"
+  | "category_in_use"
+  | "user_not_found"
+  | "username_taken"
+  | "cannot_delete_self"
+  | "last_owner"
+  | "invalid_multipart"
+  | "missing_file"
+  | "file_too_large"
+  | "unsupported_media_type"
+  | "invalid_image"
+  | "unsupported_content_type"
+  | "precondition_required"
+  | "edit_conflict";
+
+export type ErrorLanguage = "ru" | "en";
+
+export const errorCatalogue: Record<ErrorCode, { status: number; messages: Record<ErrorLanguage, string> }> = {
+  "internal": {
+    status: 500,
+    messages: {
+      ru: "Внутренняя ошибка сервера",
+      en: "Internal server error",
+    },
+  },
+  "invalid_json": {
+    status: 400,
+    messages: {
+      ru: "Некорректный JSON в теле запроса",
+      en: "Invalid JSON",
+    },
+  },
+  "invalid_id": {
+    status: 400,
+    messages: {
+      ru: "Некорректный идентификатор",
+      en: "Invalid ID",
+    },
+  },
+  "invalid_query": {
+    status: 400,
+    messages: {
+      ru: "Некорректные параметры запроса",
+      en: "Invalid query parameters",
+    },
+  },
+  "validation_failed": {
+    status: 422,
+    messages: {
+      ru: "Проверьте правильность заполнения полей",
+      en: "Validation failed",
+    },
+  },
+  "route_not_found": {
+    status: 404,
+    messages: {
+      ru: "Адрес не найден",
+      en: "Not found",
+    },
+  },
+  "method_not_allowed": {
+    status: 405,
+    messages: {
+      ru: "Метод не поддерживается",
+      en: "Method not allowed",
+    },
+  },
+  "missing_token": {
+    status: 401,
+    messages: {
+      ru: "Требуется вход в систему",
+      en: "Missing token",
+    },
+  },
+  "invalid_token": {
+    status: 401,
+    messages: {
+      ru: "Сессия недействительна, войдите снова",
+      en: "Invalid token",
+    },
+  },
+  "token_revoked": {
+    status: 401,
+    messages: {
+      ru: "Сессия завершена, войдите снова",
+      en: "Token revoked",
+    },
+  },
+  "invalid_credentials": {
+    status: 401,
+    messages: {
+      ru: "Неверное имя пользователя или пароль",
+      en: "Invalid username or password",
+    },
+  },
+  "invalid_refresh_token": {
+    status: 401,
+    messages: {
+      ru: "Сессия недействительна, войдите снова",
+      en: "Invalid refresh token",
+    },
+  },
+  "refresh_token_expired": {
+    status: 401,
+    messages: {
+      ru: "Сессия истекла, войдите снова",
+      en: "Refresh token expired",
+    },
+  },
+  "wrong_password": {
+    status: 401,
+    messages: {
+      ru: "Неверный текущий пароль",
+      en: "Invalid password",
+    },
+  },
+  "forbidden": {
+    status: 403,
+    messages: {
+      ru: "Недостаточно прав",
+      en: "Forbidden",
+    },
+  },
+  "menu_item_not_found": {
+    status: 404,
+    messages: {
+      ru: "Позиция меню не найдена",
+      en: "Menu item not found",
+    },
+  },
+  "news_not_found": {
+    status: 404,
+    messages: {
+      ru: "Новость не найдена",
+      en: "News item not found",
+    },
+  },
+  "revision_not_found": {
+    status: 404,
+    messages: {
+      ru: "Версия не найдена",
+      en: "Revision not found",
+    },
+  },
+  "category_not_found": {
+    status: 404,
+    messages: {
+      ru: "Категория не найдена",
+      en: "Category not found",
+    },
+  },
+  "category_exists": {
+    status: 409,
+    messages: {
+      ru: "Категория с таким кодом уже существует",
+      en: "Category already exists",
+    },
+  },
+  "category_in_use": {
+    status: 409,
+    messages: {
+      ru: "В категории есть позиции меню",
+      en: "Category still has menu items",
+    },
+  },
+  "user_not_found": {
+    status: 404,
+    messages: {
+      ru: "Пользователь не найден",
+      en: "User not found",
+    },
+  },
+  "username_taken": {
+    status: 409,
+    messages: {
+      ru: "Имя пользователя уже занято",
+      en: "Username already taken",
+    },
+  },
+  "cannot_delete_self": {
+    status: 400,
+    messages: {
+      ru: "Нельзя удалить собственную учётную запись",
+      en: "You cannot delete your own account",
+    },
+  },
+  "last_owner": {
+    status: 409,
+    messages: {
+      ru: "Должен остаться хотя бы один владелец",
+      en: "At least one owner must remain",
+    },
+  },
+  "invalid_multipart": {
+    status: 400,
+    messages: {
+      ru: "Ожидается корректное тело multipart/form-data",
+      en: "Expected a well-formed multipart/form-data body",
+    },
+  },
+  "missing_file": {
+    status: 400,
+    messages: {
+      ru: "Файл не передан",
+      en: "Missing file field",
+    },
+  },
+  "file_too_large": {
+    status: 413,
+    messages: {
+      ru: "Файл слишком большой",
+      en: "File too large",
+    },
+  },
+  "unsupported_media_type": {
+    status: 415,
+    messages: {
+      ru: "Неподдерживаемый тип файла",
+      en: "Unsupported file type",
+    },
+  },
+  "invalid_image": {
+    status: 422,
+    messages: {
+      ru: "Не удалось прочитать изображение",
+      en: "Invalid image",
+    },
+  },
+  "unsupported_content_type": {
+    status: 415,
+    messages: {
+      ru: "Неподдерживаемый формат тела запроса",
+      en: "Unsupported request content type",
+    },
+  },
+  "precondition_required": {
+    status: 428,
+    messages: {
+      ru: "Укажите версию, которую вы изменяете (If-Match или updatedAt)",
+      en: "Send If-Match or updatedAt with the version you are editing",
+    },
+  },
+  "edit_conflict": {
+    status: 412,
+    messages: {
+      ru: "Запись уже изменил кто-то другой. Обновите страницу и повторите",
+      en: "Someone else has changed this record; reload and try again",
+    },
+  },
+};
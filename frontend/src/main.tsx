@@ -2,6 +2,7 @@ import { StrictMode } from 'react'
 import { createRoot } from 'react-dom/client'
 import { AuthProvider } from './contexts/AuthContext'
 import { ThemeProvider } from './contexts/ThemeContext'
+import { TimezoneProvider } from './contexts/TimezoneContext'
 import './style/index.css'
 import './style/footer.css'
 import App from './App.tsx'
@@ -9,9 +10,11 @@ import App from './App.tsx'
 createRoot(document.getElementById('root')!).render(
   <StrictMode>
     <ThemeProvider>
-      <AuthProvider>
-        <App />
-      </AuthProvider>
+      <TimezoneProvider>
+        <AuthProvider>
+          <App />
+        </AuthProvider>
+      </TimezoneProvider>
     </ThemeProvider>
   </StrictMode>,
 )
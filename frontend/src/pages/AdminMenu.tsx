@@ -1,12 +1,26 @@
 import { useEffect, useState } from 'react';
-import type { MenuItem } from '../types';
-import { fetchMenu, createMenuItem, updateMenuItem, deleteMenuItem } from '../api';
+import type { Availability, Category, MenuItem } from '../types';
+import { ApiError, fetchAllMenu, fetchMenuTrash, createMenuItem, updateMenuItem, deleteMenuItem, restoreMenuItem, setMenuItemAvailability, uploadImage } from '../api';
+import type { FieldError } from '../api';
+import { FieldErrors } from '../components/FieldErrors';
+import { useTimezone } from '../contexts/TimezoneContext';
+
+const availabilityLabels: Record<Availability, string> = {
+  available: 'Available',
+  sold_out: 'Sold out today',
+  hidden: 'Hidden',
+  seasonal: 'Seasonal',
+};
 
 const AdminMenu = () => {
   const [menu, setMenu] = useState<MenuItem[]>([]);
+  const [trash, setTrash] = useState<MenuItem[]>([]);
+  const [categories, setCategories] = useState<Category[]>([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
+  const [fieldErrors, setFieldErrors] = useState<FieldError[]>([]);
   const [editingItem, setEditingItem] = useState<MenuItem | null>(null);
+  const { formatDateTime } = useTimezone();
   const [formData, setFormData] = useState({
     title: '',
     price: 0,
@@ -14,6 +28,9 @@ const AdminMenu = () => {
     calories: 0,
     description: '',
     category: '',
+    availability: 'available' as Availability,
+    seasonStart: '',
+    seasonEnd: '',
   });
 
   useEffect(() => {
@@ -22,8 +39,10 @@ const AdminMenu = () => {
 
   const loadMenu = async () => {
     try {
-      const data = await fetchMenu();
-      setMenu(data);
+      const [data, deleted] = await Promise.all([fetchAllMenu({}, true), fetchMenuTrash()]);
+      setMenu(data.items);
+      setCategories(data.categories);
+      setTrash(deleted);
     } catch (err) {
       setError('Failed to load menu');
     } finally {
@@ -35,13 +54,17 @@ const AdminMenu = () => {
     e.preventDefault();
     try {
       if (editingItem) {
-        await updateMenuItem(editingItem.id, formData);
+        await updateMenuItem(editingItem.id, formData, editingItem.updatedAt);
       } else {
         await createMenuItem(formData);
       }
       loadMenu();
       resetForm();
     } catch (err) {
+      if (err instanceof ApiError && err.fields.length > 0) {
+        setFieldErrors(err.fields);
+        return;
+      }
       setError('Failed to save item');
     }
   };
@@ -55,11 +78,14 @@ const AdminMenu = () => {
       calories: item.calories || 0,
       description: item.description || '',
       category: item.category || '',
+      availability: item.availability || 'available',
+      seasonStart: item.seasonStart || '',
+      seasonEnd: item.seasonEnd || '',
     });
   };
 
   const handleDelete = async (id: number) => {
-    if (confirm('Are you sure you want to delete this item?')) {
+    if (confirm('Move this item to the trash? It can be restored below.')) {
       try {
         await deleteMenuItem(id);
         loadMenu();
@@ -69,8 +95,30 @@ const AdminMenu = () => {
     }
   };
 
+  const handleRestore = async (id: number) => {
+    try {
+      await restoreMenuItem(id);
+      loadMenu();
+    } catch (err) {
+      setError('Failed to restore item');
+    }
+  };
+
+  // toggleSoldOut puts an item on the stop-list for the rest of the day, or
+  // takes it back off. The server resets the stop-list at midnight.
+  const toggleSoldOut = async (item: MenuItem) => {
+    try {
+      const availability = item.availability === 'sold_out' ? 'available' : 'sold_out';
+      await setMenuItemAvailability(item.id, { availability });
+      loadMenu();
+    } catch (err) {
+      setError('Failed to update availability');
+    }
+  };
+
   const resetForm = () => {
     setEditingItem(null);
+    setFieldErrors([]);
     setFormData({
       title: '',
       price: 0,
@@ -78,6 +126,9 @@ const AdminMenu = () => {
       calories: 0,
       description: '',
       category: '',
+      availability: 'available',
+      seasonStart: '',
+      seasonEnd: '',
     });
   };
 
@@ -91,6 +142,21 @@ const AdminMenu = () => {
     setFormData({ ...formData, imageURLs: newURLs });
   };
 
+  const handleUpload = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    const file = e.target.files?.[0];
+    e.target.value = '';
+    if (!file) {
+      return;
+    }
+    try {
+      const url = await uploadImage(file);
+      const urls = formData.imageURLs.filter((u) => u !== '');
+      setFormData({ ...formData, imageURLs: [...urls, url] });
+    } catch (err) {
+      setError('Failed to upload image');
+    }
+  };
+
   const removeImageURL = (index: number) => {
     if (formData.imageURLs.length > 1) {
       const newURLs = formData.imageURLs.filter((_, i) => i !== index);
@@ -108,6 +174,7 @@ const AdminMenu = () => {
           <h3>{editingItem ? 'Edit Item' : 'Add New Item'}</h3>
           <div className="form-group">
             <label>Title:</label>
+            <FieldErrors errors={fieldErrors} field="title" />
             <input
               type="text"
               value={formData.title}
@@ -117,6 +184,7 @@ const AdminMenu = () => {
           </div>
           <div className="form-group">
             <label>Price:</label>
+            <FieldErrors errors={fieldErrors} field="price" />
             <input
               type="number"
               value={formData.price}
@@ -126,6 +194,7 @@ const AdminMenu = () => {
           </div>
           <div className="form-group">
             <label>Image URLs:</label>
+            <FieldErrors errors={fieldErrors} field="imageURLs" />
             {formData.imageURLs.map((url, index) => (
               <div key={index} className="image-url-group">
                 <input
@@ -145,9 +214,11 @@ const AdminMenu = () => {
               </div>
             ))}
             <button type="button" onClick={addImageURL}>Add Image URL</button>
+            <input type="file" accept="image/jpeg,image/png,image/webp,image/gif" onChange={handleUpload} />
           </div>
           <div className="form-group">
             <label>Calories:</label>
+            <FieldErrors errors={fieldErrors} field="calories" />
             <input
               type="number"
               value={formData.calories}
@@ -156,15 +227,52 @@ const AdminMenu = () => {
           </div>
           <div className="form-group">
             <label>Category:</label>
-            <input
-              type="text"
+            <FieldErrors errors={fieldErrors} field="category" />
+            <select
               value={formData.category}
               onChange={(e) => setFormData({ ...formData, category: e.target.value })}
               required
-            />
+            >
+              <option value="">—</option>
+              {categories.map((c) => (
+                <option key={c.slug} value={c.slug}>
+                  {c.name}{c.visible ? '' : ' (hidden)'}
+                </option>
+              ))}
+            </select>
           </div>
+          <div className="form-group">
+            <label>Availability:</label>
+            <FieldErrors errors={fieldErrors} field="availability" />
+            <select
+              value={formData.availability}
+              onChange={(e) => setFormData({ ...formData, availability: e.target.value as Availability })}
+            >
+              {Object.entries(availabilityLabels).map(([value, label]) => (
+                <option key={value} value={value}>{label}</option>
+              ))}
+            </select>
+          </div>
+          {formData.availability === 'seasonal' && (
+            <div className="form-group">
+              <label>Season:</label>
+              <FieldErrors errors={fieldErrors} field="seasonStart" />
+              <FieldErrors errors={fieldErrors} field="seasonEnd" />
+              <input
+                type="date"
+                value={formData.seasonStart}
+                onChange={(e) => setFormData({ ...formData, seasonStart: e.target.value })}
+              />
+              <input
+                type="date"
+                value={formData.seasonEnd}
+                onChange={(e) => setFormData({ ...formData, seasonEnd: e.target.value })}
+              />
+            </div>
+          )}
           <div className="form-group">
             <label>Description:</label>
+            <FieldErrors errors={fieldErrors} field="description" />
             <textarea
               value={formData.description}
               onChange={(e) => setFormData({ ...formData, description: e.target.value })}
@@ -185,8 +293,17 @@ const AdminMenu = () => {
                   <h4>{item.title}</h4>
                   <p>Price: {item.price} руб.</p>
                   <p>Calories: {item.calories}</p>
+                  <p>
+                    {availabilityLabels[item.availability || 'available']}
+                    {item.availability === 'seasonal' && ` (${item.seasonStart || '…'} – ${item.seasonEnd || '…'})`}
+                  </p>
                 </div>
                 <div className="item-actions">
+                  {(item.availability === 'available' || item.availability === 'sold_out') && (
+                    <button onClick={() => toggleSoldOut(item)}>
+                      {item.availability === 'sold_out' ? 'Back on sale' : 'Sold out'}
+                    </button>
+                  )}
                   <button onClick={() => handleEdit(item)}>Edit</button>
                   <button onClick={() => handleDelete(item.id)}>Delete</button>
                 </div>
@@ -194,6 +311,25 @@ const AdminMenu = () => {
             ))}
           </div>
         )}
+
+        {trash.length > 0 && (
+          <>
+            <h3>Trash</h3>
+            <div className="admin-list">
+              {trash.map((item) => (
+                <div key={item.id} className="admin-item">
+                  <div className="item-info">
+                    <h4>{item.title}</h4>
+                    {item.deletedAt && <small>Deleted: {formatDateTime(item.deletedAt)}</small>}
+                  </div>
+                  <div className="item-actions">
+                    <button onClick={() => handleRestore(item.id)}>Restore</button>
+                  </div>
+                </div>
+              ))}
+            </div>
+          </>
+        )}
       </section>
     </main>
   );
@@ -1,25 +1,33 @@
 import { useEffect, useState } from 'react';
-import type { NewsItem } from '../types';
-import { fetchNews, createNewsItem, updateNewsItem, deleteNewsItem } from '../api';
+import type { NewsItem, NewsStatus, Revision, RevisionDiff } from '../types';
+import {
+  ApiError, fetchAdminNews, fetchNewsTrash, createNewsItem, updateNewsItem, deleteNewsItem, restoreNewsItem, uploadImage,
+  fetchNewsRevisions, diffNewsRevisions, restoreNewsRevision,
+} from '../api';
+import type { FieldError } from '../api';
+import { FieldErrors } from '../components/FieldErrors';
+import { useTimezone } from '../contexts/TimezoneContext';
 
 const AdminNews = () => {
   const [news, setNews] = useState<NewsItem[]>([]);
+  const [trash, setTrash] = useState<NewsItem[]>([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
+  const [fieldErrors, setFieldErrors] = useState<FieldError[]>([]);
   const [editingItem, setEditingItem] = useState<NewsItem | null>(null);
-  const getMoscowTimeString = () => {
-    const now = new Date();
-    const moscowOffset = 3 * 60 * 60 * 1000; // 3 hours in ms
-    const moscowTime = new Date(now.getTime() + moscowOffset);
-    return moscowTime.toISOString().slice(0, 16);
-  };
+  const [historyItem, setHistoryItem] = useState<NewsItem | null>(null);
+  const [revisions, setRevisions] = useState<Revision<NewsItem>[]>([]);
+  const [diff, setDiff] = useState<RevisionDiff | null>(null);
+  const { timezone, formatDateTime, toInputValue, fromInputValue } = useTimezone();
+  const nowInputValue = () => toInputValue(new Date());
 
   const [formData, setFormData] = useState({
     title: '',
     preview: '',
     description: '',
     imageURLs: [''],
-    postedAt: getMoscowTimeString(),
+    postedAt: nowInputValue(),
+    status: 'published' as NewsStatus,
   });
 
   useEffect(() => {
@@ -28,8 +36,9 @@ const AdminNews = () => {
 
   const loadNews = async () => {
     try {
-      const data = await fetchNews();
+      const [data, deleted] = await Promise.all([fetchAdminNews(), fetchNewsTrash()]);
       setNews(data);
+      setTrash(deleted);
     } catch (err) {
       setError('Failed to load news');
     } finally {
@@ -39,48 +48,43 @@ const AdminNews = () => {
 
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
-    let postedAt: string;
-    if (formData.postedAt) {
-      // Convert the input (which is in local time, but we treat as Moscow) to UTC
-      const selectedTime = new Date(formData.postedAt + ':00'); // Add seconds
-      postedAt = selectedTime.toISOString();
-    } else {
-      postedAt = new Date().toISOString();
-    }
+    // The form holds cafe wall-clock time; the API takes an instant.
+    const postedAt = formData.postedAt ? fromInputValue(formData.postedAt) : new Date().toISOString();
     const dataToSend = {
       ...formData,
       postedAt,
     };
     try {
       if (editingItem) {
-        await updateNewsItem(editingItem.id, dataToSend);
+        await updateNewsItem(editingItem.id, dataToSend, editingItem.updatedAt);
       } else {
         await createNewsItem(dataToSend);
       }
       loadNews();
       resetForm();
     } catch (err) {
+      if (err instanceof ApiError && err.fields.length > 0) {
+        setFieldErrors(err.fields);
+        return;
+      }
       setError('Failed to save item');
     }
   };
 
   const handleEdit = (item: NewsItem) => {
     setEditingItem(item);
-    // Convert postedAt from UTC to Moscow time for display
-    const postedAtMoscow = new Date(item.postedAt);
-    postedAtMoscow.setHours(postedAtMoscow.getHours() + 3); // Add 3 hours for Moscow
-    const postedAtString = postedAtMoscow.toISOString().slice(0, 16);
     setFormData({
       title: item.title,
       preview: item.preview || '',
       description: item.description || '',
       imageURLs: item.imageURLs || [''],
-      postedAt: postedAtString,
+      postedAt: toInputValue(item.postedAt),
+      status: item.status === 'scheduled' ? 'published' : item.status || 'published',
     });
   };
 
   const handleDelete = async (id: number) => {
-    if (confirm('Are you sure you want to delete this item?')) {
+    if (confirm('Move this post to the trash? It can be restored below.')) {
       try {
         await deleteNewsItem(id);
         loadNews();
@@ -90,14 +94,65 @@ const AdminNews = () => {
     }
   };
 
+  const handleRestore = async (id: number) => {
+    try {
+      await restoreNewsItem(id);
+      loadNews();
+    } catch (err) {
+      setError('Failed to restore item');
+    }
+  };
+
+  const showHistory = async (item: NewsItem) => {
+    try {
+      setRevisions(await fetchNewsRevisions(item.id));
+      setHistoryItem(item);
+      setDiff(null);
+    } catch (err) {
+      setError('Failed to load history');
+    }
+  };
+
+  const closeHistory = () => {
+    setHistoryItem(null);
+    setRevisions([]);
+    setDiff(null);
+  };
+
+  const compareWithCurrent = async (revision: number) => {
+    if (!historyItem) {
+      return;
+    }
+    try {
+      setDiff(await diffNewsRevisions(historyItem.id, revision));
+    } catch (err) {
+      setError('Failed to compare versions');
+    }
+  };
+
+  const handleRestoreRevision = async (revision: number) => {
+    if (!historyItem || !confirm('Bring back this version? The current text is kept in the history.')) {
+      return;
+    }
+    try {
+      await restoreNewsRevision(historyItem.id, revision);
+      closeHistory();
+      loadNews();
+    } catch (err) {
+      setError('Failed to restore version');
+    }
+  };
+
   const resetForm = () => {
     setEditingItem(null);
+    setFieldErrors([]);
     setFormData({
       title: '',
       preview: '',
       description: '',
       imageURLs: [''],
-      postedAt: getMoscowTimeString(),
+      postedAt: nowInputValue(),
+      status: 'published',
     });
   };
 
@@ -111,6 +166,21 @@ const AdminNews = () => {
     setFormData({ ...formData, imageURLs: newURLs });
   };
 
+  const handleUpload = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    const file = e.target.files?.[0];
+    e.target.value = '';
+    if (!file) {
+      return;
+    }
+    try {
+      const url = await uploadImage(file);
+      const urls = formData.imageURLs.filter((u) => u !== '');
+      setFormData({ ...formData, imageURLs: [...urls, url] });
+    } catch (err) {
+      setError('Failed to upload image');
+    }
+  };
+
   const removeImageURL = (index: number) => {
     if (formData.imageURLs.length > 1) {
       const newURLs = formData.imageURLs.filter((_, i) => i !== index);
@@ -128,6 +198,7 @@ const AdminNews = () => {
           <h3>{editingItem ? 'Edit Item' : 'Add New Item'}</h3>
           <div className="form-group">
             <label>Title:</label>
+            <FieldErrors errors={fieldErrors} field="title" />
             <input
               type="text"
               value={formData.title}
@@ -137,6 +208,7 @@ const AdminNews = () => {
           </div>
           <div className="form-group">
             <label>Preview:</label>
+            <FieldErrors errors={fieldErrors} field="preview" />
             <textarea
               value={formData.preview}
               onChange={(e) => setFormData({ ...formData, preview: e.target.value })}
@@ -144,21 +216,36 @@ const AdminNews = () => {
           </div>
           <div className="form-group">
             <label>Description:</label>
+            <FieldErrors errors={fieldErrors} field="description" />
             <textarea
               value={formData.description}
               onChange={(e) => setFormData({ ...formData, description: e.target.value })}
             />
           </div>
           <div className="form-group">
-            <label>Posted At (Moscow time):</label>
+            <label>Posted At ({timezone}):</label>
+            <FieldErrors errors={fieldErrors} field="postedAt" />
             <input
               type="datetime-local"
               value={formData.postedAt}
               onChange={(e) => setFormData({ ...formData, postedAt: e.target.value })}
             />
           </div>
+          <div className="form-group">
+            <label>Status:</label>
+            <FieldErrors errors={fieldErrors} field="status" />
+            <select
+              value={formData.status}
+              onChange={(e) => setFormData({ ...formData, status: e.target.value as NewsStatus })}
+            >
+              <option value="draft">Draft</option>
+              <option value="published">Publish at the time above</option>
+              <option value="archived">Archived</option>
+            </select>
+          </div>
           <div className="form-group">
             <label>Image URLs:</label>
+            <FieldErrors errors={fieldErrors} field="imageURLs" />
             {formData.imageURLs.map((url, index) => (
               <div key={index} className="image-url-group">
                 <input
@@ -178,6 +265,7 @@ const AdminNews = () => {
               </div>
             ))}
             <button type="button" onClick={addImageURL}>Add Image URL</button>
+            <input type="file" accept="image/jpeg,image/png,image/webp,image/gif" onChange={handleUpload} />
           </div>
           <div className="form-actions">
             <button type="submit">{editingItem ? 'Update' : 'Create'}</button>
@@ -193,16 +281,71 @@ const AdminNews = () => {
                 <div className="item-info">
                   <h4>{item.title}</h4>
                   <p>{item.description}</p>
-                  <small>Posted At: {new Date(item.postedAt).toLocaleString('ru-RU', { timeZone: 'Europe/Moscow' })}</small>
+                  <small>Posted At: {formatDateTime(item.postedAt)}</small>
+                  {item.status && <small> · {item.status}</small>}
                 </div>
                 <div className="item-actions">
                   <button onClick={() => handleEdit(item)}>Edit</button>
+                  <button onClick={() => showHistory(item)}>History</button>
                   <button onClick={() => handleDelete(item.id)}>Delete</button>
                 </div>
               </div>
             ))}
           </div>
         )}
+
+        {historyItem && (
+          <>
+            <h3>History: {historyItem.title}</h3>
+            {revisions.length === 0 && <p>This post has not been edited yet.</p>}
+            <div className="admin-list">
+              {revisions.map((revision) => (
+                <div key={revision.id} className="admin-item">
+                  <div className="item-info">
+                    <h4>{revision.item.title}</h4>
+                    <p>{revision.item.preview}</p>
+                    <small>Replaced: {formatDateTime(revision.createdAt)}</small>
+                  </div>
+                  <div className="item-actions">
+                    <button onClick={() => compareWithCurrent(revision.id)}>Compare with current</button>
+                    <button onClick={() => handleRestoreRevision(revision.id)}>Restore</button>
+                  </div>
+                </div>
+              ))}
+            </div>
+            {diff && (
+              <dl className="revision-diff">
+                {Object.entries(diff.changes).map(([field, change]) => (
+                  <div key={field}>
+                    <dt>{field}</dt>
+                    <dd className="revision-before">{JSON.stringify(change.before ?? null)}</dd>
+                    <dd className="revision-after">{JSON.stringify(change.after ?? null)}</dd>
+                  </div>
+                ))}
+              </dl>
+            )}
+            <button type="button" onClick={closeHistory}>Close history</button>
+          </>
+        )}
+
+        {trash.length > 0 && (
+          <>
+            <h3>Trash</h3>
+            <div className="admin-list">
+              {trash.map((item) => (
+                <div key={item.id} className="admin-item">
+                  <div className="item-info">
+                    <h4>{item.title}</h4>
+                    {item.deletedAt && <small>Deleted: {formatDateTime(item.deletedAt)}</small>}
+                  </div>
+                  <div className="item-actions">
+                    <button onClick={() => handleRestore(item.id)}>Restore</button>
+                  </div>
+                </div>
+              ))}
+            </div>
+          </>
+        )}
       </section>
     </main>
   );
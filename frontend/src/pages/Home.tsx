@@ -3,8 +3,10 @@ import { Link } from 'react-router-dom';
 import type { NewsItem, MenuItem } from '../types';
 import { fetchNews, fetchMenu } from '../api';
 import { MenuItemCard } from '../components/MenuItemCard';
+import { useTimezone } from '../contexts/TimezoneContext';
 
 const Home = () => {
+  const { formatDate } = useTimezone();
   const [news, setNews] = useState<NewsItem[]>([]);
   const [menu, setMenu] = useState<MenuItem[]>([]);
   const [loading, setLoading] = useState(true);
@@ -13,12 +15,10 @@ const Home = () => {
   useEffect(() => {
     const loadData = async () => {
       try {
-        const [newsData, menuData] = await Promise.all([fetchNews(), fetchMenu()]);
-        const now = new Date();
-        const visibleNews = newsData.filter(item => new Date(item.postedAt) <= now);
-        setNews(visibleNews.slice(0, 3));
+        const [newsData, menuData] = await Promise.all([fetchNews(), fetchMenu({ limit: 6 })]);
+        setNews(newsData.slice(0, 3));
         // Assume all menu items are popular for now, or filter by price > some value
-        setMenu(menuData.slice(0, 6)); // Take first 6 as popular
+        setMenu(menuData.items);
       } catch (err) {
         setError('Failed to load data');
       } finally {
@@ -80,7 +80,7 @@ const Home = () => {
                 </div>
                 <div className="news-item-content">
                   <div className="news-item-meta">
-                    <span className="news-item-date">{new Date(item.postedAt).toLocaleDateString()}</span>
+                    <span className="news-item-date">{formatDate(item.postedAt)}</span>
                     <span className="news-item-category">Событие</span>
                   </div>
                   <h3 className="news-item-title">{item.title}</h3>
@@ -110,6 +110,7 @@ const Home = () => {
               price={item.price.toString()}
               calories={item.calories}
               image={item.imageURLs?.[0]}
+              imageSet={item.images?.[0]}
               popular={true} // Assuming these are popular
             />
           ))}
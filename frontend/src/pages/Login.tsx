@@ -3,6 +3,7 @@ import { useAuth } from '../contexts/AuthContext';
 import { useNavigate } from 'react-router-dom';
 
 const Login = () => {
+  const [username, setUsername] = useState('');
   const [password, setPassword] = useState('');
   const [error, setError] = useState('');
   const { login } = useAuth();
@@ -12,11 +13,11 @@ const Login = () => {
     e.preventDefault();
     setError('');
 
-    const success = await login(password);
+    const success = await login(username, password);
     if (success) {
       navigate('/admin/menu');
     } else {
-      setError('Неверный пароль');
+      setError('Неверное имя пользователя или пароль');
     }
   };
 
@@ -25,6 +26,16 @@ const Login = () => {
       <section className="login">
         <h2>Вход в админ-панель</h2>
         <form onSubmit={handleSubmit} className="login-form">
+          <div className="form-group">
+            <label>Имя пользователя:</label>
+            <input
+              type="text"
+              value={username}
+              onChange={(e) => setUsername(e.target.value)}
+              autoComplete="username"
+              required
+            />
+          </div>
           <div className="form-group">
             <label>Пароль:</label>
             <input
@@ -1,18 +1,20 @@
 import { useEffect, useState } from 'react';
-import type { MenuItem } from '../types';
-import { fetchMenu } from '../api';
+import type { Category, MenuItem } from '../types';
+import { fetchAllMenu } from '../api';
 import { MenuItemCard } from '../components/MenuItemCard';
 
 const Menu = () => {
   const [menu, setMenu] = useState<MenuItem[]>([]);
+  const [categoryList, setCategoryList] = useState<Category[]>([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
 
   useEffect(() => {
     const loadMenu = async () => {
       try {
-        const menuData = await fetchMenu();
-        setMenu(menuData);
+        const menuData = await fetchAllMenu();
+        setMenu(menuData.items);
+        setCategoryList(menuData.categories);
       } catch (err) {
         setError('Failed to load menu');
       } finally {
@@ -22,19 +24,10 @@ const Menu = () => {
     loadMenu();
   }, []);
 
-  // Category display names
-  const categoryNames: Record<string, string> = {
-    main_meal: 'Основное меню',
-    snacks: 'Закуски',
-    breakfast: 'Завтрак',
-    desserts: 'Десерты',
-    drinks: 'Напитки',
-  };
+  // Category names and order come from the server; items arrive already
+  // sorted by category position.
+  const categoryNames = Object.fromEntries(categoryList.map((c) => [c.slug, c.name]));
 
-  // Category order
-  const categoryOrder = ['main_meal', 'snacks', 'breakfast', 'desserts', 'drinks'];
-
-  // Group menu items by category
   const groupedMenu = menu.reduce((acc, item) => {
     const category = item.category || 'Без категории';
     if (!acc[category]) {
@@ -44,15 +37,7 @@ const Menu = () => {
     return acc;
   }, {} as Record<string, MenuItem[]>);
 
-  // Sort categories by predefined order
-  const categories = Object.keys(groupedMenu).sort((a, b) => {
-    const indexA = categoryOrder.indexOf(a);
-    const indexB = categoryOrder.indexOf(b);
-    if (indexA === -1 && indexB === -1) return 0;
-    if (indexA === -1) return 1;
-    if (indexB === -1) return -1;
-    return indexA - indexB;
-  });
+  const categories = Object.keys(groupedMenu);
 
   return (
     <div className="max-w-7xl mx-auto px-6 py-16">
@@ -82,7 +67,9 @@ const Menu = () => {
                 price={item.price.toString()}
                 calories={item.calories}
                 image={item.imageURLs?.[0]}
+                imageSet={item.images?.[0]}
                 popular={false} // You can add logic to determine if item is popular
+                soldOut={item.availability === 'sold_out'}
               />
             ))}
           </div>
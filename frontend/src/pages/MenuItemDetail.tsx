@@ -2,8 +2,10 @@ import { useEffect, useState } from 'react';
 import { useParams } from 'react-router-dom';
 import type { MenuItem } from '../types';
 import { fetchMenuItem } from '../api';
+import { useTimezone } from '../contexts/TimezoneContext';
 
 const MenuItemDetail = () => {
+  const { formatDate } = useTimezone();
   const { id } = useParams<{ id: string }>();
   const [item, setItem] = useState<MenuItem | null>(null);
   const [loading, setLoading] = useState(true);
@@ -40,6 +42,7 @@ const MenuItemDetail = () => {
           </div>
         )}
         <p><strong>Цена:</strong> {item.price} руб.</p>
+        {item.availability === 'sold_out' && <p className="menu-item-sold-out">Сегодня закончилось</p>}
         {item.calories && <p><strong>Калории:</strong> {item.calories}</p>}
         {item.description && <p><strong>Описание:</strong> {item.description}</p>}
         <p><strong>Категория:</strong> {
@@ -49,8 +52,8 @@ const MenuItemDetail = () => {
           item.category === 'drinks' ? 'Напитки' :
           item.category
         }</p>
-        <p><strong>Создано:</strong> {new Date(item.createdAt).toLocaleDateString()}</p>
-        <p><strong>Обновлено:</strong> {new Date(item.updatedAt).toLocaleDateString()}</p>
+        <p><strong>Создано:</strong> {formatDate(item.createdAt)}</p>
+        <p><strong>Обновлено:</strong> {formatDate(item.updatedAt)}</p>
       </section>
     </main>
   );
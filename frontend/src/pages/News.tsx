@@ -2,8 +2,10 @@ import { useEffect, useState } from 'react';
 import { Link } from 'react-router-dom';
 import type { NewsItem } from '../types';
 import { fetchNews } from '../api';
+import { useTimezone } from '../contexts/TimezoneContext';
 
 const News = () => {
+  const { formatDate } = useTimezone();
   const [news, setNews] = useState<NewsItem[]>([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
@@ -12,9 +14,8 @@ const News = () => {
     const loadNews = async () => {
       try {
         const newsData = await fetchNews();
-        const now = new Date();
-        const visibleNews = newsData.filter(item => new Date(item.postedAt) <= now);
-        setNews(visibleNews);
+        // The API only returns posts that are already live.
+        setNews(newsData);
       } catch (err) {
         setError('Failed to load news');
       } finally {
@@ -46,7 +47,7 @@ const News = () => {
               )}
               <div className="news-item-content">
                 <div className="news-item-meta">
-                  <span className="news-item-date">{new Date(item.postedAt).toLocaleDateString()}</span>
+                  <span className="news-item-date">{formatDate(item.postedAt)}</span>
                   <span className="news-item-category">Событие</span>
                 </div>
                 <h3 className="news-item-title">{item.title}</h3>
@@ -2,8 +2,10 @@ import { useEffect, useState } from 'react';
 import { useParams } from 'react-router-dom';
 import type { NewsItem } from '../types';
 import { fetchNewsItem } from '../api';
+import { useTimezone } from '../contexts/TimezoneContext';
 
 const NewsItemDetail = () => {
+  const { formatDate } = useTimezone();
   const { id } = useParams<{ id: string }>();
   const [item, setItem] = useState<NewsItem | null>(null);
   const [loading, setLoading] = useState(true);
@@ -41,9 +43,9 @@ const NewsItemDetail = () => {
         )}
         {item.preview && <p className="preview">{item.preview}</p>}
         <p>{item.description}</p>
-        <p><strong>Опубликовано:</strong> {new Date(item.postedAt).toLocaleDateString()}</p>
-        <p><strong>Создано:</strong> {new Date(item.createdAt).toLocaleDateString()}</p>
-        <p><strong>Обновлено:</strong> {new Date(item.updatedAt).toLocaleDateString()}</p>
+        <p><strong>Опубликовано:</strong> {formatDate(item.postedAt)}</p>
+        <p><strong>Создано:</strong> {formatDate(item.createdAt)}</p>
+        <p><strong>Обновлено:</strong> {formatDate(item.updatedAt)}</p>
       </section>
     </main>
   );
@@ -1,22 +1,105 @@
+export interface ImageVariant {
+  name: 'thumb' | 'card' | 'full';
+  width: number;
+  webp: string;
+  jpeg: string;
+}
+
+export interface ImageSet {
+  src: string;
+  variants?: ImageVariant[];
+  srcset?: { webp: string; jpeg: string };
+}
+
 export interface MenuItem {
   id: number;
   title: string;
   price: number;
   imageURLs: string[];
+  images?: ImageSet[];
   calories?: number;
   description?: string;
   category: string;
+  availability?: Availability;
+  soldOutAt?: string;
+  seasonStart?: string;
+  seasonEnd?: string;
+  // deletedAt is only set on items listed from the trash.
+  deletedAt?: string;
   createdAt: string;
   updatedAt: string;
 }
 
+// Availability is how an item appears on the public menu: sold_out items
+// are listed but marked, hidden items are left out, and seasonal items are
+// listed between seasonStart and seasonEnd.
+export type Availability = 'available' | 'sold_out' | 'hidden' | 'seasonal';
+
+export interface AvailabilityChange {
+  availability: Availability;
+  seasonStart?: string;
+  seasonEnd?: string;
+}
+
+export type MenuSort = 'price' | '-price' | 'title' | '-title' | 'created' | '-created';
+
+export interface MenuQuery {
+  category?: string;
+  minPrice?: number;
+  maxPrice?: number;
+  maxCalories?: number;
+  q?: string;
+  sort?: MenuSort;
+  availability?: Availability;
+  limit?: number;
+  cursor?: string;
+}
+
+export interface Category {
+  slug: string;
+  name: string;
+  position: number;
+  imageURL?: string;
+  visible: boolean;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface MenuPage {
+  items: MenuItem[];
+  nextCursor?: string;
+  categories: Category[];
+}
+
 export interface NewsItem {
   id: number;
   title: string;
   preview?: string;
   description?: string;
   imageURLs?: string[];
+  images?: ImageSet[];
   createdAt: string;
   updatedAt: string;
   postedAt: string;
+  status?: NewsStatus;
+  publishedAt?: string;
+  deletedAt?: string;
+}
+
+export type NewsStatus = 'draft' | 'scheduled' | 'published' | 'archived';
+
+// Revision is a version of an item that an update replaced; createdAt is
+// when it was replaced.
+export interface Revision<T> {
+  id: number;
+  createdAt: string;
+  item: T;
+}
+
+// RevisionDiff lists the fields that differ between two versions, keyed
+// by field name. to is left out when comparing with the current version.
+export interface RevisionDiff {
+  from: number;
+  to?: number;
+  changes: Record<string, { before?: unknown; after?: unknown }>;
 }